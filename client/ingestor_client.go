@@ -43,6 +43,35 @@ func (c *IngestorClient) SendEvent(event models.Event) error {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
+	return c.post(payload)
+}
+
+// EnrichedEvent is an event with extra attributes, such as device
+// attributes from an inventory. It marshals to the event's JSON object with
+// the extra keys added.
+type EnrichedEvent interface {
+	json.Marshaler
+	BaseEvent() models.Event // The wrapped event, validated before sending
+}
+
+// SendEnrichedEvent sends an event together with its extra attributes to
+// Ingestor Core, using the same retry logic as SendEvent.
+func (c *IngestorClient) SendEnrichedEvent(event EnrichedEvent) error {
+	if err := event.BaseEvent().Validate(); err != nil {
+		return fmt.Errorf("event validation failed: %w", err)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return c.post(payload)
+}
+
+// post delivers a JSON payload to the ingest endpoint, retrying on transport
+// and server errors.
+func (c *IngestorClient) post(payload []byte) error {
 	url := fmt.Sprintf("%s/ingest/event", c.baseURL)
 
 	var lastErr error
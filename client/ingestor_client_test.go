@@ -1,8 +1,11 @@
 package client
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -74,3 +77,49 @@ func TestHealthCheck(t *testing.T) {
 		t.Fatalf("expected health check to succeed, got %v", err)
 	}
 }
+
+// vendorEvent is an EnrichedEvent adding a "vendor" key.
+type vendorEvent struct {
+	event  models.Event
+	vendor string
+}
+
+func (e vendorEvent) BaseEvent() models.Event { return e.event }
+
+func (e vendorEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"event_type": e.event.EventType, "vendor": e.vendor})
+}
+
+func TestSendEnrichedEvent_IncludesAttributes(t *testing.T) {
+	var body string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewIngestorClient(server.URL)
+
+	event := vendorEvent{
+		event: models.Event{
+			EventType:      "syslog",
+			SourceHost:     "router-1",
+			SourceIP:       "192.168.1.1",
+			Severity:       "critical",
+			Category:       "network",
+			Message:        "Interface down",
+			EventTimestamp: time.Now(),
+		},
+		vendor: "Cisco",
+	}
+
+	if err := client.SendEnrichedEvent(event); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !strings.Contains(body, `"vendor":"Cisco"`) {
+		t.Fatalf("expected extra attributes in payload, got %s", body)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+	"github.com/ibm-live-project-interns/datasource/simulator"
+)
+
+func main() {
+	configPath := flag.String("config", "config/sample.yml", "Path to simulator YAML configuration")
+	apiAddr := flag.String("api", "localhost:8090", "HTTP control API listen address (empty to disable)")
+	scenarioPath := flag.String("scenario", "", "Run a scripted scenario YAML instead of the configured devices")
+	scenarioOut := flag.String("scenario-out", "-", "Where to write the scenario's JSON Lines emission log (- for stdout)")
+	noise := flag.Bool("noise", false, "With -scenario, also run the configured devices as background traffic")
+	seed := flag.Int64("seed", 0, "Random seed for the devices; overrides the configuration's, 0 keeps it")
+	var timing simclock.Options
+	timing.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	if err := timing.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	// Every device gets its own clock, all starting at the same simulated time.
+	now := time.Now()
+	newClock := func() simclock.Clock {
+		clock, _ := timing.NewClock(now)
+		return clock
+	}
+
+	if *scenarioPath != "" {
+		if err := runScenario(*scenarioPath, *scenarioOut, *noise, *configPath, *seed, newClock); err != nil {
+			log.Fatalf("scenario failed: %v", err)
+		}
+		return
+	}
+
+	mgr, err := simulator.LoadManager(*configPath)
+	if err != nil {
+		log.Fatalf("simulator setup failed: %v", err)
+	}
+
+	mgr.Clock = newClock
+	mgr.Seed = simclock.Seed(cmp.Or(*seed, mgr.Seed))
+	fmt.Printf("Starting device simulator: devices=%d, config=%s, seed=%d, start=%s\n",
+		mgr.Len(), *configPath, mgr.Seed, newClock().Now().Format(time.RFC3339))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *apiAddr != "" {
+		srv := &http.Server{Addr: *apiAddr, Handler: mgr.Handler()}
+		go func() {
+			fmt.Printf("Control API listening on http://%s/devices\n", *apiAddr)
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("control API stopped: %v", err)
+			}
+		}()
+		defer srv.Close()
+	}
+
+	err = mgr.Start(ctx)
+
+	fmt.Println("Device status:")
+	for _, st := range mgr.Status() {
+		fmt.Printf("  %-20s %-12s %-10s restarts=%d %s\n", st.Name, st.Type, st.State, st.Restarts, st.LastError)
+	}
+
+	if err != nil {
+		log.Fatalf("simulator stopped with failures: %v", err)
+	}
+}
+
+// runScenario executes a scenario file, optionally with the configured
+// devices running alongside (seeded with seed, if not 0), with clocks from
+// newClock. It returns an error if the scenario could not be loaded or did
+// not run to completion.
+func runScenario(path, outPath string, noise bool, configPath string, seed int64, newClock func() simclock.Clock) error {
+	scenario, err := simulator.LoadScenario(path)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if outPath != "-" {
+		if out, err = os.Create(outPath); err != nil {
+			return fmt.Errorf("failed to create scenario output: %w", err)
+		}
+		defer out.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if noise {
+		mgr, err := simulator.LoadManager(configPath)
+		if err != nil {
+			return err
+		}
+		mgr.Clock = newClock
+		mgr.Seed = simclock.Seed(cmp.Or(seed, mgr.Seed))
+		log.Printf("Background devices: seed=%d", mgr.Seed)
+
+		noiseCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if err := mgr.Start(noiseCtx); err != nil {
+				log.Printf("background devices: %v", err)
+			}
+		}()
+		defer func() {
+			cancel()
+			<-done
+		}()
+	}
+
+	log.Printf("Running scenario %q from %s", scenario.Name, path)
+	summary, err := scenario.Run(simclock.WithClock(ctx, newClock()), out)
+	log.Printf("Scenario finished: steps=%d emitted=%d failed=%d seed=%d",
+		summary.Steps, summary.Emitted, summary.Failed, summary.Seed)
+	return err
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/ibm-live-project-interns/datasource/pkg/metadatasim"
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
 )
 
 func main() {
@@ -14,20 +19,35 @@ func main() {
 	deviceCount := flag.Int("devices", 10, "Number of devices to generate")
 	updates := flag.Int("updates", 0, "Number of metadata update cycles (0 = none)")
 	updateInterval := flag.Duration("update-interval", 30*time.Second, "Time between metadata updates")
+	seed := flag.Int64("seed", 0, "Random seed for reproducible metadata (0 = random)")
+	var timing simclock.Options
+	timing.RegisterFlags(flag.CommandLine)
 
 	flag.Parse()
 
+	clock, err := timing.NewClock(time.Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	cfg := metadatasim.Config{
 		OutputPath:     *output,
 		DeviceCount:    *deviceCount,
 		Updates:        *updates,
 		UpdateInterval: *updateInterval,
+		Seed:           simclock.Seed(*seed),
+		Clock:          clock,
 	}
 
-	fmt.Printf("Starting metadata publisher: devices=%d, output=%s, updates=%d, interval=%s\n",
-		cfg.DeviceCount, cfg.OutputPath, cfg.Updates, cfg.UpdateInterval)
+	fmt.Printf("Starting metadata publisher: devices=%d, output=%s, updates=%d, interval=%s, seed=%d\n",
+		cfg.DeviceCount, cfg.OutputPath, cfg.Updates, cfg.UpdateInterval, cfg.Seed)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if err := metadatasim.Run(cfg); err != nil {
+	summary, err := metadatasim.Run(ctx, cfg)
+	fmt.Println("Metadata publisher finished:", summary)
+	if err != nil {
 		log.Fatalf("metadata publisher failed: %v", err)
 	}
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/simulator"
+)
+
+const usage = `Usage: simctl [-api URL] <command> [args]
+
+Commands:
+  list                                  list devices and their state
+  add <name> <type> [flags]             add devices (name may use {1..N})
+        -trap N -syslog N -count N -trap-target ADDR -syslog-target ADDR
+        -profile steady|poisson|diurnal|storm
+        -dialect rfc5424|rfc3164|cisco|junos|cef|leef
+  remove <name>                         stop and remove a device
+  pause <name>                          pause a device's telemetry
+  resume <name>                         resume a paused device
+  interval <name> [-trap N] [-syslog N] change intervals (seconds)
+`
+
+func main() {
+	api := flag.String("api", "http://localhost:8090", "device-sim control API base URL")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	c := &ctl{base: *api, http: &http.Client{Timeout: 10 * time.Second}}
+	if err := c.run(args[0], args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "simctl:", err)
+		os.Exit(1)
+	}
+}
+
+type ctl struct {
+	base string
+	http *http.Client
+}
+
+func (c *ctl) run(cmd string, args []string) error {
+	switch cmd {
+	case "list":
+		var devices []simulator.DeviceStatus
+		if err := c.call(http.MethodGet, "/devices", nil, &devices); err != nil {
+			return err
+		}
+		printStatus(devices...)
+		return nil
+
+	case "add":
+		fs := flag.NewFlagSet("add", flag.ExitOnError)
+		trap := fs.Int("trap", 0, "trap interval in seconds")
+		syslog := fs.Int("syslog", 0, "syslog interval in seconds")
+		count := fs.Int("count", 0, "number of devices to create")
+		trapTarget := fs.String("trap-target", "", "UDP host:port for traps")
+		syslogTarget := fs.String("syslog-target", "", "UDP host:port for syslog")
+		profile := fs.String("profile", "", "traffic profile preset")
+		dialect := fs.String("dialect", "", "syslog format")
+		if len(args) < 2 {
+			return fmt.Errorf("add requires <name> <type>")
+		}
+		fs.Parse(args[2:])
+
+		req := simulator.DeviceRequest{
+			Name: args[0], Type: args[1], Count: *count,
+			TrapInterval: *trap, SyslogInterval: *syslog,
+			TrapTarget: *trapTarget, SyslogTarget: *syslogTarget,
+			Profile: *profile, Dialect: *dialect,
+		}
+		var added []simulator.DeviceStatus
+		if err := c.call(http.MethodPost, "/devices", req, &added); err != nil {
+			return err
+		}
+		printStatus(added...)
+		return nil
+
+	case "remove":
+		name, err := oneName(cmd, args)
+		if err != nil {
+			return err
+		}
+		return c.call(http.MethodDelete, "/devices/"+url.PathEscape(name), nil, nil)
+
+	case "pause", "resume":
+		name, err := oneName(cmd, args)
+		if err != nil {
+			return err
+		}
+		var st simulator.DeviceStatus
+		if err := c.call(http.MethodPost, "/devices/"+url.PathEscape(name)+"/"+cmd, nil, &st); err != nil {
+			return err
+		}
+		printStatus(st)
+		return nil
+
+	case "interval":
+		if len(args) < 1 {
+			return fmt.Errorf("interval requires <name>")
+		}
+		fs := flag.NewFlagSet("interval", flag.ExitOnError)
+		trap := fs.String("trap", "", "trap interval in seconds")
+		syslog := fs.String("syslog", "", "syslog interval in seconds")
+		fs.Parse(args[1:])
+
+		var req simulator.IntervalRequest
+		var err error
+		if req.TrapInterval, err = optionalInt("trap", *trap); err != nil {
+			return err
+		}
+		if req.SyslogInterval, err = optionalInt("syslog", *syslog); err != nil {
+			return err
+		}
+		if req.TrapInterval == nil && req.SyslogInterval == nil {
+			return fmt.Errorf("interval requires -trap and/or -syslog")
+		}
+
+		var st simulator.DeviceStatus
+		if err := c.call(http.MethodPatch, "/devices/"+url.PathEscape(args[0]), req, &st); err != nil {
+			return err
+		}
+		printStatus(st)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command %q (see simctl -h)", cmd)
+	}
+}
+
+// call sends a JSON request and decodes the JSON response into out, turning
+// API error responses into Go errors.
+func (c *ctl) call(method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s (HTTP %d)", apiErr.Error, resp.StatusCode)
+		}
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func oneName(cmd string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("%s requires exactly one device name", cmd)
+	}
+	return args[0], nil
+}
+
+func optionalInt(name, value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s value %q", name, value)
+	}
+	return &n, nil
+}
+
+func printStatus(devices ...simulator.DeviceStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tSTATE\tTRAP\tSYSLOG\tRESTARTS\tLAST ERROR")
+	for _, d := range devices {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%s\n",
+			d.Name, d.Type, d.State, d.TrapInterval, d.SyslogInterval, d.Restarts, d.LastError)
+	}
+	w.Flush()
+}
@@ -1,24 +1,77 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"net"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/client"
+	"github.com/ibm-live-project-interns/datasource/mapper"
 )
 
 func main() {
-	addr, _ := net.ResolveUDPAddr("udp", ":5162")
+	listen := flag.String("addr", ":5162", "UDP listen address")
+	trustPeer := flag.Bool("trust-peer", false, "use the packet's source address as the event source IP")
+	ingestorURL := flag.String("ingestor", "", "Ingestor Core URL to forward mapped traps to (empty to only print them)")
+	flag.Parse()
+
+	addr, _ := net.ResolveUDPAddr("udp", *listen)
 	conn, err := net.ListenUDP("udp", addr)
 	if err != nil {
 		panic(err)
 	}
 	defer conn.Close()
 
-	fmt.Println("Listening for SNMP traps on UDP :5162")
+	// Reverse DNS fills in missing hosts and checks claimed ones against the peer
+	resolver := mapper.NewChainResolver(mapper.ResolverConfig{
+		PositiveTTL: 5 * time.Minute,
+		NegativeTTL: 30 * time.Second,
+		MaxEntries:  10000,
+	})
+	opts := []mapper.Option{mapper.WithReverseDNS(resolver)}
+	if *trustPeer {
+		opts = append(opts, mapper.WithPeerTrust())
+	}
+	m := mapper.NewMapper(resolver, opts...)
+
+	var ingestor *client.IngestorClient
+	if *ingestorURL != "" {
+		ingestor = client.NewIngestorClient(*ingestorURL)
+	}
+
+	fmt.Printf("Listening for SNMP traps on UDP %s\n", *listen)
 
 	buf := make([]byte, 4096)
 
 	for {
-		n, remote, _ := conn.ReadFromUDP(buf)
-		fmt.Printf("From %s:\n%s\n\n", remote, string(buf[:n]))
+		n, remote, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			fmt.Println("read error:", err)
+			continue
+		}
+		fmt.Printf("From %s:\n%s\n", remote, string(buf[:n]))
+
+		event, check, err := m.MapSNMPFrom(buf[:n], remote)
+		if err != nil {
+			fmt.Printf("not mappable: %v\n\n", err)
+			continue
+		}
+
+		// The source check travels with the event as "source_check"
+		checked := mapper.CheckedEvent{Event: event, Source: check}
+		out, _ := json.Marshal(checked)
+		fmt.Printf("Mapped: %s\n", out)
+		if check.Mismatch {
+			fmt.Printf("⚠️  Source mismatch: payload claims %s (%s) but packet came from %s (%s)\n",
+				check.ClaimedHost, check.ClaimedIP, check.PeerIP, check.ReverseHost)
+		}
+		if ingestor != nil {
+			if err := ingestor.SendEnrichedEvent(checked); err != nil {
+				fmt.Printf("forward failed: %v\n", err)
+			}
+		}
+		fmt.Println()
 	}
 }
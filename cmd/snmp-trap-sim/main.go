@@ -1,42 +1,124 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"log"
 	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/ibm-live-project-interns/datasource/pkg/chaos"
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
 	"github.com/ibm-live-project-interns/datasource/pkg/snmptrap"
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
 )
 
 func main() {
 	addr := flag.String("addr", "localhost:5162", "UDP address")
 	device := flag.String("device", "router", "router|switch|firewall")
 	freq := flag.Int("freq", 3, "seconds between traps")
-	file := flag.String("file", "data/snmp-traps.json", "file to store traps")
+	file := flag.String("file", "", "file to append traps to, e.g. snmp-traps.json (empty to disable)")
+	seed := flag.Int64("seed", 0, "random seed for reproducible traps (0 = random)")
+	profileName := flag.String("profile", "", "traffic profile: preset (steady|poisson|diurnal|storm) or YAML file; -freq sets the baseline")
+	var timing simclock.Options
+	timing.RegisterFlags(flag.CommandLine)
+	var chaosCfg chaos.Config
+	chaosCfg.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
-	rand.Seed(time.Now().UnixNano())
+	clock, err := timing.NewClock(time.Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+	gen := snmptrap.NewGenerator(*seed, clock)
+
+	var profile *traffic.Profile
+	if *profileName != "" {
+		if profile, err = traffic.Load(*profileName); err != nil {
+			log.Fatal(err)
+		}
+		if err := gen.SetSeverities(profile.Severities); err != nil {
+			log.Fatal(err)
+		}
+	}
+	schedule := traffic.NewSchedule(profile, time.Duration(*freq)*time.Second, clock.Now(), rand.New(rand.NewSource(gen.Seed()+1)))
+	faults, err := snmptrap.NewChaos(chaosCfg, rand.New(rand.NewSource(gen.Seed()+2)))
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	fmt.Printf("Starting SNMP trap simulator (%s) → %s\n", *device, *addr)
+	sender, err := snmptrap.NewSender(*addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sender.Close()
+
+	var recorder *snmptrap.Recorder
+	if *file != "" {
+		if recorder, err = snmptrap.NewRecorder(*file); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// Traps are buffered and appended to the file at most once per
+	// flushEvery traps or flushInterval, and once more on exit.
+	lastFlush := time.Now()
+	flush := func(force bool) {
+		if recorder == nil || recorder.Pending() == 0 {
+			return
+		}
+		if !force && recorder.Pending() < flushEvery && time.Since(lastFlush) < flushInterval {
+			return
+		}
+		lastFlush = time.Now()
+		if _, err := recorder.Flush(); err != nil {
+			fmt.Println("failed to save traps:", err)
+		}
+	}
+	defer flush(true)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Starting SNMP trap simulator (%s) → %s, seed=%d, start=%s\n",
+		*device, *addr, gen.Seed(), clock.Now().Format(time.RFC3339))
 
 	for {
-		trap := snmptrap.RandomTrap(*device, "device-01")
+		data, rec, err := faults.Apply(gen.RandomTrap(*device, "device-01"))
+		if err != nil {
+			log.Fatal(err)
+		}
 
 		// Send over UDP
-		err := snmptrap.SendTrap(*addr, trap)
-		if err != nil {
+		if err := sender.SendRaw(data); err != nil {
 			fmt.Println("failed to send trap:", err)
+		} else if rec.Fault != "" {
+			fmt.Println("sent trap:", rec.OID, "- fault:", rec.Fault)
 		} else {
-			fmt.Println("sent trap:", trap.OID, "-", trap.Message)
+			fmt.Println("sent trap:", rec.OID, "-", rec.Message)
 		}
 
 		// Save to JSON file
-		err = snmptrap.SaveTrapToFile(*file, trap)
-		if err != nil {
-			fmt.Println("failed to save trap:", err)
+		if recorder != nil {
+			recorder.Add(rec)
+			flush(false)
 		}
 
-		time.Sleep(time.Duration(*freq) * time.Second)
+		if err := clock.Sleep(ctx, schedule.Next(clock.Now())); err != nil {
+			if ctx.Err() == nil {
+				fmt.Println("simulated period finished at", clock.Now().Format(time.RFC3339))
+			}
+			return
+		}
 	}
 }
+
+// flushEvery and flushInterval bound how many traps, and for how much wall
+// time, are buffered before they are appended to the trap file.
+const (
+	flushEvery    = 100
+	flushInterval = time.Second
+)
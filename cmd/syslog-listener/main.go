@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/client"
+	"github.com/ibm-live-project-interns/datasource/mapper"
+	sysloglistener "github.com/ibm-live-project-interns/datasource/sysylog-listener"
+)
+
+func main() {
+	listen := flag.String("addr", ":5140", "UDP listen address")
+	trustPeer := flag.Bool("trust-peer", false, "use the packet's source address as the event source IP")
+	ingestorURL := flag.String("ingestor", "", "Ingestor Core URL to forward mapped messages to (empty to only print them)")
+	flag.Parse()
+
+	// Reverse DNS fills in missing hosts and checks claimed ones against the peer
+	resolver := mapper.NewChainResolver(mapper.ResolverConfig{
+		PositiveTTL: 5 * time.Minute,
+		NegativeTTL: 30 * time.Second,
+		MaxEntries:  10000,
+	})
+	opts := []mapper.Option{mapper.WithReverseDNS(resolver)}
+	if *trustPeer {
+		opts = append(opts, mapper.WithPeerTrust())
+	}
+	m := mapper.NewMapper(resolver, opts...)
+
+	var ingestor *client.IngestorClient
+	if *ingestorURL != "" {
+		ingestor = client.NewIngestorClient(*ingestorURL)
+	}
+
+	err := sysloglistener.StartMappedListener(*listen, m, func(checked mapper.CheckedEvent) {
+		// The source check travels with the event as "source_check"
+		out, _ := json.Marshal(checked)
+		fmt.Printf("Mapped: %s\n", out)
+		if check := checked.Source; check.Mismatch {
+			fmt.Printf("⚠️  Source mismatch: payload claims %s (%s) but packet came from %s (%s)\n",
+				check.ClaimedHost, check.ClaimedIP, check.PeerIP, check.ReverseHost)
+		}
+		if ingestor != nil {
+			if err := ingestor.SendEnrichedEvent(checked); err != nil {
+				fmt.Printf("forward failed: %v\n", err)
+			}
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+}
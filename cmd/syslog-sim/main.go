@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/ibm-live-project-interns/datasource/pkg/chaos"
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
 	"github.com/ibm-live-project-interns/datasource/pkg/syslogsim"
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
 )
 
 func main() {
@@ -16,10 +23,86 @@ func main() {
 	interval := flag.Duration("interval", 2*time.Second, "Interval between batches")
 	batchSize := flag.Int("batch", 5, "Number of messages per batch")
 	totalBatches := flag.Int("batches", 3, "Total batches to send (0 = infinite)")
-	filePath := flag.String("file", "data/syslog-events.json", "File to store syslog events")
+	filePath := flag.String("file", "data/syslog-events.json", "File to store syslog events (empty to disable)")
+	seed := flag.Int64("seed", 0, "Random seed for reproducible messages (0 = random)")
+	minimal := flag.Bool("minimal-headers", false, `Write "- - -" for PROCID, MSGID and STRUCTURED-DATA`)
+	zone := flag.String("tz", "UTC", `Time zone of message timestamps, e.g. "Local" or "Europe/Berlin"`)
+	useBOM := flag.Bool("bom", false, "Prefix messages with the UTF-8 byte order mark")
+	dialectName := flag.String("dialect", "rfc5424", "Output format: rfc5424, rfc3164, cisco, junos, cef or leef")
+	buffer := flag.Int("buffer", 1000, "Messages to hold while a TCP connection is down (0 = drop them)")
+	profileName := flag.String("profile", "", "Traffic profile: preset (steady|poisson|diurnal|storm) or YAML file; paces messages individually")
+	templates := flag.String("templates", "", "Message template library: built in (network|server|security|wireless) or YAML file (default mixes network and server)")
+	var timing simclock.Options
+	timing.RegisterFlags(flag.CommandLine)
+	var chaosCfg chaos.Config
+	chaosCfg.RegisterFlags(flag.CommandLine)
+
+	load := flag.Bool("load", false, "Load-test mode: concurrent senders replaying a message pool, with live stats (no persistence)")
+	senders := flag.Int("senders", 8, "Load mode: concurrent connections")
+	rate := flag.Float64("rate", 0, "Load mode: target messages per second across senders (0 = unlimited)")
+	messages := flag.Int64("messages", 0, "Load mode: stop after this many messages (0 = until -duration or Ctrl-C)")
+	poolSize := flag.Int("pool", 10000, "Load mode: distinct pre-generated messages")
+	report := flag.Duration("report", time.Second, "Load mode: interval between live stats")
 
 	flag.Parse()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	location, err := time.LoadLocation(*zone)
+	if err != nil {
+		log.Fatalf("invalid -tz: %v", err)
+	}
+	headers := syslogsim.Headers{Minimal: *minimal, Location: location, BOM: *useBOM}
+	dialect, err := syslogsim.ParseDialect(*dialectName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var library *syslogsim.Library
+	if *templates != "" {
+		if library, err = syslogsim.LoadLibrary(*templates); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *load {
+		if timing.Backfill > 0 || timing.Speed != 1 || !timing.Start.IsZero() {
+			log.Fatal("-load runs in real time; -backfill, -speed and -start do not apply")
+		}
+		if chaosCfg.Enabled() {
+			log.Fatal("-load replays a fixed message pool; -chaos does not apply")
+		}
+		runLoad(ctx, syslogsim.LoadConfig{
+			Host:        *host,
+			Port:        *port,
+			Protocol:    *protocol,
+			Senders:     *senders,
+			Rate:        *rate,
+			Duration:    timing.Duration,
+			Total:       *messages,
+			PoolSize:    *poolSize,
+			Seed:        simclock.Seed(*seed),
+			Library:     library,
+			Headers:     headers,
+			Dialect:     dialect,
+			ReportEvery: *report,
+		})
+		return
+	}
+
+	clock, err := timing.NewClock(time.Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var profile *traffic.Profile
+	if *profileName != "" {
+		if profile, err = traffic.Load(*profileName); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	cfg := syslogsim.Config{
 		Host:         *host,
 		Port:         *port,
@@ -28,14 +111,43 @@ func main() {
 		BatchSize:    *batchSize,
 		TotalBatches: *totalBatches,
 		FilePath:     *filePath,
+		Seed:         simclock.Seed(*seed),
+		Clock:        clock,
+		Profile:      profile,
+		Library:      library,
+		Headers:      headers,
+		Dialect:      dialect,
+		Buffer:       *buffer,
+		Chaos:        chaosCfg,
 	}
 
 	fmt.Printf(
-		"Starting syslog simulation to %s:%d over %s\n",
-		cfg.Host, cfg.Port, cfg.Protocol,
+		"Starting syslog simulation to %s:%d over %s, seed=%d\n",
+		cfg.Host, cfg.Port, cfg.Protocol, cfg.Seed,
 	)
 
-	if err := syslogsim.RunSimulation(cfg); err != nil {
+	summary, err := syslogsim.RunSimulation(ctx, cfg)
+	fmt.Println("Syslog simulation finished:", summary)
+	if summary.SendErr != nil {
+		fmt.Println("warning: last send error:", summary.SendErr)
+	}
+	if summary.SaveErr != nil {
+		fmt.Println("warning: last save error:", summary.SaveErr)
+	}
+	if err != nil {
 		log.Fatalf("simulation failed: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// runLoad runs a load test until it completes or ctx is cancelled, printing
+// live stats and a final summary.
+func runLoad(ctx context.Context, cfg syslogsim.LoadConfig) {
+	fmt.Printf("Load test to %s:%d over %s with %d senders, seed=%d\n",
+		cfg.Host, cfg.Port, cfg.Protocol, cfg.Senders, cfg.Seed)
+
+	final, err := syslogsim.RunLoad(ctx, cfg, func(r syslogsim.LoadReport) { fmt.Println(r) })
+	if err != nil {
+		log.Fatalf("load test failed: %v", err)
+	}
+	fmt.Println("total:", final)
+}
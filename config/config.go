@@ -2,15 +2,23 @@
 // simulator. It defines device and simulator configuration structures that map
 // to the config/sample.yml reference file.
 //
-// NOTE: This package is available but not yet integrated into the simulator/
-// runtime. The simulator.Manager currently hardcodes its device list. Future
-// iterations should call LoadConfig to read devices from sample.yml and pass
-// them to the Manager.
+// Device entries may describe several devices at once, either with a numeric
+// range in the name ("router-{01..50}") or with a count ("count: 3" on
+// "switch" yields switch-1, switch-2, switch-3). Expand turns a configuration
+// into the concrete device list consumed by simulator.NewManagerFromConfig.
 package config
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/ibm-live-project-interns/datasource/pkg/syslogsim"
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
 	"gopkg.in/yaml.v2"
 )
 
@@ -18,15 +26,35 @@ import (
 type DeviceConfig struct {
 	Name           string `yaml:"name"`
 	Type           string `yaml:"type"`
-	TrapInterval   int    `yaml:"trap_interval"`
-	SyslogInterval int    `yaml:"syslog_interval"`
+	Count          int    `yaml:"count"`           // Number of devices to create from this entry (0 or 1 = one)
+	TrapInterval   int    `yaml:"trap_interval"`   // Seconds between SNMP traps
+	SyslogInterval int    `yaml:"syslog_interval"` // Seconds between syslog messages
+	TrapTarget     string `yaml:"trap_target"`     // UDP host:port for traps; overrides the top-level value
+	SyslogTarget   string `yaml:"syslog_target"`   // UDP host:port for syslog; overrides the top-level value
+
+	// Profile shapes the device's event rate: a preset name (see
+	// traffic.Presets) or a full profile. Nil sends at the fixed intervals.
+	Profile *traffic.Profile `yaml:"profile"`
+
+	// Dialect is the device's syslog output format (see
+	// syslogsim.Dialects); empty means RFC 5424.
+	Dialect string `yaml:"dialect"`
 }
 
 // SimulatorConfig is the top-level configuration loaded from YAML.
 type SimulatorConfig struct {
-	Devices []DeviceConfig `yaml:"devices"`
+	TrapTarget   string         `yaml:"trap_target"`   // Default UDP host:port for traps
+	SyslogTarget string         `yaml:"syslog_target"` // Default UDP host:port for syslog
+	Seed         int64          `yaml:"seed"`          // Random seed for all devices; 0 picks one from the clock
+	Devices      []DeviceConfig `yaml:"devices"`
 }
 
+// Default telemetry destinations, matching the standalone simulators.
+const (
+	DefaultTrapTarget   = "localhost:5162"
+	DefaultSyslogTarget = "localhost:5140"
+)
+
 // LoadConfig reads and parses a YAML configuration file at the given path.
 func LoadConfig(path string) (*SimulatorConfig, error) {
 	data, err := os.ReadFile(path)
@@ -41,3 +69,155 @@ func LoadConfig(path string) (*SimulatorConfig, error) {
 
 	return &cfg, nil
 }
+
+// maxExpandedDevices bounds how many devices a single entry may produce, to
+// catch typos such as "{1..100000}".
+const maxExpandedDevices = 10000
+
+// rangePattern matches a "{start..end}" name template.
+var rangePattern = regexp.MustCompile(`\{(\d+)\.\.(\d+)\}`)
+
+// Expand validates the configuration and returns one DeviceConfig per
+// concrete device, with name templates and counts expanded and telemetry
+// targets filled in from the top-level defaults. Errors identify
+// the offending entry by index and name; all problems are reported together.
+func (c *SimulatorConfig) Expand() ([]DeviceConfig, error) {
+	var devices []DeviceConfig
+	var errs []error
+	seen := make(map[string]int)
+
+	for i, d := range c.Devices {
+		names, err := expandNames(d)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("devices[%d] (%s): %w", i, d.Name, err))
+			continue
+		}
+
+		if err := validateIntervals(d); err != nil {
+			errs = append(errs, fmt.Errorf("devices[%d] (%s): %w", i, d.Name, err))
+			continue
+		}
+
+		if err := validateProfile(d.Profile); err != nil {
+			errs = append(errs, fmt.Errorf("devices[%d] (%s): %w", i, d.Name, err))
+			continue
+		}
+
+		if _, err := syslogsim.ParseDialect(d.Dialect); err != nil {
+			errs = append(errs, fmt.Errorf("devices[%d] (%s): %w", i, d.Name, err))
+			continue
+		}
+
+		for _, name := range names {
+			if prev, dup := seen[name]; dup {
+				errs = append(errs, fmt.Errorf("devices[%d]: device name %q already defined by devices[%d]", i, name, prev))
+				continue
+			}
+			seen[name] = i
+
+			dev := d
+			dev.Name = name
+			dev.Count = 0
+			dev.TrapTarget = cmp.Or(d.TrapTarget, c.TrapTarget, DefaultTrapTarget)
+			dev.SyslogTarget = cmp.Or(d.SyslogTarget, c.SyslogTarget, DefaultSyslogTarget)
+			devices = append(devices, dev)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(devices) == 0 {
+		return nil, errors.New("no devices configured")
+	}
+
+	return devices, nil
+}
+
+// expandNames returns the concrete device names for an entry.
+func expandNames(d DeviceConfig) ([]string, error) {
+	name := strings.TrimSpace(d.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if d.Count < 0 {
+		return nil, fmt.Errorf("count must not be negative, got %d", d.Count)
+	}
+
+	matches := rangePattern.FindAllStringSubmatchIndex(name, -1)
+	switch {
+	case len(matches) > 1:
+		return nil, errors.New("name may contain at most one {start..end} range")
+	case len(matches) == 1 && d.Count > 1:
+		return nil, errors.New("use either a {start..end} name range or count, not both")
+	case len(matches) == 0 && strings.ContainsAny(name, "{}"):
+		return nil, errors.New(`malformed name template, expected e.g. "router-{01..50}"`)
+	case len(matches) == 0 && d.Count > 1:
+		if d.Count > maxExpandedDevices {
+			return nil, fmt.Errorf("count %d exceeds the limit of %d devices", d.Count, maxExpandedDevices)
+		}
+		names := make([]string, d.Count)
+		for n := range names {
+			names[n] = fmt.Sprintf("%s-%d", name, n+1)
+		}
+		return names, nil
+	case len(matches) == 0:
+		return []string{name}, nil
+	}
+
+	m := matches[0]
+	startTok, endTok := name[m[2]:m[3]], name[m[4]:m[5]]
+	start, _ := strconv.Atoi(startTok)
+	end, _ := strconv.Atoi(endTok)
+
+	if end < start {
+		return nil, fmt.Errorf("range {%s..%s} is descending", startTok, endTok)
+	}
+	if end-start+1 > maxExpandedDevices {
+		return nil, fmt.Errorf("range {%s..%s} exceeds the limit of %d devices", startTok, endTok, maxExpandedDevices)
+	}
+
+	// A leading zero on the start value ("01") pads all numbers to its width.
+	width := 0
+	if len(startTok) > 1 && startTok[0] == '0' {
+		width = len(startTok)
+	}
+
+	prefix, suffix := name[:m[0]], name[m[1]:]
+	names := make([]string, 0, end-start+1)
+	for n := start; n <= end; n++ {
+		names = append(names, fmt.Sprintf("%s%0*d%s", prefix, width, n, suffix))
+	}
+	return names, nil
+}
+
+// validateIntervals checks that configured intervals are not negative.
+// Whether an interval is required depends on the device type and is checked
+// by the simulator.
+func validateIntervals(d DeviceConfig) error {
+	if d.TrapInterval < 0 {
+		return fmt.Errorf("trap_interval must not be negative, got %d", d.TrapInterval)
+	}
+	if d.SyslogInterval < 0 {
+		return fmt.Errorf("syslog_interval must not be negative, got %d", d.SyslogInterval)
+	}
+	return nil
+}
+
+// validateProfile checks a device's traffic profile, if any. Severity mixes
+// must name syslog severities: they weight the device's state transitions by
+// the severity of their syslog lines.
+func validateProfile(p *traffic.Profile) error {
+	if p == nil {
+		return nil
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	for name := range p.Severities {
+		if _, err := syslogsim.ParseSeverity(name); err != nil {
+			return fmt.Errorf("invalid traffic profile: %w", err)
+		}
+	}
+	return nil
+}
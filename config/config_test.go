@@ -0,0 +1,168 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
+)
+
+func names(devices []DeviceConfig) []string {
+	out := make([]string, len(devices))
+	for i, d := range devices {
+		out[i] = d.Name
+	}
+	return out
+}
+
+func TestLoadConfig_Sample(t *testing.T) {
+	cfg, err := LoadConfig("sample.yml")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	devices, err := cfg.Expand()
+	if err != nil {
+		t.Fatalf("expected sample config to be valid, got %v", err)
+	}
+
+	if got := names(devices); !reflect.DeepEqual(got, []string{"router-1", "switch-1"}) {
+		t.Errorf("unexpected devices: %v", got)
+	}
+	if devices[0].TrapInterval != 5 || devices[1].SyslogInterval != 15 {
+		t.Errorf("unexpected intervals: %+v", devices)
+	}
+}
+
+func TestExpand_Templates(t *testing.T) {
+	tests := []struct {
+		name   string
+		device DeviceConfig
+		want   []string
+	}{
+		{"plain", DeviceConfig{Name: "core-1"}, []string{"core-1"}},
+		{"zero padded range", DeviceConfig{Name: "router-{01..03}"}, []string{"router-01", "router-02", "router-03"}},
+		{"unpadded range", DeviceConfig{Name: "sw{9..11}"}, []string{"sw9", "sw10", "sw11"}},
+		{"range with suffix", DeviceConfig{Name: "ap-{1..2}.branch"}, []string{"ap-1.branch", "ap-2.branch"}},
+		{"count", DeviceConfig{Name: "fw", Count: 3}, []string{"fw-1", "fw-2", "fw-3"}},
+		{"count of one", DeviceConfig{Name: "fw", Count: 1}, []string{"fw"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := SimulatorConfig{Devices: []DeviceConfig{tt.device}}
+			devices, err := cfg.Expand()
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got := names(devices); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpand_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		devices []DeviceConfig
+		wantErr string
+	}{
+		{"empty", nil, "no devices configured"},
+		{"missing name", []DeviceConfig{{Type: "router"}}, "devices[0] (): name is required"},
+		{"descending range", []DeviceConfig{{Name: "r-{5..1}"}}, "is descending"},
+		{"malformed template", []DeviceConfig{{Name: "r-{1-5}"}}, "malformed name template"},
+		{"range and count", []DeviceConfig{{Name: "r-{1..5}", Count: 2}}, "not both"},
+		{"two ranges", []DeviceConfig{{Name: "r-{1..2}-{1..2}"}}, "at most one"},
+		{"too many", []DeviceConfig{{Name: "r-{1..20000}"}}, "exceeds the limit"},
+		{"negative interval", []DeviceConfig{{Name: "r", TrapInterval: -1}}, "trap_interval must not be negative"},
+		{"duplicate", []DeviceConfig{{Name: "r-{1..3}"}, {Name: "r-2"}}, `device name "r-2" already defined by devices[0]`},
+		{"invalid profile", []DeviceConfig{{Name: "r", Profile: &traffic.Profile{Arrivals: "bursty"}}}, "arrivals must be"},
+		{"unknown severity", []DeviceConfig{{Name: "r", Profile: &traffic.Profile{Severities: map[string]float64{"fatal": 1}}}}, `unknown syslog severity "fatal"`},
+		{"unknown dialect", []DeviceConfig{{Name: "r", Dialect: "syslog-ng"}}, `unknown syslog dialect "syslog-ng"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := SimulatorConfig{Devices: tt.devices}
+			_, err := cfg.Expand()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExpand_Targets(t *testing.T) {
+	cfg := &SimulatorConfig{
+		SyslogTarget: "collector:514",
+		Devices: []DeviceConfig{
+			{Name: "router-1", Type: "router", TrapInterval: 5, TrapTarget: "10.0.0.9:162"},
+			{Name: "switch-1", Type: "switch", SyslogInterval: 5},
+		},
+	}
+
+	devices, err := cfg.Expand()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if devices[0].TrapTarget != "10.0.0.9:162" {
+		t.Errorf("expected per-device trap target, got %q", devices[0].TrapTarget)
+	}
+	if devices[1].TrapTarget != DefaultTrapTarget {
+		t.Errorf("expected default trap target, got %q", devices[1].TrapTarget)
+	}
+	if devices[1].SyslogTarget != "collector:514" {
+		t.Errorf("expected top-level syslog target, got %q", devices[1].SyslogTarget)
+	}
+}
+
+func TestLoadConfig_InvalidYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.yml")
+	if err := os.WriteFile(path, []byte("devices: [name: x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatalf("expected error for invalid YAML, got nil")
+	}
+}
+
+func TestLoadConfig_Profiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.yml")
+	data := `devices:
+  - name: r-1
+    trap_interval: 5
+    profile: storm
+  - name: r-2
+    trap_interval: 5
+    profile:
+      arrivals: poisson
+      bursts:
+        - {after: 30s, duration: 10s, rate: 500}
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := cfg.Expand(); err != nil {
+		t.Fatalf("expected profiles to be valid, got %v", err)
+	}
+
+	storm, _ := traffic.Preset("storm")
+	if !reflect.DeepEqual(cfg.Devices[0].Profile, storm) {
+		t.Errorf("expected storm preset, got %+v", cfg.Devices[0].Profile)
+	}
+	if b := cfg.Devices[1].Profile.Bursts; len(b) != 1 || b[0].After != 30*time.Second || b[0].Rate != 500 {
+		t.Errorf("unexpected bursts: %+v", b)
+	}
+}
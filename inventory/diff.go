@@ -0,0 +1,171 @@
+package inventory
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/mapper"
+	"github.com/ibm-live-project-interns/datasource/pkg/metadatasim"
+	"github.com/ibm-live-project-interns/ingestor/shared/models"
+)
+
+// ChangeKind describes how a device differs between two inventory snapshots.
+type ChangeKind string
+
+const (
+	DeviceAdded    ChangeKind = "added"    // Device present only in the new snapshot
+	DeviceRemoved  ChangeKind = "removed"  // Device present only in the old snapshot
+	DeviceModified ChangeKind = "modified" // Device present in both with differing fields
+)
+
+// FieldChange holds the before and after value of a single metadata field.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Change describes the difference for one device between two snapshots.
+type Change struct {
+	Kind      ChangeKind    `json:"change"`
+	DeviceID  string        `json:"device_id"`
+	Hostname  string        `json:"hostname"`
+	Fields    []FieldChange `json:"fields"`
+	UpdatedAt string        `json:"updated_at,omitempty"`
+}
+
+// trackedFields lists the metadata fields compared by Diff. UpdatedAt is
+// deliberately excluded so that a rewrite that only bumps the timestamp is
+// treated as a no-op.
+var trackedFields = []struct {
+	name string
+	get  func(metadatasim.Device) string
+}{
+	{"hostname", func(d metadatasim.Device) string { return d.Hostname }},
+	{"ip", func(d metadatasim.Device) string { return d.IP }},
+	{"vendor", func(d metadatasim.Device) string { return d.Vendor }},
+	{"model", func(d metadatasim.Device) string { return d.Model }},
+	{"os", func(d metadatasim.Device) string { return d.OS }},
+	{"location", func(d metadatasim.Device) string { return d.Location }},
+}
+
+// Diff compares two inventory snapshots and returns one Change per added,
+// removed or modified device, ordered by device key. Devices are matched by
+// ID, falling back to hostname for records without an ID.
+func Diff(before, after []metadatasim.Device) []Change {
+	old := indexByKey(before)
+	cur := indexByKey(after)
+
+	keys := make([]string, 0, len(old)+len(cur))
+	for k := range old {
+		keys = append(keys, k)
+	}
+	for k := range cur {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []Change
+	for _, k := range keys {
+		o, hadOld := old[k]
+		n, hasNew := cur[k]
+
+		switch {
+		case !hadOld:
+			changes = append(changes, newChange(DeviceAdded, n, compareFields(metadatasim.Device{}, n)))
+		case !hasNew:
+			changes = append(changes, newChange(DeviceRemoved, o, compareFields(o, metadatasim.Device{})))
+		default:
+			if fields := compareFields(o, n); len(fields) > 0 {
+				changes = append(changes, newChange(DeviceModified, n, fields))
+			}
+		}
+	}
+
+	return changes
+}
+
+// Payload renders the change as a metadata JSON document understood by
+// mapper.MapMetadata. The per-field before/after values are carried in data.
+func (c Change) Payload() ([]byte, error) {
+	ts := c.UpdatedAt
+	if _, err := time.Parse(time.RFC3339, ts); err != nil {
+		ts = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	return json.Marshal(mapper.MetadataInput{
+		Entity:    c.Hostname,
+		Data:      c,
+		Timestamp: ts,
+	})
+}
+
+// Event maps the change into a shared metadata Event.
+func (c Change) Event() (models.Event, error) {
+	payload, err := c.Payload()
+	if err != nil {
+		return models.Event{}, err
+	}
+	return mapper.MapMetadata(payload)
+}
+
+// Watcher compares successive inventory snapshots and reports what changed
+// since the previous one. Snapshots identical in all tracked fields produce no
+// changes, which suppresses no-op rewrites of the metadata file.
+type Watcher struct {
+	mu   sync.Mutex
+	last []metadatasim.Device
+}
+
+// NewWatcher creates a Watcher whose baseline is the given snapshot.
+func NewWatcher(initial []metadatasim.Device) *Watcher {
+	return &Watcher{last: append([]metadatasim.Device(nil), initial...)}
+}
+
+// Observe records a new snapshot and returns the changes relative to the
+// previous one, or nil if nothing changed.
+func (w *Watcher) Observe(devices []metadatasim.Device) []Change {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	changes := Diff(w.last, devices)
+	w.last = append([]metadatasim.Device(nil), devices...)
+	return changes
+}
+
+func newChange(kind ChangeKind, d metadatasim.Device, fields []FieldChange) Change {
+	return Change{
+		Kind:      kind,
+		DeviceID:  d.ID,
+		Hostname:  d.Hostname,
+		Fields:    fields,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+func compareFields(before, after metadatasim.Device) []FieldChange {
+	var fields []FieldChange
+	for _, f := range trackedFields {
+		b, a := f.get(before), f.get(after)
+		if b != a {
+			fields = append(fields, FieldChange{Field: f.name, Before: b, After: a})
+		}
+	}
+	return fields
+}
+
+func indexByKey(devices []metadatasim.Device) map[string]metadatasim.Device {
+	idx := make(map[string]metadatasim.Device, len(devices))
+	for _, d := range devices {
+		key := d.ID
+		if key == "" {
+			key = hostKey(d.Hostname)
+		}
+		idx[key] = d
+	}
+	return idx
+}
@@ -0,0 +1,110 @@
+package inventory
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/metadatasim"
+	"github.com/ibm-live-project-interns/ingestor/shared/constants"
+)
+
+func TestDiff_AddedRemovedModified(t *testing.T) {
+	before := sampleDevices()
+	after := []metadatasim.Device{
+		before[0],
+		{ID: "dev-003", Hostname: "fw-1", IP: "10.0.0.3", Vendor: "Juniper"},
+	}
+	after[0].OS = "IOS-XE 17.3 (patched)"
+	after[0].UpdatedAt = "2025-12-10T18:00:00Z"
+
+	changes := Diff(before, after)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d: %+v", len(changes), changes)
+	}
+
+	modified := changes[0]
+	if modified.Kind != DeviceModified || modified.DeviceID != "dev-001" {
+		t.Fatalf("unexpected first change: %+v", modified)
+	}
+	if len(modified.Fields) != 1 || modified.Fields[0] != (FieldChange{Field: "os", Before: "IOS-XE 17.3", After: "IOS-XE 17.3 (patched)"}) {
+		t.Errorf("unexpected field changes: %+v", modified.Fields)
+	}
+
+	if changes[1].Kind != DeviceRemoved || changes[1].Hostname != "switch-1" {
+		t.Errorf("expected switch-1 removed, got %+v", changes[1])
+	}
+
+	if changes[2].Kind != DeviceAdded || changes[2].Hostname != "fw-1" {
+		t.Errorf("expected fw-1 added, got %+v", changes[2])
+	}
+}
+
+func TestWatcher_SuppressesNoOpRewrite(t *testing.T) {
+	devices := sampleDevices()
+	w := NewWatcher(devices)
+
+	rewritten := sampleDevices()
+	rewritten[1].UpdatedAt = "2025-12-10T18:00:00Z"
+
+	if changes := w.Observe(rewritten); changes != nil {
+		t.Fatalf("expected no changes for timestamp-only rewrite, got %+v", changes)
+	}
+
+	rewritten[1].Location = "DC2-Rack5-Alt"
+	if changes := w.Observe(rewritten); len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %+v", changes)
+	}
+
+	if changes := w.Observe(rewritten); changes != nil {
+		t.Fatalf("expected identical snapshot to be a no-op, got %+v", changes)
+	}
+}
+
+func TestInventory_OnChange(t *testing.T) {
+	inv := New("")
+	inv.Replace(sampleDevices())
+
+	var got []Change
+	inv.OnChange(func(changes []Change) { got = append(got, changes...) })
+
+	inv.Replace(sampleDevices())
+	if len(got) != 0 {
+		t.Fatalf("expected no notification for identical snapshot, got %+v", got)
+	}
+
+	devices := sampleDevices()
+	devices[0].Location = "DC1-Rack1-Alt"
+	inv.Replace(devices)
+	if len(got) != 1 || got[0].Fields[0].Field != "location" {
+		t.Fatalf("expected location change, got %+v", got)
+	}
+}
+
+func TestChange_Event(t *testing.T) {
+	c := Change{
+		Kind:      DeviceModified,
+		DeviceID:  "dev-001",
+		Hostname:  "router-1",
+		Fields:    []FieldChange{{Field: "os", Before: "a", After: "b"}},
+		UpdatedAt: "2025-12-10T18:00:00Z",
+	}
+
+	event, err := c.Event()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if event.EventType != constants.EventTypeMetadata || event.SourceHost != "router-1" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	var payload struct {
+		Data Change `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(event.RawPayload), &payload); err != nil {
+		t.Fatalf("raw payload is not JSON: %v", err)
+	}
+	if payload.Data.Fields[0].Before != "a" || payload.Data.Fields[0].After != "b" {
+		t.Errorf("expected before/after in payload, got %+v", payload.Data)
+	}
+}
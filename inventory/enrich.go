@@ -0,0 +1,133 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/metadatasim"
+	"github.com/ibm-live-project-interns/ingestor/shared/models"
+)
+
+// UnknownPolicy controls what the Enricher does with events whose source is
+// not present in the inventory.
+type UnknownPolicy string
+
+const (
+	UnknownPass UnknownPolicy = "pass" // Forward the event without device attributes
+	UnknownTag  UnknownPolicy = "tag"  // Forward the event with device.known=false
+	UnknownDrop UnknownPolicy = "drop" // Drop the event entirely
+)
+
+// ParseUnknownPolicy converts a configuration value into an UnknownPolicy.
+// An empty string selects UnknownTag.
+func ParseUnknownPolicy(s string) (UnknownPolicy, error) {
+	switch UnknownPolicy(s) {
+	case "":
+		return UnknownTag, nil
+	case UnknownPass, UnknownTag, UnknownDrop:
+		return UnknownPolicy(s), nil
+	default:
+		return "", fmt.Errorf("invalid unknown device policy %q (want pass, tag or drop)", s)
+	}
+}
+
+// DeviceAttributes are the inventory fields attached to an enriched event.
+type DeviceAttributes struct {
+	Known    bool   `json:"known"`              // Whether the source matched an inventory device
+	ID       string `json:"id,omitempty"`       // Inventory device ID (e.g. "dev-001")
+	Hostname string `json:"hostname,omitempty"` // Inventory hostname
+	IP       string `json:"ip,omitempty"`       // Inventory management IP
+	Vendor   string `json:"vendor,omitempty"`   // Hardware vendor
+	Model    string `json:"model,omitempty"`    // Hardware model
+	OS       string `json:"os,omitempty"`       // Operating system version
+	Location string `json:"location,omitempty"` // Physical location
+}
+
+// EnrichedEvent is a shared Event plus the device attributes looked up from
+// the inventory. It serializes as the Event's JSON with an extra "device" key.
+type EnrichedEvent struct {
+	Event  models.Event
+	Device *DeviceAttributes
+}
+
+// BaseEvent returns the wrapped Event, for client.IngestorClient.
+func (e EnrichedEvent) BaseEvent() models.Event { return e.Event }
+
+// MarshalJSON flattens the wrapped Event and adds the "device" object.
+func (e EnrichedEvent) MarshalJSON() ([]byte, error) {
+	base, err := json.Marshal(e.Event)
+	if err != nil {
+		return nil, err
+	}
+	if e.Device == nil {
+		return base, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(base, &fields); err != nil {
+		return nil, err
+	}
+
+	device, err := json.Marshal(e.Device)
+	if err != nil {
+		return nil, err
+	}
+	fields["device"] = device
+
+	return json.Marshal(fields)
+}
+
+// Enricher attaches inventory attributes to events.
+type Enricher struct {
+	inv     *Inventory
+	unknown UnknownPolicy
+}
+
+// NewEnricher creates an Enricher backed by inv that applies the given policy
+// to events from devices missing in the inventory.
+func NewEnricher(inv *Inventory, unknown UnknownPolicy) *Enricher {
+	return &Enricher{inv: inv, unknown: unknown}
+}
+
+// Enrich looks up the event's source by hostname, then by IP, and returns the
+// enriched event. The boolean is false when the unknown-device policy drops
+// the event.
+//
+// When the device is known but the resolver could not find an address for it,
+// the inventory's management IP is used as the event's SourceIP.
+func (e *Enricher) Enrich(event models.Event) (EnrichedEvent, bool) {
+	d, ok := e.inv.Lookup(event.SourceHost)
+	if !ok {
+		d, ok = e.inv.Lookup(event.SourceIP)
+	}
+
+	if !ok {
+		switch e.unknown {
+		case UnknownDrop:
+			return EnrichedEvent{}, false
+		case UnknownPass:
+			return EnrichedEvent{Event: event}, true
+		default:
+			return EnrichedEvent{Event: event, Device: &DeviceAttributes{Known: false}}, true
+		}
+	}
+
+	if ipKey(event.SourceIP) == "" && d.IP != "" {
+		event.SourceIP = d.IP
+	}
+
+	return EnrichedEvent{Event: event, Device: attributesOf(d)}, true
+}
+
+func attributesOf(d metadatasim.Device) *DeviceAttributes {
+	return &DeviceAttributes{
+		Known:    true,
+		ID:       d.ID,
+		Hostname: d.Hostname,
+		IP:       d.IP,
+		Vendor:   d.Vendor,
+		Model:    d.Model,
+		OS:       d.OS,
+		Location: d.Location,
+	}
+}
@@ -0,0 +1,238 @@
+// Package inventory keeps an in-memory view of the device metadata published
+// by pkg/metadatasim and uses it to enrich outgoing events with device
+// attributes (vendor, model, OS, location).
+//
+// The inventory is loaded from the shared devices-metadata.json file and can
+// be refreshed whenever the file changes on disk; source.MetadataSource
+// watches the file and replaces the inventory's devices. Devices are indexed by both
+// hostname and management IP so that events can be matched regardless of which
+// identifier the originating payload carried.
+//
+// Successive snapshots are compared field by field so that consumers can be
+// notified of added, removed and modified devices (see Diff and Watcher).
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/metadatasim"
+)
+
+// Inventory is a concurrency-safe, in-memory index of device metadata.
+type Inventory struct {
+	path string
+
+	mu      sync.RWMutex
+	devices []metadatasim.Device
+	byHost  map[string]metadatasim.Device
+	byIP    map[string]metadatasim.Device
+
+	// modTime and size identify the file version currently loaded so that
+	// Refresh can skip re-parsing when nothing changed.
+	modTime time.Time
+	size    int64
+
+	watcher   *Watcher
+	listeners []func([]Change)
+}
+
+// New creates an empty Inventory backed by the metadata file at path.
+// Call Refresh (or use Load) to populate it.
+func New(path string) *Inventory {
+	return &Inventory{
+		path:    path,
+		byHost:  make(map[string]metadatasim.Device),
+		byIP:    make(map[string]metadatasim.Device),
+		watcher: NewWatcher(nil),
+	}
+}
+
+// Load creates an Inventory and populates it from the metadata file at path.
+func Load(path string) (*Inventory, error) {
+	inv := New(path)
+	if _, err := inv.Refresh(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// Path returns the metadata file backing the inventory.
+func (i *Inventory) Path() string {
+	return i.path
+}
+
+// Refresh reloads the metadata file if it changed since the last load.
+// It reports whether the in-memory inventory was replaced.
+func (i *Inventory) Refresh() (bool, error) {
+	info, err := os.Stat(i.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat metadata file: %w", err)
+	}
+
+	i.mu.RLock()
+	unchanged := info.ModTime().Equal(i.modTime) && info.Size() == i.size
+	i.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(i.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read metadata file: %w", err)
+	}
+
+	devices, err := Parse(data)
+	if err != nil {
+		return false, fmt.Errorf("invalid metadata file %s: %w", i.path, err)
+	}
+
+	i.Replace(devices)
+
+	i.mu.Lock()
+	i.modTime = info.ModTime()
+	i.size = info.Size()
+	i.mu.Unlock()
+
+	return true, nil
+}
+
+// OnChange registers fn to be called with the device changes each time the
+// inventory contents are replaced. It is not called for no-op replacements.
+func (i *Inventory) OnChange(fn func([]Change)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.listeners = append(i.listeners, fn)
+}
+
+// Replace swaps the inventory contents for the given devices, rebuilds the
+// hostname and IP indexes and notifies OnChange listeners of any differences.
+func (i *Inventory) Replace(devices []metadatasim.Device) {
+	byHost := make(map[string]metadatasim.Device, len(devices))
+	byIP := make(map[string]metadatasim.Device, len(devices))
+
+	for _, d := range devices {
+		if d.Hostname != "" {
+			byHost[hostKey(d.Hostname)] = d
+		}
+		if key := ipKey(d.IP); key != "" {
+			byIP[key] = d
+		}
+	}
+
+	i.mu.Lock()
+	i.devices = append([]metadatasim.Device(nil), devices...)
+	i.byHost = byHost
+	i.byIP = byIP
+	changes := i.watcher.Observe(devices)
+	listeners := append(([]func([]Change))(nil), i.listeners...)
+	i.mu.Unlock()
+
+	if len(changes) == 0 {
+		return
+	}
+	for _, fn := range listeners {
+		fn(changes)
+	}
+}
+
+// Lookup finds a device by hostname or IP address.
+func (i *Inventory) Lookup(hostOrIP string) (metadatasim.Device, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if key := ipKey(hostOrIP); key != "" {
+		d, ok := i.byIP[key]
+		return d, ok
+	}
+
+	d, ok := i.byHost[hostKey(hostOrIP)]
+	return d, ok
+}
+
+// LookupIP returns the management IP of the device with the given hostname
+// or IP. It matches the lookup signature of mapper.NewInventoryBackend.
+func (i *Inventory) LookupIP(hostOrIP string) (string, bool) {
+	d, ok := i.Lookup(hostOrIP)
+	if !ok || d.IP == "" {
+		return "", false
+	}
+	return d.IP, true
+}
+
+// Devices returns a copy of all devices currently in the inventory.
+func (i *Inventory) Devices() []metadatasim.Device {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return append([]metadatasim.Device(nil), i.devices...)
+}
+
+// Len returns the number of devices in the inventory.
+func (i *Inventory) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.devices)
+}
+
+// Parse decodes a devices-metadata.json document and validates it.
+func Parse(data []byte) ([]metadatasim.Device, error) {
+	var devices []metadatasim.Device
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return nil, err
+	}
+	if err := Validate(devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Validate checks that every device has an ID, a hostname and a valid IP, and
+// that IDs and hostnames are unique. All problems found are reported together.
+func Validate(devices []metadatasim.Device) error {
+	var errs []error
+	ids := make(map[string]bool, len(devices))
+	hosts := make(map[string]bool, len(devices))
+
+	for n, d := range devices {
+		if d.ID == "" {
+			errs = append(errs, fmt.Errorf("device %d: missing id", n))
+		} else if ids[d.ID] {
+			errs = append(errs, fmt.Errorf("device %d: duplicate id %q", n, d.ID))
+		}
+		ids[d.ID] = true
+
+		if d.Hostname == "" {
+			errs = append(errs, fmt.Errorf("device %d: missing hostname", n))
+		} else if hosts[hostKey(d.Hostname)] {
+			errs = append(errs, fmt.Errorf("device %d: duplicate hostname %q", n, d.Hostname))
+		}
+		hosts[hostKey(d.Hostname)] = true
+
+		if net.ParseIP(d.IP) == nil {
+			errs = append(errs, fmt.Errorf("device %d: invalid ip %q", n, d.IP))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// hostKey normalizes a hostname for case-insensitive lookups.
+func hostKey(host string) string {
+	return strings.ToLower(strings.TrimSpace(host))
+}
+
+// ipKey returns the canonical form of an IP address, or "" if s is not an IP.
+// The unspecified address is ignored since it is the resolver's failure value.
+func ipKey(s string) string {
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil || ip.IsUnspecified() {
+		return ""
+	}
+	return ip.String()
+}
@@ -0,0 +1,140 @@
+package inventory
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/metadatasim"
+	"github.com/ibm-live-project-interns/ingestor/shared/models"
+)
+
+func writeMetadata(t *testing.T, path string, devices []metadatasim.Device) {
+	t.Helper()
+	data, err := json.Marshal(devices)
+	if err != nil {
+		t.Fatalf("marshal metadata: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+}
+
+func sampleDevices() []metadatasim.Device {
+	return []metadatasim.Device{
+		{ID: "dev-001", Hostname: "router-1", IP: "10.0.0.1", Vendor: "Cisco", Model: "ISR-4000", OS: "IOS-XE 17.3", Location: "DC1-Rack1"},
+		{ID: "dev-002", Hostname: "switch-1", IP: "10.0.0.2", Vendor: "Arista", Model: "7050X3", OS: "EOS 4.28", Location: "DC2-Rack5"},
+	}
+}
+
+func TestLoad_LookupByHostAndIP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices.json")
+	writeMetadata(t, path, sampleDevices())
+
+	inv, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if d, ok := inv.Lookup("ROUTER-1"); !ok || d.ID != "dev-001" {
+		t.Errorf("expected router-1 by hostname, got %+v (found=%v)", d, ok)
+	}
+
+	if d, ok := inv.Lookup("10.0.0.2"); !ok || d.Hostname != "switch-1" {
+		t.Errorf("expected switch-1 by IP, got %+v (found=%v)", d, ok)
+	}
+
+	if _, ok := inv.Lookup("0.0.0.0"); ok {
+		t.Errorf("expected unspecified address to never match")
+	}
+}
+
+func TestRefresh_ReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices.json")
+	writeMetadata(t, path, sampleDevices())
+
+	inv, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	changed, err := inv.Refresh()
+	if err != nil || changed {
+		t.Fatalf("expected no reload for unchanged file, got changed=%v err=%v", changed, err)
+	}
+
+	devices := sampleDevices()
+	devices[0].OS = "IOS-XE 17.3 (patched)"
+	writeMetadata(t, path, devices)
+	future := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	changed, err = inv.Refresh()
+	if err != nil || !changed {
+		t.Fatalf("expected reload after change, got changed=%v err=%v", changed, err)
+	}
+
+	if d, _ := inv.Lookup("router-1"); d.OS != "IOS-XE 17.3 (patched)" {
+		t.Errorf("expected updated OS, got %s", d.OS)
+	}
+}
+
+func TestEnrich_KnownDevice(t *testing.T) {
+	inv := New("")
+	inv.Replace(sampleDevices())
+	e := NewEnricher(inv, UnknownDrop)
+
+	enriched, ok := e.Enrich(models.Event{SourceHost: "router-1", SourceIP: "0.0.0.0"})
+	if !ok {
+		t.Fatalf("expected known device to be forwarded")
+	}
+
+	if enriched.Device == nil || !enriched.Device.Known || enriched.Device.Vendor != "Cisco" {
+		t.Fatalf("unexpected device attributes: %+v", enriched.Device)
+	}
+
+	if enriched.Event.SourceIP != "10.0.0.1" {
+		t.Errorf("expected SourceIP backfilled from inventory, got %s", enriched.Event.SourceIP)
+	}
+
+	data, err := json.Marshal(enriched)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"device":{"known":true`) {
+		t.Errorf("expected device object in JSON, got %s", data)
+	}
+}
+
+func TestEnrich_UnknownPolicies(t *testing.T) {
+	inv := New("")
+	inv.Replace(sampleDevices())
+	event := models.Event{SourceHost: "mystery-host", SourceIP: "192.0.2.9"}
+
+	if _, ok := NewEnricher(inv, UnknownDrop).Enrich(event); ok {
+		t.Errorf("expected drop policy to drop unknown device")
+	}
+
+	if enriched, ok := NewEnricher(inv, UnknownPass).Enrich(event); !ok || enriched.Device != nil {
+		t.Errorf("expected pass policy to forward without attributes, got %+v", enriched.Device)
+	}
+
+	if enriched, ok := NewEnricher(inv, UnknownTag).Enrich(event); !ok || enriched.Device == nil || enriched.Device.Known {
+		t.Errorf("expected tag policy to mark device unknown, got %+v", enriched.Device)
+	}
+}
+
+func TestParseUnknownPolicy(t *testing.T) {
+	if p, err := ParseUnknownPolicy(""); err != nil || p != UnknownTag {
+		t.Errorf("expected default tag policy, got %q (%v)", p, err)
+	}
+
+	if _, err := ParseUnknownPolicy("ignore"); err == nil {
+		t.Errorf("expected error for invalid policy")
+	}
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/ibm-live-project-interns/datasource/client"
+	"github.com/ibm-live-project-interns/datasource/inventory"
 	"github.com/ibm-live-project-interns/datasource/mapper"
+	"github.com/ibm-live-project-interns/datasource/source"
 	"github.com/ibm-live-project-interns/ingestor/shared/config"
+	"github.com/ibm-live-project-interns/ingestor/shared/models"
 )
 
 func main() {
@@ -39,6 +45,55 @@ log.Printf("Loading config from %s", configPath)
 		fmt.Println("✅ Ingestor Core is healthy")
 	}
 
+	// Device inventory used to enrich events with vendor/model/OS/location
+	metadataPath := os.Getenv("DEVICE_METADATA_PATH")
+	if metadataPath == "" {
+		metadataPath = "data/devices-metadata.json"
+	}
+
+	unknownPolicy, err := inventory.ParseUnknownPolicy(os.Getenv("ENRICH_UNKNOWN_DEVICES"))
+	if err != nil {
+		log.Fatal("Invalid ENRICH_UNKNOWN_DEVICES:", err)
+	}
+
+	inv, err := inventory.Load(metadataPath)
+	if err != nil {
+		log.Printf("⚠️  Warning: device inventory unavailable: %v\n", err)
+		inv = inventory.New(metadataPath)
+	} else {
+		fmt.Printf("📇 Loaded %d devices from %s\n", inv.Len(), metadataPath)
+	}
+
+	// IP resolver chain: static hosts file → device inventory → DNS
+	resolver, err := buildResolver(inv)
+	if err != nil {
+		log.Fatal("IP resolver setup failed:", err)
+	}
+	eventMapper := mapper.NewMapper(resolver)
+
+	// Inventory edits change what the inventory backend answers, so cached
+	// lookups are dropped whenever the inventory changes
+	inv.OnChange(func([]inventory.Change) { resolver.Purge() })
+
+	// Library helpers (e.g. metadata change events) use the package-level mapper
+	mapper.SetDefaultResolver(resolver)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	enricher := inventory.NewEnricher(inv, unknownPolicy)
+
+	// Watch the metadata file and publish a metadata event for every device
+	// that changes in the inventory. Change events skip the unknown-device
+	// policy: a removed device has already left the inventory.
+	changeCount := 0
+	metadataSource := source.NewMetadataSource(inv, func(event models.Event) {
+		changeCount++
+		send(ingestorClient, "Metadata change", changeCount, event)
+	})
+	metadataSource.PollInterval = time.Duration(config.GetEnvInt("INVENTORY_REFRESH_SECONDS", 30)) * time.Second
+	go metadataSource.Run(ctx)
+
 	// Process Syslog events
 	rawSyslogs := [][]byte{
 		[]byte(`{
@@ -57,17 +112,13 @@ log.Printf("Loading config from %s", configPath)
 
 	fmt.Println("\n📤 Sending Syslog events...")
 	for i, raw := range rawSyslogs {
-		event, err := mapper.MapSyslog(raw)
+		event, err := eventMapper.MapSyslog(raw)
 		if err != nil {
 			log.Printf("❌ Syslog %d mapping failed: %v\n", i+1, err)
 			continue
 		}
 
-		if err := ingestorClient.SendEvent(event); err != nil {
-			log.Printf("❌ Syslog %d send failed: %v\n", i+1, err)
-		} else {
-			fmt.Printf("✅ Syslog %d sent successfully\n", i+1)
-		}
+		forward(ingestorClient, enricher, "Syslog", i+1, event)
 	}
 
 	// Process SNMP events
@@ -83,17 +134,13 @@ log.Printf("Loading config from %s", configPath)
 
 	fmt.Println("\n📤 Sending SNMP events...")
 	for i, raw := range rawSNMPs {
-		event, err := mapper.MapSNMP(raw)
+		event, err := eventMapper.MapSNMP(raw)
 		if err != nil {
 			log.Printf("❌ SNMP %d mapping failed: %v\n", i+1, err)
 			continue
 		}
 
-		if err := ingestorClient.SendEvent(event); err != nil {
-			log.Printf("❌ SNMP %d send failed: %v\n", i+1, err)
-		} else {
-			fmt.Printf("✅ SNMP %d sent successfully\n", i+1)
-		}
+		forward(ingestorClient, enricher, "SNMP", i+1, event)
 	}
 
 	// Process Metadata events
@@ -110,19 +157,82 @@ log.Printf("Loading config from %s", configPath)
 
 	fmt.Println("\n📤 Sending Metadata events...")
 	for i, raw := range rawMetadata {
-		event, err := mapper.MapMetadata(raw)
+		event, err := eventMapper.MapMetadata(raw)
 		if err != nil {
 			log.Printf("❌ Metadata %d mapping failed: %v\n", i+1, err)
 			continue
 		}
 
-		if err := ingestorClient.SendEvent(event); err != nil {
-			log.Printf("❌ Metadata %d send failed: %v\n", i+1, err)
-		} else {
-			fmt.Printf("✅ Metadata %d sent successfully\n", i+1)
-		}
+		send(ingestorClient, "Metadata", i+1, event)
 	}
 
 	fmt.Println("\n🎉 Datasource completed processing all events")
+
+	// Keep running to forward live metadata changes (e.g. alongside cmd/metadata-pub)
+	if os.Getenv("METADATA_WATCH") == "true" {
+		fmt.Printf("\n👀 Watching %s for metadata changes (Ctrl+C to stop)\n", metadataPath)
+		<-ctx.Done()
+		fmt.Println("\n👋 Datasource stopped")
+	}
+
+	cancel()
 	os.Exit(0)
 }
+
+// buildResolver assembles the IP resolver chain from IP_RESOLVER_* settings.
+func buildResolver(inv *inventory.Inventory) (*mapper.IPResolver, error) {
+	var backends []mapper.Backend
+
+	if path := os.Getenv("IP_RESOLVER_HOSTS_FILE"); path != "" {
+		hosts, err := mapper.LoadHostsFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load hosts file: %w", err)
+		}
+		backends = append(backends, hosts)
+	}
+
+	backends = append(backends,
+		mapper.NewInventoryBackend(inv.LookupIP),
+		mapper.NewDNSBackend(os.Getenv("IP_RESOLVER_DNS_SERVER")),
+	)
+
+	hints, err := mapper.ParseCIDRs(os.Getenv("IP_RESOLVER_CIDR_HINTS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP_RESOLVER_CIDR_HINTS: %w", err)
+	}
+
+	return mapper.NewChainResolver(mapper.ResolverConfig{
+		Backends:    backends,
+		PositiveTTL: time.Duration(config.GetEnvInt("IP_RESOLVER_CACHE_TTL_SECONDS", 300)) * time.Second,
+		NegativeTTL: time.Duration(config.GetEnvInt("IP_RESOLVER_NEGATIVE_TTL_SECONDS", 30)) * time.Second,
+		MaxEntries:  config.GetEnvInt("IP_RESOLVER_CACHE_SIZE", 10000),
+		PreferCIDRs: hints,
+		PreferIPv6:  os.Getenv("IP_RESOLVER_PREFER_IPV6") == "true",
+	}), nil
+}
+
+// forward enriches a mapped syslog or SNMP event with inventory attributes
+// and sends it to Ingestor Core, logging the outcome.
+func forward(c *client.IngestorClient, e *inventory.Enricher, kind string, n int, event models.Event) {
+	enriched, ok := e.Enrich(event)
+	if !ok {
+		log.Printf("🚫 %s %d dropped: unknown device %s\n", kind, n, event.SourceHost)
+		return
+	}
+	report(kind, n, c.SendEnrichedEvent(enriched))
+}
+
+// send forwards a metadata event to Ingestor Core as it is, logging the
+// outcome. Metadata describes the inventory itself, so it is not enriched.
+func send(c *client.IngestorClient, kind string, n int, event models.Event) {
+	report(kind, n, c.SendEvent(event))
+}
+
+// report logs the outcome of sending an event.
+func report(kind string, n int, err error) {
+	if err != nil {
+		log.Printf("❌ %s %d send failed: %v\n", kind, n, err)
+	} else {
+		fmt.Printf("✅ %s %d sent successfully\n", kind, n)
+	}
+}
@@ -0,0 +1,228 @@
+package mapper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrHostNotFound is returned by a Backend that has no mapping for a host.
+var ErrHostNotFound = errors.New("host not found")
+
+// Backend is a single source of hostname-to-address mappings in an
+// IPResolver chain.
+type Backend interface {
+	Name() string
+	Lookup(ctx context.Context, host string) ([]net.IP, error)
+}
+
+// ReverseBackend is implemented by backends that can also map an address
+// back to hostnames.
+type ReverseBackend interface {
+	LookupAddr(ctx context.Context, ip string) ([]string, error)
+}
+
+// ---------------- Static hosts ----------------
+
+// StaticHosts resolves hostnames from a fixed table, typically loaded from an
+// /etc/hosts style file.
+type StaticHosts struct {
+	hosts map[string][]net.IP
+	names map[string][]string // canonical IP → hostnames, in file order
+}
+
+// NewStaticHosts creates a backend from a hostname → IP map.
+// Entries whose value is not a valid IP are ignored.
+func NewStaticHosts(entries map[string]string) *StaticHosts {
+	h := &StaticHosts{
+		hosts: make(map[string][]net.IP, len(entries)),
+		names: make(map[string][]string, len(entries)),
+	}
+	for host, addr := range entries {
+		if ip := net.ParseIP(addr); ip != nil {
+			h.add(host, ip)
+		}
+	}
+	return h
+}
+
+// LoadHostsFile parses a hosts file ("<ip> <name> [aliases...]" per line,
+// "#" comments) into a StaticHosts backend.
+func LoadHostsFile(path string) (*StaticHosts, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := &StaticHosts{
+		hosts: make(map[string][]net.IP),
+		names: make(map[string][]string),
+	}
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i != -1 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: expected address and at least one hostname", path, lineNo)
+		}
+
+		ip := net.ParseIP(fields[0])
+		if ip == nil {
+			return nil, fmt.Errorf("%s:%d: invalid address %q", path, lineNo, fields[0])
+		}
+
+		for _, name := range fields[1:] {
+			h.add(name, ip)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *StaticHosts) add(host string, ip net.IP) {
+	key := strings.ToLower(host)
+	h.hosts[key] = append(h.hosts[key], ip)
+	h.names[ip.String()] = append(h.names[ip.String()], host)
+}
+
+// Name implements Backend.
+func (h *StaticHosts) Name() string { return "static" }
+
+// Lookup implements Backend.
+func (h *StaticHosts) Lookup(_ context.Context, host string) ([]net.IP, error) {
+	if ips, ok := h.hosts[strings.ToLower(host)]; ok {
+		return ips, nil
+	}
+	return nil, ErrHostNotFound
+}
+
+// LookupAddr implements ReverseBackend.
+func (h *StaticHosts) LookupAddr(_ context.Context, ip string) ([]string, error) {
+	if parsed := net.ParseIP(ip); parsed != nil {
+		if names, ok := h.names[parsed.String()]; ok {
+			return names, nil
+		}
+	}
+	return nil, ErrHostNotFound
+}
+
+// ---------------- Inventory ----------------
+
+// InventoryBackend resolves hostnames from the device inventory's management
+// IPs. The lookup function is usually (*inventory.Inventory).LookupIP. The
+// resolver caches its answers like any other, so purge the cache when the
+// inventory changes (see IPResolver.Purge).
+type InventoryBackend struct {
+	lookup func(host string) (string, bool)
+}
+
+// NewInventoryBackend creates a backend around an inventory lookup function.
+func NewInventoryBackend(lookup func(host string) (string, bool)) *InventoryBackend {
+	return &InventoryBackend{lookup: lookup}
+}
+
+// Name implements Backend.
+func (b *InventoryBackend) Name() string { return "inventory" }
+
+// Lookup implements Backend.
+func (b *InventoryBackend) Lookup(_ context.Context, host string) ([]net.IP, error) {
+	addr, ok := b.lookup(host)
+	if !ok {
+		return nil, ErrHostNotFound
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil, ErrHostNotFound
+	}
+	return []net.IP{ip}, nil
+}
+
+// ---------------- DNS ----------------
+
+// DNSBackend resolves hostnames via DNS, either through the system resolver
+// or a specific DNS server.
+type DNSBackend struct {
+	resolver *net.Resolver
+}
+
+// NewDNSBackend creates a DNS backend. If server is empty the system resolver
+// is used; otherwise queries go to server ("host" or "host:port", port 53 by
+// default).
+func NewDNSBackend(server string) *DNSBackend {
+	if server == "" {
+		return &DNSBackend{resolver: net.DefaultResolver}
+	}
+
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	dialer := &net.Dialer{Timeout: 2 * time.Second}
+	return &DNSBackend{
+		resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, server)
+			},
+		},
+	}
+}
+
+// Name implements Backend.
+func (d *DNSBackend) Name() string { return "dns" }
+
+// Lookup implements Backend.
+func (d *DNSBackend) Lookup(ctx context.Context, host string) ([]net.IP, error) {
+	addrs, err := d.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := make([]net.IP, 0, len(addrs))
+	for _, a := range addrs {
+		ips = append(ips, a.IP)
+	}
+	return ips, nil
+}
+
+// LookupAddr implements ReverseBackend using PTR queries.
+func (d *DNSBackend) LookupAddr(ctx context.Context, ip string) ([]string, error) {
+	return d.resolver.LookupAddr(ctx, ip)
+}
+
+// ParseCIDRs parses a comma-separated list of CIDR ranges, e.g. the value of
+// IP_RESOLVER_CIDR_HINTS.
+func ParseCIDRs(list string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
@@ -0,0 +1,282 @@
+package mapper
+
+import (
+	"encoding/json"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/ibm-live-project-interns/ingestor/shared/constants"
+	"github.com/ibm-live-project-interns/ingestor/shared/models"
+)
+
+// The fuzz targets run their seed corpus as part of go test. To fuzz one:
+//
+//	go test ./mapper -run '^$' -fuzz FuzzMapSyslog -fuzztime 30s
+
+// severities is the set every mapped Event's Severity must be in.
+var severities = []string{
+	constants.SeverityCritical,
+	constants.SeverityHigh,
+	constants.SeverityMedium,
+	constants.SeverityLow,
+	constants.SeverityInfo,
+}
+
+// corpusRecords returns each record of the JSON arrays in data/*.json, the
+// files the simulators write.
+func corpusRecords(t testing.TB) [][]byte {
+	paths, err := filepath.Glob(filepath.Join("..", "data", "*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no corpus files in ../data (%v)", err)
+	}
+
+	var records [][]byte
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			t.Fatalf("parse %s: %v", path, err)
+		}
+		for _, item := range items {
+			records = append(records, []byte(item))
+		}
+	}
+	return records
+}
+
+// corpusStrings returns the distinct string values of the records in
+// data/*.json, such as hostnames, IPs and raw messages.
+func corpusStrings(t testing.TB) []string {
+	seen := map[string]bool{}
+	var values []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			if !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		case map[string]any:
+			for _, e := range v {
+				walk(e)
+			}
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	for _, rec := range corpusRecords(t) {
+		var v any
+		json.Unmarshal(rec, &v)
+		walk(v)
+	}
+	slices.Sort(values)
+	return values
+}
+
+// corpusResolver returns a resolver for the hostnames of
+// data/devices-metadata.json that does not touch the network.
+func corpusResolver(t testing.TB) *IPResolver {
+	data, err := os.ReadFile(filepath.Join("..", "data", "devices-metadata.json"))
+	if err != nil {
+		t.Fatalf("read device metadata: %v", err)
+	}
+	var devices []struct {
+		Hostname string `json:"hostname"`
+		IP       string `json:"ip"`
+	}
+	if err := json.Unmarshal(data, &devices); err != nil {
+		t.Fatalf("parse device metadata: %v", err)
+	}
+	hosts := make(map[string]string, len(devices))
+	for _, d := range devices {
+		hosts[d.Hostname] = d.IP
+	}
+	return NewChainResolver(ResolverConfig{
+		Backends:    []Backend{NewStaticHosts(hosts)},
+		PositiveTTL: time.Minute,
+		NegativeTTL: time.Minute,
+		MaxEntries:  1000,
+	})
+}
+
+// corpusMapper returns a Mapper resolving hosts with corpusResolver.
+func corpusMapper(t testing.TB) *Mapper { return NewMapper(corpusResolver(t)) }
+
+// addPayloads seeds f with the corpus records and some malformed payloads.
+func addPayloads(f *testing.F) {
+	for _, rec := range corpusRecords(f) {
+		f.Add(rec)
+	}
+	for _, raw := range []string{
+		``, `{}`, `null`, `[]`, `"x"`, `{invalid json}`,
+		`{"host": 1, "source": true, "entity": []}`,
+		`{"host": "router-1", "severity": "ERROR", "timestamp": "not a time"}`,
+		`{"host": "[::1]:514", "source": "10.0.0.1:162", "entity": "fe80::1%eth0"}`,
+		"{\"host\": \"r\xff1\", \"message\": \"\xc3\x28\"}",
+	} {
+		f.Add([]byte(raw))
+	}
+}
+
+// checkEvent reports a mapped Event that breaks the mapper invariants: a
+// known event type, a severity from the allowed set, a valid SourceIP, and
+// the payload kept byte for byte.
+func checkEvent(t *testing.T, raw []byte, event models.Event, eventType string) {
+	t.Helper()
+	if event.EventType != eventType {
+		t.Errorf("expected event_type=%s, got %q", eventType, event.EventType)
+	}
+	if !slices.Contains(severities, event.Severity) {
+		t.Errorf("severity %q not in %v", event.Severity, severities)
+	}
+	if _, err := netip.ParseAddr(event.SourceIP); err != nil {
+		t.Errorf("source_ip %q is not an IP: %v", event.SourceIP, err)
+	}
+	if event.RawPayload != string(raw) {
+		t.Errorf("raw payload not preserved:\n got %q\nwant %q", event.RawPayload, raw)
+	}
+}
+
+func FuzzMapSyslog(f *testing.F) {
+	addPayloads(f)
+	mp := corpusMapper(f)
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		event, err := mp.MapSyslog(raw)
+		if err != nil {
+			return
+		}
+		checkEvent(t, raw, event, constants.EventTypeSyslog)
+	})
+}
+
+func FuzzMapSNMP(f *testing.F) {
+	addPayloads(f)
+	mp := corpusMapper(f)
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		event, err := mp.MapSNMP(raw)
+		if err != nil {
+			return
+		}
+		checkEvent(t, raw, event, constants.EventTypeSNMP)
+	})
+}
+
+func FuzzMapMetadata(f *testing.F) {
+	addPayloads(f)
+	mp := corpusMapper(f)
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		event, err := mp.MapMetadata(raw)
+		if err != nil {
+			return
+		}
+		checkEvent(t, raw, event, constants.EventTypeMetadata)
+		if event.Severity != constants.SeverityInfo {
+			t.Errorf("expected severity=info for metadata, got %s", event.Severity)
+		}
+	})
+}
+
+func FuzzIPResolver_ResolveIP(f *testing.F) {
+	for _, s := range corpusStrings(f) {
+		f.Add(s)
+	}
+	for _, s := range []string{
+		"", " ", ":", "[]", "[::1]", "[::1]:514", "[::1]:99999", "::ffff:10.0.0.1",
+		"fe80::1%eth0", "[fe80::1%eth0]:514", "10.0.0.1:", "host:port", "a:b:c",
+	} {
+		f.Add(s)
+	}
+	r := corpusResolver(f)
+
+	f.Fuzz(func(t *testing.T, hostOrIP string) {
+		ip := r.ResolveIP(hostOrIP)
+		if _, err := netip.ParseAddr(ip); err != nil {
+			t.Fatalf("ResolveIP(%q) = %q, not an IP: %v", hostOrIP, ip, err)
+		}
+		if again := r.ResolveIP(hostOrIP); again != ip {
+			t.Errorf("ResolveIP(%q) not stable: %q then %q", hostOrIP, ip, again)
+		}
+		if _, addr := parseHost(hostOrIP); addr.IsValid() && ip != addr.String() {
+			t.Errorf("ResolveIP(%q) = %q, want the literal %s", hostOrIP, ip, addr)
+		}
+	})
+}
+
+// TestMapSyslog_Properties checks the invariants on well-formed payloads
+// with arbitrary field values.
+func TestMapSyslog_Properties(t *testing.T) {
+	mp := corpusMapper(t)
+	property := func(in SyslogInput) bool {
+		raw, _ := json.Marshal(in)
+		event, err := mp.MapSyslog(raw)
+		if err != nil {
+			t.Logf("MapSyslog(%s): %v", raw, err)
+			return false
+		}
+		checkEvent(t, raw, event, constants.EventTypeSyslog)
+		return event.SourceHost == in.Host && event.Message == in.Message
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMapSNMP_Properties(t *testing.T) {
+	mp := corpusMapper(t)
+	property := func(in SNMPInput) bool {
+		raw, _ := json.Marshal(in)
+		event, err := mp.MapSNMP(raw)
+		if err != nil {
+			t.Logf("MapSNMP(%s): %v", raw, err)
+			return false
+		}
+		checkEvent(t, raw, event, constants.EventTypeSNMP)
+		return event.SourceHost == in.Source && event.Message == in.OID+" = "+in.Value
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMapMetadata_Properties(t *testing.T) {
+	mp := corpusMapper(t)
+	property := func(entity, timestamp string, data map[string]string) bool {
+		raw, _ := json.Marshal(MetadataInput{Entity: entity, Data: data, Timestamp: timestamp})
+		event, err := mp.MapMetadata(raw)
+		if err != nil {
+			t.Logf("MapMetadata(%s): %v", raw, err)
+			return false
+		}
+		checkEvent(t, raw, event, constants.EventTypeMetadata)
+		return event.SourceHost == entity && event.Severity == constants.SeverityInfo
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestNormalizeSeverity_AllowedSet checks that every severity spelling,
+// known or not, maps into the allowed set.
+func TestNormalizeSeverity_AllowedSet(t *testing.T) {
+	known := []string{"ERROR", "CRITICAL", "ALERT", "EMERGENCY", "WARN", "WARNING", "NOTICE", "DEBUG", "INFO", "INFORMATIONAL", "", "error", "fatal"}
+	for _, s := range known {
+		if got := normalizeSeverity(s); !slices.Contains(severities, got) {
+			t.Errorf("normalizeSeverity(%q) = %q, not in %v", s, got, severities)
+		}
+	}
+	property := func(s string) bool { return slices.Contains(severities, normalizeSeverity(s)) }
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
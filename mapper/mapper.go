@@ -0,0 +1,37 @@
+package mapper
+
+// Mapper converts raw JSON payloads into shared Events, resolving source
+// hosts through an injected Resolver.
+//
+// The package-level MapSyslog, MapSNMP and MapMetadata functions use a Mapper
+// backed by the default resolver (see SetDefaultResolver).
+type Mapper struct {
+	resolver  Resolver
+	reverse   ReverseResolver
+	trustPeer bool
+}
+
+// Option configures a Mapper.
+type Option func(*Mapper)
+
+// WithPeerTrust makes the socket peer address authoritative: events mapped
+// with MapSyslogFrom/MapSNMPFrom take their SourceIP from the peer rather
+// than from a forward lookup of the claimed host.
+func WithPeerTrust() Option {
+	return func(m *Mapper) { m.trustPeer = true }
+}
+
+// WithReverseDNS enables reverse lookups of peer addresses, used to fill in
+// SourceHost when the payload carries none and to verify claimed hosts.
+func WithReverseDNS(r ReverseResolver) Option {
+	return func(m *Mapper) { m.reverse = r }
+}
+
+// NewMapper creates a Mapper that resolves source hosts with r.
+func NewMapper(r Resolver, opts ...Option) *Mapper {
+	m := &Mapper{resolver: r}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
@@ -14,7 +14,13 @@ type MetadataInput struct {
 	Timestamp string `json:"timestamp"`
 }
 
+// MapMetadata maps a raw metadata JSON payload using the default resolver.
 func MapMetadata(rawJSON []byte) (models.Event, error) {
+	return defaultMapper.Load().MapMetadata(rawJSON)
+}
+
+// MapMetadata maps a raw metadata JSON payload into a shared Event.
+func (mp *Mapper) MapMetadata(rawJSON []byte) (models.Event, error) {
 	var m MetadataInput
 	if err := json.Unmarshal(rawJSON, &m); err != nil {
 		return models.Event{}, err
@@ -24,7 +30,7 @@ func MapMetadata(rawJSON []byte) (models.Event, error) {
 
 	// Resolve the entity to an IP address using the resolver
 	// For metadata events, this will typically resolve to 0.0.0.0 if entity is not a hostname
-	sourceIP := ResolveHostIP(m.Entity)
+	sourceIP := mp.resolver.ResolveIP(m.Entity)
 
 	return models.Event{
 		EventType:      constants.EventTypeMetadata,
@@ -0,0 +1,167 @@
+package mapper
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	"github.com/ibm-live-project-interns/ingestor/shared/models"
+)
+
+// ReverseResolver maps an IP address back to a hostname.
+// Implementations return "" when no name is known.
+type ReverseResolver interface {
+	ReverseLookup(ip string) string
+}
+
+// SourceCheck records how an event's claimed source compares with the
+// address the payload actually arrived from.
+type SourceCheck struct {
+	ClaimedHost string `json:"claimed_host"`           // Host named in the payload
+	ClaimedIP   string `json:"claimed_ip"`             // Forward resolution of the claimed host
+	PeerIP      string `json:"peer_ip"`                // Socket remote address
+	ReverseHost string `json:"reverse_host,omitempty"` // Reverse DNS name of the peer
+	Verified    bool   `json:"verified"`               // Claimed host and peer were shown to agree
+	Mismatch    bool   `json:"mismatch"`               // Claimed host and peer were shown to disagree
+}
+
+// CheckedEvent is a mapped event together with the check of its claimed
+// source against the peer address. It serializes as the Event's JSON with an
+// extra "source_check" key, so the ingestor sees the check result.
+type CheckedEvent struct {
+	Event  models.Event
+	Source SourceCheck
+}
+
+// BaseEvent returns the wrapped Event, for client.IngestorClient.
+func (e CheckedEvent) BaseEvent() models.Event { return e.Event }
+
+// MarshalJSON flattens the wrapped Event and adds the "source_check" object.
+func (e CheckedEvent) MarshalJSON() ([]byte, error) {
+	base, err := json.Marshal(e.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(base, &fields); err != nil {
+		return nil, err
+	}
+
+	check, err := json.Marshal(e.Source)
+	if err != nil {
+		return nil, err
+	}
+	fields["source_check"] = check
+
+	return json.Marshal(fields)
+}
+
+// MapSyslogFrom maps a syslog payload received from peer and checks the
+// claimed host against the peer address.
+func (mp *Mapper) MapSyslogFrom(rawJSON []byte, peer net.Addr) (models.Event, SourceCheck, error) {
+	event, err := mp.MapSyslog(rawJSON)
+	if err != nil {
+		return models.Event{}, SourceCheck{}, err
+	}
+	check := mp.applyPeer(&event, peer)
+	return event, check, nil
+}
+
+// MapSNMPFrom maps an SNMP payload received from peer and checks the claimed
+// source against the peer address.
+func (mp *Mapper) MapSNMPFrom(rawJSON []byte, peer net.Addr) (models.Event, SourceCheck, error) {
+	event, err := mp.MapSNMP(rawJSON)
+	if err != nil {
+		return models.Event{}, SourceCheck{}, err
+	}
+	check := mp.applyPeer(&event, peer)
+	return event, check, nil
+}
+
+// applyPeer compares the event's claimed source with the peer address and,
+// when peer trust is enabled, rewrites SourceIP (and an empty or literal
+// SourceHost) from the peer.
+func (mp *Mapper) applyPeer(event *models.Event, peer net.Addr) SourceCheck {
+	check := SourceCheck{ClaimedHost: event.SourceHost, ClaimedIP: event.SourceIP}
+
+	peerIP := peerAddrIP(peer)
+	if peerIP == "" {
+		return check
+	}
+	check.PeerIP = peerIP
+
+	if mp.reverse != nil {
+		check.ReverseHost = mp.reverse.ReverseLookup(peerIP)
+	}
+
+	check.Verified, check.Mismatch = compareSource(check)
+
+	if mp.trustPeer {
+		event.SourceIP = peerIP
+
+		if _, addr := parseHost(event.SourceHost); event.SourceHost == "" || addr.IsValid() {
+			if check.ReverseHost != "" {
+				event.SourceHost = check.ReverseHost
+			} else if event.SourceHost == "" {
+				event.SourceHost = peerIP
+			}
+		}
+	}
+
+	return check
+}
+
+// compareSource decides whether the claimed host and the peer agree. An
+// address match or a reverse name match verifies the source; a resolved
+// claim that differs from the peer, without a matching reverse name, is a
+// mismatch. Claims that cannot be checked are neither.
+func compareSource(c SourceCheck) (verified, mismatch bool) {
+	if c.ClaimedHost == "" {
+		return false, false
+	}
+
+	if c.ClaimedIP == c.PeerIP {
+		return true, false
+	}
+	if c.ReverseHost != "" && sameHost(c.ClaimedHost, c.ReverseHost) {
+		return true, false
+	}
+
+	claimResolved := c.ClaimedIP != "" && c.ClaimedIP != "0.0.0.0"
+	return false, claimResolved || c.ReverseHost != ""
+}
+
+// sameHost compares hostnames case-insensitively, treating a short name as
+// equal to a fully qualified name that starts with it.
+func sameHost(a, b string) bool {
+	a = strings.TrimSuffix(strings.ToLower(a), ".")
+	b = strings.TrimSuffix(strings.ToLower(b), ".")
+	if a == b {
+		return true
+	}
+	return strings.HasPrefix(b, a+".") || strings.HasPrefix(a, b+".")
+}
+
+// peerAddrIP extracts the canonical IP string from a socket address.
+func peerAddrIP(peer net.Addr) string {
+	switch a := peer.(type) {
+	case nil:
+		return ""
+	case *net.UDPAddr:
+		if a == nil || a.IP == nil {
+			return ""
+		}
+		return (&net.IPAddr{IP: a.IP, Zone: a.Zone}).String()
+	case *net.TCPAddr:
+		if a == nil || a.IP == nil {
+			return ""
+		}
+		return (&net.IPAddr{IP: a.IP, Zone: a.Zone}).String()
+	}
+
+	if _, addr := parseHost(peer.String()); addr.IsValid() {
+		return addr.String()
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package mapper
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func newPeerTestMapper(opts ...Option) *Mapper {
+	hosts := NewStaticHosts(map[string]string{
+		"router-1":             "10.0.0.1",
+		"switch-1.dc1.example": "10.0.0.2",
+	})
+	r := NewChainResolver(ResolverConfig{Backends: []Backend{hosts}, PositiveTTL: time.Minute, NegativeTTL: time.Minute})
+	return NewMapper(r, append([]Option{WithReverseDNS(r)}, opts...)...)
+}
+
+func udpPeer(ip string) net.Addr {
+	return &net.UDPAddr{IP: net.ParseIP(ip), Port: 40000}
+}
+
+func TestMapSyslogFrom_Verified(t *testing.T) {
+	m := newPeerTestMapper()
+
+	event, check, err := m.MapSyslogFrom([]byte(`{"host": "router-1", "severity": "INFO", "message": "ok"}`), udpPeer("10.0.0.1"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !check.Verified || check.Mismatch {
+		t.Errorf("expected verified source, got %+v", check)
+	}
+	if event.SourceIP != "10.0.0.1" || event.SourceHost != "router-1" {
+		t.Errorf("unexpected source: %s / %s", event.SourceHost, event.SourceIP)
+	}
+}
+
+func TestMapSyslogFrom_Mismatch(t *testing.T) {
+	m := newPeerTestMapper(WithPeerTrust())
+
+	event, check, err := m.MapSyslogFrom([]byte(`{"host": "router-1", "severity": "INFO", "message": "spoofed"}`), udpPeer("10.0.0.2"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !check.Mismatch || check.Verified {
+		t.Errorf("expected mismatch, got %+v", check)
+	}
+	if check.ReverseHost != "switch-1.dc1.example" {
+		t.Errorf("expected reverse name for peer, got %q", check.ReverseHost)
+	}
+	if event.SourceIP != "10.0.0.2" {
+		t.Errorf("expected peer address to be authoritative, got %s", event.SourceIP)
+	}
+	if event.SourceHost != "router-1" {
+		t.Errorf("expected claimed host to be kept, got %s", event.SourceHost)
+	}
+}
+
+func TestMapSNMPFrom_ReverseFillsHost(t *testing.T) {
+	m := newPeerTestMapper(WithPeerTrust())
+
+	tests := []struct {
+		name     string
+		source   string
+		peer     string
+		wantHost string
+	}{
+		{"missing source", "", "10.0.0.2", "switch-1.dc1.example"},
+		{"literal source", "10.0.0.2", "10.0.0.2", "switch-1.dc1.example"},
+		{"no reverse name", "", "192.0.2.7", "192.0.2.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := []byte(`{"source": "` + tt.source + `", "oid": "1.3.6.1", "value": "1", "severity": "CRITICAL"}`)
+			event, _, err := m.MapSNMPFrom(raw, udpPeer(tt.peer))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if event.SourceHost != tt.wantHost {
+				t.Errorf("SourceHost = %q, want %q", event.SourceHost, tt.wantHost)
+			}
+			if event.SourceIP != tt.peer {
+				t.Errorf("SourceIP = %q, want %q", event.SourceIP, tt.peer)
+			}
+		})
+	}
+}
+
+func TestMapSyslogFrom_ShortNameMatchesReverse(t *testing.T) {
+	m := newPeerTestMapper()
+
+	_, check, err := m.MapSyslogFrom([]byte(`{"host": "SWITCH-1", "severity": "INFO", "message": "ok"}`), udpPeer("10.0.0.2"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !check.Verified {
+		t.Errorf("expected short name to match reverse FQDN, got %+v", check)
+	}
+}
+
+func TestMapSyslogFrom_UnverifiableIsNotMismatch(t *testing.T) {
+	m := newPeerTestMapper()
+
+	_, check, err := m.MapSyslogFrom([]byte(`{"host": "unknown-host", "severity": "INFO", "message": "?"}`), udpPeer("192.0.2.7"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if check.Verified || check.Mismatch {
+		t.Errorf("expected unverifiable source, got %+v", check)
+	}
+}
+
+func TestReverseLookup_Cached(t *testing.T) {
+	b := &countingBackend{name: "fake"}
+	static := NewStaticHosts(map[string]string{"router-1": "10.0.0.1"})
+	r := NewChainResolver(ResolverConfig{Backends: []Backend{b, static}, PositiveTTL: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		if name := r.ReverseLookup("10.0.0.1"); name != "router-1" {
+			t.Fatalf("expected router-1, got %q", name)
+		}
+	}
+	if r.CacheLen() != 1 {
+		t.Errorf("expected a single cached reverse entry, got %d", r.CacheLen())
+	}
+}
+
+func TestCheckedEvent_MarshalJSON(t *testing.T) {
+	m := newPeerTestMapper()
+
+	event, check, err := m.MapSyslogFrom([]byte(`{"host": "router-1", "severity": "INFO", "message": "spoofed"}`), udpPeer("10.0.0.2"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := json.Marshal(CheckedEvent{Event: event, Source: check})
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var out struct {
+		SourceHost  string      `json:"source_host"`
+		SourceCheck SourceCheck `json:"source_check"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if out.SourceHost != "router-1" {
+		t.Errorf("expected event fields to be kept, got %s", data)
+	}
+	if !out.SourceCheck.Mismatch || out.SourceCheck.PeerIP != "10.0.0.2" {
+		t.Errorf("expected source_check with the mismatch, got %s", data)
+	}
+}
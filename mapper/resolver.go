@@ -1,100 +1,349 @@
 package mapper
 
 import (
+	"container/list"
+	"context"
 	"net"
+	"net/netip"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ibm-live-project-interns/ingestor/shared/config"
 )
 
-// IPResolver handles hostname to IP resolution with caching
+// Resolver resolves a hostname (optionally with a port) to an IP address.
+// Implementations return "0.0.0.0" when the host cannot be resolved.
+type Resolver interface {
+	ResolveIP(hostOrIP string) string
+}
+
+// IPResolver handles hostname to IP resolution with caching.
+// Hostnames are looked up through a chain of backends (e.g. static hosts,
+// device inventory, DNS); the first backend that returns addresses wins.
+// Successful and failed lookups are cached with separate TTLs in a bounded
+// LRU cache.
 type IPResolver struct {
-	cache    map[string]cacheEntry
-	mu       sync.RWMutex
-	cacheTTL time.Duration
+	backends    []Backend
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+	maxEntries  int
+	preferCIDRs []*net.IPNet
+	preferIPv6  bool
+	timeout     time.Duration
+
+	mu    sync.Mutex
+	cache map[string]*list.Element
+	lru   *list.List // front = most recently used
+	now   func() time.Time
 }
 
 type cacheEntry struct {
+	host      string
 	ip        string
 	expiresAt time.Time
 }
 
-// Default TTL from environment or 5 minutes
+// ResolverConfig configures an IPResolver.
+type ResolverConfig struct {
+	Backends    []Backend     // Consulted in order; defaults to system DNS
+	PositiveTTL time.Duration // How long successful lookups are cached
+	NegativeTTL time.Duration // How long failed lookups are cached
+	MaxEntries  int           // Cache size bound; 0 means unbounded
+	PreferCIDRs []*net.IPNet  // When a host has several addresses, prefer ones in these ranges
+	PreferIPv6  bool          // Otherwise prefer IPv6 over IPv4 addresses
+	Timeout     time.Duration // Per-backend lookup timeout
+}
+
+// Default TTLs and cache size from environment
 var defaultCacheTTL = time.Duration(config.GetEnvInt("IP_RESOLVER_CACHE_TTL_SECONDS", 300)) * time.Second
-var defaultResolver = NewIPResolver(defaultCacheTTL)
+var defaultNegativeTTL = time.Duration(config.GetEnvInt("IP_RESOLVER_NEGATIVE_TTL_SECONDS", 30)) * time.Second
+var defaultCacheSize = config.GetEnvInt("IP_RESOLVER_CACHE_SIZE", 10000)
 
-// NewIPResolver creates a new IP resolver with cache TTL
+// defaultMapper backs the package-level mapping functions and
+// ResolveHostIP. SetDefaultResolver swaps it atomically, so listener
+// goroutines can keep mapping while it is replaced.
+var defaultMapper atomic.Pointer[Mapper]
+
+func init() {
+	defaultMapper.Store(NewMapper(NewChainResolver(ResolverConfig{
+		PositiveTTL: defaultCacheTTL,
+		NegativeTTL: defaultNegativeTTL,
+		MaxEntries:  defaultCacheSize,
+	})))
+}
+
+// NewIPResolver creates a DNS-backed resolver that caches both successful
+// and failed lookups for ttl.
 func NewIPResolver(ttl time.Duration) *IPResolver {
+	return NewChainResolver(ResolverConfig{
+		PositiveTTL: ttl,
+		NegativeTTL: ttl,
+	})
+}
+
+// NewChainResolver creates a resolver from the given configuration.
+func NewChainResolver(cfg ResolverConfig) *IPResolver {
+	backends := cfg.Backends
+	if len(backends) == 0 {
+		backends = []Backend{NewDNSBackend("")}
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
 	return &IPResolver{
-		cache:    make(map[string]cacheEntry),
-		cacheTTL: ttl,
+		backends:    backends,
+		positiveTTL: cfg.PositiveTTL,
+		negativeTTL: cfg.NegativeTTL,
+		maxEntries:  cfg.MaxEntries,
+		preferCIDRs: cfg.PreferCIDRs,
+		preferIPv6:  cfg.PreferIPv6,
+		timeout:     timeout,
+		cache:       make(map[string]*list.Element),
+		lru:         list.New(),
+		now:         time.Now,
 	}
 }
 
 // ResolveIP resolves a hostname to an IP address with caching
-// If the input is already an IP, returns it directly
+// If the input is already an IP, returns it directly (see parseHost for the
+// accepted host/port forms)
 // Falls back to "0.0.0.0" if resolution fails
 func (r *IPResolver) ResolveIP(hostOrIP string) string {
 	if hostOrIP == "" {
 		return "0.0.0.0"
 	}
 
-	// Clean up the input (might have port attached)
-	host := hostOrIP
-	if colonIdx := strings.LastIndex(hostOrIP, ":"); colonIdx != -1 {
-		// Check if it's IPv6 or host:port
-		if !strings.Contains(hostOrIP, "[") {
-			host = hostOrIP[:colonIdx]
+	// Strip any port and brackets; IP literals are returned directly
+	host, addr := parseHost(hostOrIP)
+	if addr.IsValid() {
+		return addr.String()
+	}
+	if host == "" {
+		return "0.0.0.0"
+	}
+
+	// Check cache first
+	if ip, ok := r.cached(host); ok {
+		return ip
+	}
+
+	// Walk the backend chain
+	for _, b := range r.backends {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		ips, err := b.Lookup(ctx, host)
+		cancel()
+
+		if err == nil && len(ips) > 0 {
+			resultIP := r.pick(ips).String()
+			r.cacheResult(host, resultIP, r.positiveTTL)
+			return resultIP
 		}
 	}
 
-	// If it's already a valid IP, return it
-	if ip := net.ParseIP(host); ip != nil {
-		return ip.String()
+	// Cache the failure too to avoid repeated lookups
+	r.cacheResult(host, "0.0.0.0", r.negativeTTL)
+	return "0.0.0.0"
+}
+
+// ReverseLookup returns the hostname for ip using the first backend that
+// supports reverse lookups and knows the address, or "" if none does.
+// Results are cached alongside forward lookups with the same TTLs.
+func (r *IPResolver) ReverseLookup(ip string) string {
+	_, addr := parseHost(ip)
+	if !addr.IsValid() {
+		return ""
 	}
 
-	// Check cache first
-	r.mu.RLock()
-	entry, exists := r.cache[host]
-	r.mu.RUnlock()
+	key := "ptr:" + addr.String()
+	if name, ok := r.cached(key); ok {
+		return name
+	}
+
+	for _, b := range r.backends {
+		rb, ok := b.(ReverseBackend)
+		if !ok {
+			continue
+		}
 
-	if exists && time.Now().Before(entry.expiresAt) {
-		return entry.ip
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		names, err := rb.LookupAddr(ctx, addr.String())
+		cancel()
+
+		if err == nil && len(names) > 0 {
+			name := strings.TrimSuffix(names[0], ".")
+			r.cacheResult(key, name, r.positiveTTL)
+			return name
+		}
 	}
 
-	// Resolve the hostname
-	ips, err := net.LookupIP(host)
-	if err != nil || len(ips) == 0 {
-		// Cache the failure too to avoid repeated lookups
-		r.cacheResult(host, "0.0.0.0")
-		return "0.0.0.0"
+	r.cacheResult(key, "", r.negativeTTL)
+	return ""
+}
+
+// pick chooses the address to report when a host has several. Addresses in
+// the configured CIDR hints win (in hint order), then the preferred address
+// family (IPv4 unless PreferIPv6 is set), then the first.
+func (r *IPResolver) pick(ips []net.IP) net.IP {
+	for _, cidr := range r.preferCIDRs {
+		for _, ip := range ips {
+			if cidr.Contains(ip) {
+				return ip
+			}
+		}
 	}
 
-	// Prefer IPv4 addresses
-	resultIP := ips[0].String()
+	// Prefer IPv4 addresses unless configured otherwise
 	for _, ip := range ips {
-		if ip.To4() != nil {
-			resultIP = ip.String()
-			break
+		if (ip.To4() == nil) == r.preferIPv6 {
+			return ip
+		}
+	}
+	return ips[0]
+}
+
+// parseHost extracts the host part of a resolver input. It accepts bare IPv4
+// and IPv6 addresses (including zone identifiers such as "fe80::1%eth0"),
+// bracketed IPv6 with or without a port ("[2001:db8::1]:514"), and
+// "hostname:port" or "ipv4:port". If the host is an IP literal, addr is
+// valid and holds its canonical form; IPv4-mapped IPv6 addresses are
+// reported as IPv4. An empty host means the input was malformed.
+func parseHost(s string) (host string, addr netip.Addr) {
+	s = strings.TrimSpace(s)
+
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end == -1 {
+			return "", netip.Addr{}
 		}
+		rest := s[end+1:]
+		if rest != "" && !(strings.HasPrefix(rest, ":") && isPort(rest[1:])) {
+			return "", netip.Addr{}
+		}
+		s = s[1:end]
+		if a, err := netip.ParseAddr(s); err == nil && a.Is6() {
+			return s, a.Unmap()
+		}
+		return "", netip.Addr{}
+	}
+
+	if a, err := netip.ParseAddr(s); err == nil {
+		return s, a.Unmap()
+	}
+
+	// Exactly one colon: host:port or ipv4:port. More colons without brackets
+	// can only be an (invalid) IPv6 literal.
+	switch strings.Count(s, ":") {
+	case 0:
+		host = s
+	case 1:
+		i := strings.IndexByte(s, ':')
+		if !isPort(s[i+1:]) {
+			return "", netip.Addr{}
+		}
+		host = s[:i]
+		if a, err := netip.ParseAddr(host); err == nil {
+			return host, a
+		}
+	default:
+		return "", netip.Addr{}
+	}
+
+	// Zones and brackets are only valid on IPv6 literals
+	if strings.ContainsAny(host, "%[] \t") {
+		return "", netip.Addr{}
 	}
+	return host, netip.Addr{}
+}
 
-	r.cacheResult(host, resultIP)
-	return resultIP
+// isPort reports whether s is a decimal port number in 0-65535.
+func isPort(s string) bool {
+	if s == "" || len(s) > 5 {
+		return false
+	}
+	n := 0
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n <= 65535
 }
 
-func (r *IPResolver) cacheResult(host, ip string) {
+func (r *IPResolver) cached(host string) (string, bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.cache[host] = cacheEntry{
-		ip:        ip,
-		expiresAt: time.Now().Add(r.cacheTTL),
+
+	elem, ok := r.cache[host]
+	if !ok {
+		return "", false
 	}
+
+	entry := elem.Value.(*cacheEntry)
+	if !r.now().Before(entry.expiresAt) {
+		r.lru.Remove(elem)
+		delete(r.cache, host)
+		return "", false
+	}
+
+	r.lru.MoveToFront(elem)
+	return entry.ip, true
+}
+
+func (r *IPResolver) cacheResult(host, ip string, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expiresAt := r.now().Add(ttl)
+	if elem, ok := r.cache[host]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.ip, entry.expiresAt = ip, expiresAt
+		r.lru.MoveToFront(elem)
+		return
+	}
+
+	r.cache[host] = r.lru.PushFront(&cacheEntry{host: host, ip: ip, expiresAt: expiresAt})
+
+	for r.maxEntries > 0 && r.lru.Len() > r.maxEntries {
+		oldest := r.lru.Back()
+		r.lru.Remove(oldest)
+		delete(r.cache, oldest.Value.(*cacheEntry).host)
+	}
+}
+
+// Purge drops all cached lookups, so the next lookup of every host walks
+// the backend chain again. Call it when a backend's answers change, e.g.
+// from Inventory.OnChange for an InventoryBackend.
+func (r *IPResolver) Purge() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.cache)
+	r.lru.Init()
+}
+
+// CacheLen returns the number of cached lookups.
+func (r *IPResolver) CacheLen() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lru.Len()
+}
+
+// SetDefaultResolver replaces the resolver used by the package-level mapping
+// functions and ResolveHostIP. It is safe to call while events are mapped.
+func SetDefaultResolver(r Resolver) {
+	defaultMapper.Store(NewMapper(r))
 }
 
 // ResolveHostIP is a convenience function using the default resolver
 func ResolveHostIP(hostOrIP string) string {
-	return defaultResolver.ResolveIP(hostOrIP)
+	return defaultMapper.Load().resolver.ResolveIP(hostOrIP)
 }
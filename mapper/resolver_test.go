@@ -0,0 +1,324 @@
+package mapper
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingBackend is a fake Backend that records how often it was queried.
+type countingBackend struct {
+	name  string
+	hosts map[string]string
+	calls int
+}
+
+func (b *countingBackend) Name() string { return b.name }
+
+func (b *countingBackend) Lookup(_ context.Context, host string) ([]net.IP, error) {
+	b.calls++
+	if addr, ok := b.hosts[host]; ok {
+		return []net.IP{net.ParseIP(addr)}, nil
+	}
+	return nil, ErrHostNotFound
+}
+
+func TestChainResolver_FirstBackendWins(t *testing.T) {
+	first := &countingBackend{name: "first", hosts: map[string]string{"router-1": "10.0.0.1"}}
+	second := &countingBackend{name: "second", hosts: map[string]string{"router-1": "10.9.9.9", "switch-1": "10.0.0.2"}}
+
+	r := NewChainResolver(ResolverConfig{Backends: []Backend{first, second}, PositiveTTL: time.Minute})
+
+	if ip := r.ResolveIP("router-1"); ip != "10.0.0.1" {
+		t.Errorf("expected first backend result, got %s", ip)
+	}
+	if ip := r.ResolveIP("switch-1"); ip != "10.0.0.2" {
+		t.Errorf("expected fallthrough to second backend, got %s", ip)
+	}
+	if second.calls != 1 {
+		t.Errorf("expected second backend queried once, got %d", second.calls)
+	}
+}
+
+func TestChainResolver_SeparateNegativeTTL(t *testing.T) {
+	b := &countingBackend{name: "fake", hosts: map[string]string{"router-1": "10.0.0.1"}}
+	r := NewChainResolver(ResolverConfig{
+		Backends:    []Backend{b},
+		PositiveTTL: time.Hour,
+		NegativeTTL: time.Second,
+	})
+
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	r.ResolveIP("router-1")
+	if ip := r.ResolveIP("ghost"); ip != "0.0.0.0" {
+		t.Fatalf("expected 0.0.0.0 for unknown host, got %s", ip)
+	}
+	if b.calls != 2 {
+		t.Fatalf("expected 2 lookups, got %d", b.calls)
+	}
+
+	now = now.Add(2 * time.Second)
+	b.hosts["ghost"] = "10.0.0.66"
+
+	if ip := r.ResolveIP("ghost"); ip != "10.0.0.66" {
+		t.Errorf("expected negative entry to expire, got %s", ip)
+	}
+	r.ResolveIP("router-1")
+	if b.calls != 3 {
+		t.Errorf("expected positive entry still cached, got %d lookups", b.calls)
+	}
+}
+
+func TestChainResolver_LRUEviction(t *testing.T) {
+	b := &countingBackend{name: "fake", hosts: map[string]string{"a": "10.0.0.1", "b": "10.0.0.2", "c": "10.0.0.3"}}
+	r := NewChainResolver(ResolverConfig{Backends: []Backend{b}, PositiveTTL: time.Hour, MaxEntries: 2})
+
+	r.ResolveIP("a")
+	r.ResolveIP("b")
+	r.ResolveIP("a") // a becomes most recently used
+	r.ResolveIP("c") // evicts b
+
+	if r.CacheLen() != 2 {
+		t.Fatalf("expected cache bounded to 2 entries, got %d", r.CacheLen())
+	}
+
+	calls := b.calls
+	r.ResolveIP("a")
+	if b.calls != calls {
+		t.Errorf("expected a to remain cached")
+	}
+	r.ResolveIP("b")
+	if b.calls != calls+1 {
+		t.Errorf("expected b to have been evicted")
+	}
+}
+
+func TestChainResolver_PurgeSeesInventoryEdits(t *testing.T) {
+	addrs := map[string]string{"router-1": "10.0.0.1"}
+	inv := NewInventoryBackend(func(host string) (string, bool) {
+		addr, ok := addrs[host]
+		return addr, ok
+	})
+	r := NewChainResolver(ResolverConfig{Backends: []Backend{inv}, PositiveTTL: time.Hour, NegativeTTL: time.Hour})
+
+	r.ResolveIP("router-1")
+	r.ResolveIP("router-2")
+	addrs["router-1"], addrs["router-2"] = "10.0.0.9", "10.0.0.2"
+
+	r.Purge()
+	if r.CacheLen() != 0 {
+		t.Fatalf("expected an empty cache, got %d entries", r.CacheLen())
+	}
+	if ip := r.ResolveIP("router-1"); ip != "10.0.0.9" {
+		t.Errorf("expected the edited address, got %s", ip)
+	}
+	if ip := r.ResolveIP("router-2"); ip != "10.0.0.2" {
+		t.Errorf("expected the added device, got %s", ip)
+	}
+}
+
+func TestSetDefaultResolver_WhileMapping(t *testing.T) {
+	defer SetDefaultResolver(defaultMapper.Load().resolver)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			MapSyslog([]byte(`{"host": "10.0.0.1"}`))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		SetDefaultResolver(NewChainResolver(ResolverConfig{Backends: []Backend{NewStaticHosts(nil)}}))
+	}
+	<-done
+
+	if ip := ResolveHostIP("10.0.0.1"); ip != "10.0.0.1" {
+		t.Errorf("expected the literal, got %s", ip)
+	}
+}
+
+func TestChainResolver_CIDRHints(t *testing.T) {
+	multi := &multiBackend{ips: []net.IP{net.ParseIP("192.168.1.5"), net.ParseIP("10.20.0.5")}}
+	hints, err := ParseCIDRs("10.0.0.0/8, 172.16.0.0/12")
+	if err != nil {
+		t.Fatalf("parse cidrs: %v", err)
+	}
+
+	r := NewChainResolver(ResolverConfig{Backends: []Backend{multi}, PreferCIDRs: hints})
+	if ip := r.ResolveIP("dual-homed"); ip != "10.20.0.5" {
+		t.Errorf("expected management address from hint range, got %s", ip)
+	}
+
+	r = NewChainResolver(ResolverConfig{Backends: []Backend{multi}})
+	if ip := r.ResolveIP("dual-homed"); ip != "192.168.1.5" {
+		t.Errorf("expected first IPv4 without hints, got %s", ip)
+	}
+}
+
+type multiBackend struct{ ips []net.IP }
+
+func (m *multiBackend) Name() string { return "multi" }
+
+func (m *multiBackend) Lookup(context.Context, string) ([]net.IP, error) { return m.ips, nil }
+
+func TestLoadHostsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	content := "# lab devices\n10.0.0.1 router-1 core-rtr\n\n10.0.0.2\tswitch-1 # access\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write hosts: %v", err)
+	}
+
+	hosts, err := LoadHostsFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	r := NewChainResolver(ResolverConfig{Backends: []Backend{hosts}})
+	for host, want := range map[string]string{"router-1": "10.0.0.1", "CORE-RTR": "10.0.0.1", "switch-1": "10.0.0.2"} {
+		if got := r.ResolveIP(host); got != want {
+			t.Errorf("ResolveIP(%q) = %s, want %s", host, got, want)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("not-an-ip router-1\n"), 0o644); err != nil {
+		t.Fatalf("write hosts: %v", err)
+	}
+	if _, err := LoadHostsFile(path); err == nil {
+		t.Errorf("expected error for invalid address")
+	}
+}
+
+func TestMapper_UsesInjectedResolver(t *testing.T) {
+	inv := NewInventoryBackend(func(host string) (string, bool) {
+		return "10.1.1.1", host == "router-2"
+	})
+	m := NewMapper(NewChainResolver(ResolverConfig{Backends: []Backend{inv}}))
+
+	event, err := m.MapSNMP([]byte(`{"source": "router-2", "oid": "1.3.6.1", "value": "1", "severity": "CRITICAL"}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if event.SourceIP != "10.1.1.1" {
+		t.Errorf("expected inventory address, got %s", event.SourceIP)
+	}
+}
+
+func TestParseHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantHost string
+		wantAddr string // "" when the host is not an IP literal
+	}{
+		{"ipv4", "10.0.0.1", "10.0.0.1", "10.0.0.1"},
+		{"ipv4 with port", "10.0.0.1:514", "10.0.0.1", "10.0.0.1"},
+		{"ipv4 surrounding space", "  10.0.0.1 ", "10.0.0.1", "10.0.0.1"},
+		{"ipv6 loopback", "::1", "::1", "::1"},
+		{"ipv6 link-local", "fe80::1", "fe80::1", "fe80::1"},
+		{"ipv6 full form", "2001:0db8:0000:0000:0000:0000:0000:0001", "2001:0db8:0000:0000:0000:0000:0000:0001", "2001:db8::1"},
+		{"ipv6 trailing group looks like port", "2001:db8::1:80", "2001:db8::1:80", "2001:db8::1:80"},
+		{"ipv6 zone", "fe80::1%eth0", "fe80::1%eth0", "fe80::1%eth0"},
+		{"ipv6 numeric zone", "fe80::1%2", "fe80::1%2", "fe80::1%2"},
+		{"bracketed ipv6", "[2001:db8::1]", "2001:db8::1", "2001:db8::1"},
+		{"bracketed ipv6 with port", "[2001:db8::1]:514", "2001:db8::1", "2001:db8::1"},
+		{"bracketed zone with port", "[fe80::1%eth0]:514", "fe80::1%eth0", "fe80::1%eth0"},
+		{"ipv4-mapped ipv6", "::ffff:10.0.0.1", "::ffff:10.0.0.1", "10.0.0.1"},
+		{"bracketed ipv4-mapped", "[::ffff:10.0.0.1]:162", "::ffff:10.0.0.1", "10.0.0.1"},
+		{"hostname", "router-1", "router-1", ""},
+		{"fqdn", "router-1.dc1.example.net", "router-1.dc1.example.net", ""},
+		{"hostname with port", "router-1:514", "router-1", ""},
+		{"hostname max port", "router-1:65535", "router-1", ""},
+		{"hostname port out of range", "router-1:65536", "", ""},
+		{"hostname non-numeric port", "router-1:syslog", "", ""},
+		{"empty port", "router-1:", "", ""},
+		{"missing host", ":514", "", ""},
+		{"unterminated bracket", "[2001:db8::1", "", ""},
+		{"bracketed ipv4", "[10.0.0.1]:514", "", ""},
+		{"bracketed hostname", "[router-1]:514", "", ""},
+		{"garbage after bracket", "[2001:db8::1]x", "", ""},
+		{"invalid ipv6", "2001:db8:::1", "", ""},
+		{"zone on hostname", "router-1%eth0", "", ""},
+		{"zone only", "%eth0", "", ""},
+		{"embedded space", "router 1", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, addr := parseHost(tt.input)
+			if host != tt.wantHost {
+				t.Errorf("parseHost(%q) host = %q, want %q", tt.input, host, tt.wantHost)
+			}
+
+			gotAddr := ""
+			if addr.IsValid() {
+				gotAddr = addr.String()
+			}
+			if gotAddr != tt.wantAddr {
+				t.Errorf("parseHost(%q) addr = %q, want %q", tt.input, gotAddr, tt.wantAddr)
+			}
+		})
+	}
+}
+
+func TestResolveIP_Forms(t *testing.T) {
+	b := &countingBackend{name: "fake", hosts: map[string]string{"router-1": "10.0.0.1"}}
+	r := NewChainResolver(ResolverConfig{Backends: []Backend{b}, PositiveTTL: time.Minute, NegativeTTL: time.Minute})
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "0.0.0.0"},
+		{"10.0.0.1:514", "10.0.0.1"},
+		{"fe80::1", "fe80::1"},
+		{"fe80::1%eth0", "fe80::1%eth0"},
+		{"[2001:db8::1]:514", "2001:db8::1"},
+		{"::ffff:192.0.2.1", "192.0.2.1"},
+		{"router-1", "10.0.0.1"},
+		{"router-1:514", "10.0.0.1"},
+		{"unknown-host", "0.0.0.0"},
+		{"[2001:db8::1", "0.0.0.0"},
+		{"router-1:notaport", "0.0.0.0"},
+	}
+
+	for _, tt := range tests {
+		if got := r.ResolveIP(tt.input); got != tt.want {
+			t.Errorf("ResolveIP(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	// IP literals never reach the backends
+	if b.calls != 2 {
+		t.Errorf("expected 2 backend lookups (router-1, unknown-host), got %d", b.calls)
+	}
+}
+
+func TestResolveIP_PreferIPv6(t *testing.T) {
+	dual := &multiBackend{ips: []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("2001:db8::1")}}
+
+	tests := []struct {
+		preferIPv6 bool
+		want       string
+	}{
+		{false, "10.0.0.1"},
+		{true, "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		r := NewChainResolver(ResolverConfig{Backends: []Backend{dual}, PreferIPv6: tt.preferIPv6})
+		if got := r.ResolveIP("dual-stack"); got != tt.want {
+			t.Errorf("PreferIPv6=%v: got %s, want %s", tt.preferIPv6, got, tt.want)
+		}
+	}
+
+	v4only := &multiBackend{ips: []net.IP{net.ParseIP("10.0.0.1")}}
+	r := NewChainResolver(ResolverConfig{Backends: []Backend{v4only}, PreferIPv6: true})
+	if got := r.ResolveIP("v4-only"); got != "10.0.0.1" {
+		t.Errorf("expected fallback to IPv4 when no IPv6 address, got %s", got)
+	}
+}
@@ -16,7 +16,13 @@ type SNMPInput struct {
 	Timestamp string `json:"timestamp"`
 }
 
+// MapSNMP maps a raw SNMP JSON payload using the default resolver.
 func MapSNMP(rawJSON []byte) (models.Event, error) {
+	return defaultMapper.Load().MapSNMP(rawJSON)
+}
+
+// MapSNMP maps a raw SNMP JSON payload into a shared Event.
+func (mp *Mapper) MapSNMP(rawJSON []byte) (models.Event, error) {
 	var s SNMPInput
 	if err := json.Unmarshal(rawJSON, &s); err != nil {
 		return models.Event{}, err
@@ -28,7 +34,7 @@ func MapSNMP(rawJSON []byte) (models.Event, error) {
 	severity := normalizeSeverity(s.Severity)
 
 	// Resolve the source to an IP address using the resolver
-	sourceIP := ResolveHostIP(s.Source)
+	sourceIP := mp.resolver.ResolveIP(s.Source)
 
 	return models.Event{
 		EventType:      constants.EventTypeSNMP,
@@ -15,7 +15,13 @@ type SyslogInput struct {
 	Timestamp string `json:"timestamp"`
 }
 
+// MapSyslog maps a raw syslog JSON payload using the default resolver.
 func MapSyslog(rawJSON []byte) (models.Event, error) {
+	return defaultMapper.Load().MapSyslog(rawJSON)
+}
+
+// MapSyslog maps a raw syslog JSON payload into a shared Event.
+func (mp *Mapper) MapSyslog(rawJSON []byte) (models.Event, error) {
 	var s SyslogInput
 	if err := json.Unmarshal(rawJSON, &s); err != nil {
 		return models.Event{}, err
@@ -27,7 +33,7 @@ func MapSyslog(rawJSON []byte) (models.Event, error) {
 	severity := normalizeSeverity(s.Severity)
 
 	// Resolve the hostname to an IP address using the resolver
-	sourceIP := ResolveHostIP(s.Host)
+	sourceIP := mp.resolver.ResolveIP(s.Host)
 
 	return models.Event{
 		EventType:      constants.EventTypeSyslog,
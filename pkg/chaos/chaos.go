@@ -0,0 +1,245 @@
+// Package chaos injects malformed and adversarial input into simulated
+// telemetry, to harden the ingest path against what real devices and
+// networks deliver.
+//
+// An Injector decides, at a configured rate, whether the next message gets a
+// fault and which one. The simulators apply it: timestamp faults before a
+// message is rendered, byte faults to the rendered message. Each injected
+// fault is recorded with the persisted message, labelled with its Fault name,
+// so a test can check how the consumer handled it.
+//
+//	-chaos 0.05                            5% of messages get a random fault
+//	-chaos 0.2 -faults truncated,invalid_utf8
+//	                                       20% get one of the two
+package chaos
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault is a kind of injected defect.
+type Fault string
+
+const (
+	// Truncated cuts the message off at a random point.
+	Truncated Fault = "truncated"
+	// InvalidPRI replaces the syslog PRI with an out-of-range or malformed
+	// one. Syslog only.
+	InvalidPRI Fault = "invalid_pri"
+	// Oversized pads the message beyond the usual receive buffers (8 KiB),
+	// up to nearly the largest UDP datagram.
+	Oversized Fault = "oversized"
+	// InvalidUTF8 inserts byte sequences that are not valid UTF-8.
+	InvalidUTF8 Fault = "invalid_utf8"
+	// DuplicateTimestamp gives the message the previous message's timestamp.
+	DuplicateTimestamp Fault = "duplicate_timestamp"
+	// OutOfOrder dates the message before the previous message.
+	OutOfOrder Fault = "out_of_order"
+	// ClockSkew shifts the timestamp by one minute (or MaxSkew, if shorter)
+	// up to MaxSkew, as a device with a wrong clock would.
+	ClockSkew Fault = "clock_skew"
+)
+
+// Faults lists every fault, in the order they are documented.
+var Faults = []Fault{Truncated, InvalidPRI, Oversized, InvalidUTF8, DuplicateTimestamp, OutOfOrder, ClockSkew}
+
+// Size bounds of an Oversized message: above common syslog and trap receive
+// buffers, and with room for framing within the largest UDP payload (65507
+// bytes over IPv4).
+const (
+	minOversized = 8*1024 + 1
+	maxOversized = 65000
+)
+
+// defaultMaxSkew bounds ClockSkew when Config.MaxSkew is unset.
+const defaultMaxSkew = 24 * time.Hour
+
+// Config selects how often and which faults are injected.
+type Config struct {
+	Rate    float64       // Fraction of messages with a fault, 0 to 1; 0 disables chaos
+	Faults  []Fault       // Faults to draw from; empty means all a simulator supports
+	MaxSkew time.Duration // Largest ClockSkew shift, either way; defaults to 24h
+}
+
+// RegisterFlags defines -chaos and -faults on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.Func("chaos", "Fraction of messages (0-1) to inject a fault into, labelled in the persisted record", func(s string) error {
+		rate, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		c.Rate = rate
+		return validateRate(rate)
+	})
+	fs.Func("faults", "Comma-separated faults for -chaos (default all): "+joinFaults(Faults), func(s string) error {
+		faults, err := ParseFaults(s)
+		c.Faults = faults
+		return err
+	})
+}
+
+// ParseFaults parses a comma-separated list of fault names.
+func ParseFaults(s string) ([]Fault, error) {
+	var faults []Fault
+	for _, name := range strings.Split(s, ",") {
+		f := Fault(strings.TrimSpace(name))
+		if !slices.Contains(Faults, f) {
+			return nil, fmt.Errorf("unknown fault %q (want %s)", f, joinFaults(Faults))
+		}
+		faults = append(faults, f)
+	}
+	return faults, nil
+}
+
+// Enabled reports whether c injects any faults. A negative rate counts as
+// enabled, so New rejects it rather than chaos being silently off.
+func (c Config) Enabled() bool { return c.Rate != 0 }
+
+// validateRate checks that rate is a fraction.
+func validateRate(rate float64) error {
+	if rate < 0 || rate > 1 {
+		return fmt.Errorf("rate must be between 0 and 1, got %g", rate)
+	}
+	return nil
+}
+
+// Injector draws faults for one stream of messages. It is not safe for
+// concurrent use.
+type Injector struct {
+	rate    float64
+	faults  []Fault
+	maxSkew time.Duration
+	rng     *rand.Rand
+}
+
+// New returns an Injector for a simulator that supports the given faults,
+// drawing from rng. It returns an error if cfg is invalid or selects a fault
+// the simulator does not support.
+func New(cfg Config, supported []Fault, rng *rand.Rand) (*Injector, error) {
+	var errs []error
+	if err := validateRate(cfg.Rate); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.MaxSkew < 0 {
+		errs = append(errs, errors.New("max skew must not be negative"))
+	}
+	faults := cfg.Faults
+	if len(faults) == 0 {
+		faults = supported
+	}
+	for _, f := range faults {
+		if !slices.Contains(supported, f) {
+			errs = append(errs, fmt.Errorf("fault %q is not supported here (supported: %s)", f, joinFaults(supported)))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid chaos configuration: %w", err)
+	}
+
+	maxSkew := cfg.MaxSkew
+	if maxSkew == 0 {
+		maxSkew = defaultMaxSkew
+	}
+	return &Injector{rate: cfg.Rate, faults: faults, maxSkew: maxSkew, rng: rng}, nil
+}
+
+// Next returns the fault to inject into the next message, or "" for none.
+// A nil Injector never injects faults.
+func (in *Injector) Next() Fault {
+	if in == nil || in.rng.Float64() >= in.rate {
+		return ""
+	}
+	return in.faults[in.rng.Intn(len(in.faults))]
+}
+
+// Timestamp applies a timestamp fault to t, given the previous message's
+// timestamp (zero if there is none). Other faults leave t unchanged.
+func (in *Injector) Timestamp(f Fault, t, prev time.Time) time.Time {
+	switch f {
+	case DuplicateTimestamp:
+		if !prev.IsZero() {
+			return prev
+		}
+	case OutOfOrder:
+		if prev.IsZero() {
+			prev = t
+		}
+		// One second to five minutes before the previous message.
+		return prev.Add(-time.Second - time.Duration(in.rng.Int63n(int64(5*time.Minute))))
+	case ClockSkew:
+		least := min(time.Minute, in.maxSkew)
+		skew := least + time.Duration(in.rng.Int63n(int64(in.maxSkew-least)+1))
+		if in.rng.Intn(2) == 0 {
+			skew = -skew
+		}
+		return t.Add(skew)
+	}
+	return t
+}
+
+// Truncate returns a random prefix of b, at least one byte shorter.
+func (in *Injector) Truncate(b []byte) []byte {
+	if len(b) <= 1 {
+		return b[:0]
+	}
+	return b[:in.rng.Intn(len(b)-1)+1]
+}
+
+// Oversize pads b with filler text to a size between 8 KiB and 65000
+// bytes, keeping the last keep bytes (e.g. a closing quote and brace)
+// at the end.
+func (in *Injector) Oversize(b []byte, keep int) []byte {
+	keep = min(keep, len(b))
+	size := minOversized + in.rng.Intn(maxOversized-minOversized+1)
+	if size <= len(b) {
+		return b
+	}
+
+	const filler = " lorem ipsum dolor sit amet"
+	out := make([]byte, 0, size)
+	out = append(out, b[:len(b)-keep]...)
+	for len(out) < size-keep {
+		out = append(out, filler[:min(len(filler), size-keep-len(out))]...)
+	}
+	return append(out, b[len(b)-keep:]...)
+}
+
+// invalidUTF8 are byte sequences that are not valid UTF-8: stray
+// continuation and lead bytes, a truncated sequence, an overlong encoding and
+// an encoded surrogate.
+var invalidUTF8 = [][]byte{
+	{0xff}, {0xfe, 0xfe}, {0x80}, {0xc3, 0x28}, {0xe2, 0x82}, {0xc0, 0xaf}, {0xed, 0xa0, 0x80},
+}
+
+// CorruptUTF8 inserts an invalid UTF-8 sequence into b at a random offset
+// from start, before the last keep bytes.
+func (in *Injector) CorruptUTF8(b []byte, start, keep int) []byte {
+	end := max(len(b)-keep, 0)
+	start = min(max(start, 0), end)
+	at := start + in.rng.Intn(end-start+1)
+
+	seq := invalidUTF8[in.rng.Intn(len(invalidUTF8))]
+	out := make([]byte, 0, len(b)+len(seq))
+	out = append(out, b[:at]...)
+	out = append(out, seq...)
+	return append(out, b[at:]...)
+}
+
+// Intn draws from the injector's random source, for simulator-specific
+// faults.
+func (in *Injector) Intn(n int) int { return in.rng.Intn(n) }
+
+func joinFaults(faults []Fault) string {
+	names := make([]string, len(faults))
+	for i, f := range faults {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
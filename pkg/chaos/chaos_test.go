@@ -0,0 +1,136 @@
+package chaos
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestNew_Validates(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"rate above 1", Config{Rate: 1.5}},
+		{"negative rate", Config{Rate: -0.1}},
+		{"negative skew", Config{Rate: 0.1, MaxSkew: -time.Second}},
+		{"unsupported fault", Config{Rate: 0.1, Faults: []Fault{InvalidPRI}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg, []Fault{Truncated, Oversized}, rand.New(rand.NewSource(1))); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestParseFaults(t *testing.T) {
+	faults, err := ParseFaults("truncated, clock_skew")
+	if err != nil || len(faults) != 2 || faults[0] != Truncated || faults[1] != ClockSkew {
+		t.Errorf("unexpected faults %v (%v)", faults, err)
+	}
+	if _, err := ParseFaults("truncated,bitflip"); err == nil {
+		t.Error("expected error for unknown fault")
+	}
+}
+
+func TestInjector_Next(t *testing.T) {
+	var none *Injector
+	if f := none.Next(); f != "" {
+		t.Errorf("expected a nil injector to inject nothing, got %q", f)
+	}
+
+	in, err := New(Config{Rate: 0.25, Faults: []Fault{Truncated, OutOfOrder}}, Faults, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	injected := 0
+	for i := 0; i < 4000; i++ {
+		switch f := in.Next(); f {
+		case "":
+		case Truncated, OutOfOrder:
+			injected++
+		default:
+			t.Fatalf("unexpected fault %q", f)
+		}
+	}
+	if injected < 800 || injected > 1200 {
+		t.Errorf("expected about 1000 faults at rate 0.25, got %d", injected)
+	}
+}
+
+func TestInjector_Timestamp(t *testing.T) {
+	in, _ := New(Config{Rate: 1, MaxSkew: time.Hour}, Faults, rand.New(rand.NewSource(1)))
+	prev := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := prev.Add(time.Second)
+
+	if got := in.Timestamp(DuplicateTimestamp, now, prev); !got.Equal(prev) {
+		t.Errorf("duplicate: expected %v, got %v", prev, got)
+	}
+	for i := 0; i < 100; i++ {
+		if got := in.Timestamp(OutOfOrder, now, prev); !got.Before(prev) {
+			t.Fatalf("out of order: expected before %v, got %v", prev, got)
+		}
+		got := in.Timestamp(ClockSkew, now, prev)
+		if d := got.Sub(now).Abs(); d < time.Minute || d > time.Hour {
+			t.Fatalf("clock skew: unexpected shift %v", d)
+		}
+	}
+	if got := in.Timestamp(Truncated, now, prev); !got.Equal(now) {
+		t.Errorf("expected byte faults to keep the timestamp, got %v", got)
+	}
+
+	short, _ := New(Config{Rate: 1, MaxSkew: 30 * time.Second}, Faults, rand.New(rand.NewSource(1)))
+	if d := short.Timestamp(ClockSkew, now, prev).Sub(now).Abs(); d != 30*time.Second {
+		t.Errorf("clock skew: expected a shift of MaxSkew below a minute, got %v", d)
+	}
+}
+
+func TestConfig_RegisterFlags(t *testing.T) {
+	for _, rate := range []string{"-0.1", "1.5", "x"} {
+		var cfg Config
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		cfg.RegisterFlags(fs)
+		if err := fs.Parse([]string{"-chaos", rate}); err == nil {
+			t.Errorf("-chaos %s: expected error", rate)
+		}
+	}
+
+	// Set in code, a negative rate reaches New instead of disabling chaos.
+	if cfg := (Config{Rate: -0.1}); !cfg.Enabled() {
+		t.Error("expected a negative rate to count as enabled")
+	}
+}
+
+func TestInjector_Bytes(t *testing.T) {
+	in, _ := New(Config{Rate: 1}, Faults, rand.New(rand.NewSource(1)))
+	msg := []byte(`{"message":"link down"}`)
+
+	for i := 0; i < 100; i++ {
+		if got := in.Truncate(msg); len(got) == 0 || len(got) >= len(msg) || !bytes.HasPrefix(msg, got) {
+			t.Fatalf("truncate: unexpected %q", got)
+		}
+
+		got := in.Oversize(msg, 2)
+		if len(got) <= minOversized-1 || len(got) > maxOversized {
+			t.Fatalf("oversize: unexpected size %d", len(got))
+		}
+		if !bytes.HasPrefix(got, msg[:len(msg)-2]) || !bytes.HasSuffix(got, []byte(`"}`)) {
+			t.Fatalf("oversize: expected the message around the padding, got %.40q...", got)
+		}
+
+		got = in.CorruptUTF8(msg, 12, 2)
+		if utf8.Valid(got) {
+			t.Fatalf("corrupt: expected invalid UTF-8, got %q", got)
+		}
+		if !bytes.HasPrefix(got, msg[:12]) || !bytes.HasSuffix(got, []byte(`"}`)) {
+			t.Fatalf("corrupt: expected bytes outside the range kept, got %q", got)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Package jsonarray persists records as a file holding one JSON array, in
+// the layout json.MarshalIndent(records, "", "  ") gives the whole array.
+// New records are appended in place, without reading or rewriting the ones
+// already in the file, so persisting N records costs O(N) in total.
+//
+// The trap and syslog simulators buffer records in their Recorders and hand
+// them to Create (for an empty file) or Append (afterwards) in batches.
+package jsonarray
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrCorrupt is returned for files that do not hold a JSON array.
+var ErrCorrupt = errors.New("not a JSON array")
+
+// Len returns the number of elements in the array file at path, or 0 if the
+// file does not exist or is empty.
+func Len(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	case len(data) == 0:
+		return 0, nil
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return len(elems), nil
+}
+
+// Create writes records as a new array file, creating its directory and
+// replacing path atomically.
+func Create[T any](path string, records []T) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, out, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Append adds records to the existing non-empty array file at path,
+// overwriting its closing bracket. A failed append is rolled back.
+func Append[T any](path string, records []T) error {
+	var buf bytes.Buffer
+	for _, rec := range records {
+		out, err := json.MarshalIndent(rec, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		buf.WriteString(",\n  ")
+		buf.Write(out)
+	}
+	buf.WriteString("\n]")
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	end, closing, err := arrayEnd(f)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(buf.Bytes(), end); err != nil {
+		// Restore the array as it was before the append.
+		f.Truncate(end)
+		f.WriteAt(closing, end)
+		return err
+	}
+	return f.Truncate(end + int64(buf.Len()))
+}
+
+// arrayEnd returns the offset just past the last element of the non-empty
+// JSON array in f, and the bytes from there to the end of the file: the
+// closing bracket and any whitespace around it.
+func arrayEnd(f *os.File) (int64, []byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, nil, err
+	}
+	tail := make([]byte, min(info.Size(), 64))
+	start := info.Size() - int64(len(tail))
+	if _, err := f.ReadAt(tail, start); err != nil {
+		return 0, nil, err
+	}
+
+	const space = " \t\r\n"
+	trimmed := bytes.TrimRight(tail, space)
+	if !bytes.HasSuffix(trimmed, []byte("]")) {
+		return 0, nil, fmt.Errorf("%w: the file does not end with a closing bracket", ErrCorrupt)
+	}
+	last := len(bytes.TrimRight(trimmed[:len(trimmed)-1], space))
+	if last == 0 {
+		return 0, nil, fmt.Errorf("%w: no element before the closing bracket", ErrCorrupt)
+	}
+	return start + int64(last), tail[last:], nil
+}
@@ -0,0 +1,51 @@
+package jsonarray
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppend_KeepsIndentedLayout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records", "out.json")
+	if n, err := Len(path); n != 0 || err != nil {
+		t.Fatalf("expected a missing file to be empty, got %d (%v)", n, err)
+	}
+
+	if err := Create(path, []map[string]int{{"n": 1}}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := Append(path, []map[string]int{{"n": 2}, {"n": 3}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := "[\n  {\n    \"n\": 1\n  },\n  {\n    \"n\": 2\n  },\n  {\n    \"n\": 3\n  }\n]"
+	if string(data) != want {
+		t.Errorf("unexpected file:\n%s\nwant:\n%s", data, want)
+	}
+	if n, err := Len(path); n != 3 || err != nil {
+		t.Errorf("expected 3 elements, got %d (%v)", n, err)
+	}
+}
+
+func TestAppend_RejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	if err := os.WriteFile(path, []byte(`[{"n": 1}`), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := Len(path); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt from Len, got %v", err)
+	}
+	if err := Append(path, []int{2}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt from Append, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `[{"n": 1}` {
+		t.Errorf("expected the file untouched, got %q", data)
+	}
+}
@@ -5,18 +5,25 @@
 // The publisher supports periodic update cycles to simulate metadata drift (e.g.
 // OS patches, device relocations).
 //
-// TODO: Replace deprecated rand.Seed with rand.New(rand.NewSource(...)) for
-// Go 1.20+ compatibility. Consider accepting a context.Context for graceful
-// shutdown of update cycles.
+// Generation draws from a random source seeded by Config.Seed and timestamps
+// records with Config.Clock, so the same seed and clock produce the same
+// inventory and updates.
+//
+// Run stops early when its context is cancelled. The metadata file is
+// replaced atomically on every write, so consumers never read a partial file
+// and an interrupted run leaves the last complete inventory behind.
 package metadatasim
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
 )
 
 // Device represents metadata about a network device.
@@ -33,47 +40,71 @@ type Device struct {
 
 // Config controls how metadata is generated and written.
 type Config struct {
-	OutputPath     string        // where to write the shared metadata file
-	DeviceCount    int           // how many devices to generate
-	Updates        int           // how many times to update metadata (0 = no updates)
-	UpdateInterval time.Duration // delay between updates
+	OutputPath     string         // where to write the shared metadata file
+	DeviceCount    int            // how many devices to generate
+	Updates        int            // how many times to update metadata (0 = no updates)
+	UpdateInterval time.Duration  // delay between updates
+	Seed           int64          // random seed; 0 picks one from the clock
+	Clock          simclock.Clock // UpdatedAt timestamps and update intervals; nil means simclock.Real
+}
+
+// Summary counts what a Run wrote.
+type Summary struct {
+	Devices int // Devices in the inventory
+	Updates int // Update cycles written
+}
+
+// String formats the summary for the command's final report.
+func (s Summary) String() string {
+	return fmt.Sprintf("devices=%d updates=%d", s.Devices, s.Updates)
 }
 
 // Run generates sample metadata and writes it to a common file.
-// Optionally performs a few update cycles to simulate changes.
-func Run(cfg Config) error {
-	rand.Seed(time.Now().UnixNano())
+// Optionally performs a few update cycles to simulate changes, until
+// cfg.Updates are written, the clock reaches its end or ctx is cancelled.
+// The returned summary counts what was written, also on error.
+func Run(ctx context.Context, cfg Config) (Summary, error) {
+	var summary Summary
+	rng := rand.New(rand.NewSource(simclock.Seed(cfg.Seed)))
+	clock := cfg.Clock
+	if clock == nil {
+		clock = simclock.Real
+	}
 
 	// Ensure the output directory exists.
 	dir := filepath.Dir(cfg.OutputPath)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("failed to create metadata directory: %w", err)
+		return summary, fmt.Errorf("failed to create metadata directory: %w", err)
 	}
 
 	// Initial metadata generation.
-	devices := generateDevices(cfg.DeviceCount)
+	devices := generateDevices(rng, clock, cfg.DeviceCount)
 	if err := writeDevices(cfg.OutputPath, devices); err != nil {
-		return err
+		return summary, err
 	}
+	summary.Devices = len(devices)
 
 	fmt.Printf("Initial metadata for %d devices written to %s\n", cfg.DeviceCount, cfg.OutputPath)
 
 	// Optional update cycles.
 	for i := 0; i < cfg.Updates; i++ {
-		time.Sleep(cfg.UpdateInterval)
+		if err := clock.Sleep(ctx, cfg.UpdateInterval); err != nil {
+			break
+		}
 
-		updateRandomDevice(devices)
+		updateRandomDevice(rng, clock, devices)
 		if err := writeDevices(cfg.OutputPath, devices); err != nil {
-			return err
+			return summary, err
 		}
+		summary.Updates++
 		fmt.Printf("Metadata update %d written to %s\n", i+1, cfg.OutputPath)
 	}
 
-	return nil
+	return summary, nil
 }
 
 // generateDevices creates a slice of fake devices.
-func generateDevices(count int) []Device {
+func generateDevices(rng *rand.Rand, clock simclock.Clock, count int) []Device {
 	vendors := []string{"Cisco", "Juniper", "Arista", "Huawei"}
 	models := []string{"ISR-4000", "MX480", "7050X3", "NE40E"}
 	oses := []string{"IOS-XE 17.3", "JUNOS 21.1", "EOS 4.28", "VRP 8.200"}
@@ -88,17 +119,17 @@ func generateDevices(count int) []Device {
 	devices := make([]Device, 0, count)
 
 	for i := 0; i < count; i++ {
-		vendorIdx := rand.Intn(len(vendors))
-		now := time.Now().UTC().Format(time.RFC3339)
+		vendorIdx := rng.Intn(len(vendors))
+		now := clock.Now().UTC().Format(time.RFC3339)
 
 		d := Device{
 			ID:        fmt.Sprintf("dev-%03d", i+1),
 			Hostname:  fmt.Sprintf("device-%03d", i+1),
-			IP:        randomIP(),
+			IP:        randomIP(rng),
 			Vendor:    vendors[vendorIdx],
 			Model:     models[vendorIdx],
 			OS:        oses[vendorIdx],
-			Location:  locations[rand.Intn(len(locations))],
+			Location:  locations[rng.Intn(len(locations))],
 			UpdatedAt: now,
 		}
 
@@ -108,40 +139,45 @@ func generateDevices(count int) []Device {
 	return devices
 }
 
-// writeDevices writes the devices slice as pretty JSON to the given path.
+// writeDevices writes the devices slice as pretty JSON to the given path,
+// replacing the file atomically.
 func writeDevices(path string, devices []Device) error {
 	data, err := json.MarshalIndent(devices, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
 		return fmt.Errorf("failed to write metadata file: %w", err)
 	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace metadata file: %w", err)
+	}
 
 	return nil
 }
 
 // updateRandomDevice simulates a metadata change on a random device.
-func updateRandomDevice(devices []Device) {
+func updateRandomDevice(rng *rand.Rand, clock simclock.Clock, devices []Device) {
 	if len(devices) == 0 {
 		return
 	}
 
-	idx := rand.Intn(len(devices))
+	idx := rng.Intn(len(devices))
 	dev := &devices[idx]
 
 	// Randomly change OS or Location to simulate drift.
-	switch rand.Intn(2) {
+	switch rng.Intn(2) {
 	case 0:
 		dev.OS = dev.OS + " (patched)"
 	default:
 		dev.Location = dev.Location + "-Alt"
 	}
 
-	dev.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	dev.UpdatedAt = clock.Now().UTC().Format(time.RFC3339)
 }
 
-func randomIP() string {
-	return fmt.Sprintf("10.%d.%d.%d", rand.Intn(256), rand.Intn(256), rand.Intn(256))
+func randomIP(rng *rand.Rand) string {
+	return fmt.Sprintf("10.%d.%d.%d", rng.Intn(256), rng.Intn(256), rng.Intn(256))
 }
@@ -0,0 +1,58 @@
+package metadatasim
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+)
+
+func TestRun_SameSeedSameOutput(t *testing.T) {
+	dir := t.TempDir()
+	clock := simclock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var outputs [][]byte
+	for _, name := range []string{"a.json", "b.json"} {
+		path := filepath.Join(dir, name)
+		cfg := Config{OutputPath: path, DeviceCount: 20, Updates: 3, Seed: 99, Clock: clock}
+		if _, err := Run(context.Background(), cfg); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read output: %v", err)
+		}
+		outputs = append(outputs, data)
+	}
+
+	if string(outputs[0]) != string(outputs[1]) {
+		t.Errorf("expected identical output for the same seed:\n%s\n%s", outputs[0], outputs[1])
+	}
+}
+
+func TestRun_StopsOnCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	summary, err := Run(ctx, Config{OutputPath: path, DeviceCount: 5, Updates: 100, UpdateInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if summary.Devices != 5 || summary.Updates != 0 {
+		t.Errorf("expected the initial inventory only, got %+v", summary)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var devices []Device
+	if err := json.Unmarshal(data, &devices); err != nil || len(devices) != 5 {
+		t.Errorf("expected 5 devices in the file, got %d (%v)", len(devices), err)
+	}
+}
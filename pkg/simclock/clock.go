@@ -0,0 +1,181 @@
+// Package simclock abstracts the time source of the simulators so their
+// output can be reproduced and their timeline compressed: generators read
+// timestamps from an injected Clock and wait with Clock.Sleep instead of
+// calling time.Now and time.Sleep directly.
+//
+// Three clocks are provided:
+//
+//   - Real, the wall clock.
+//   - Scaled, which runs a multiple of real time (e.g. 60x) from a simulated
+//     start, preserving the relative timing of events.
+//   - Manual, whose time only moves when a caller sleeps or advances it. With
+//     an end time (NewBackfill) it generates historical telemetry as fast as
+//     possible.
+package simclock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits for it to pass.
+type Clock interface {
+	Now() time.Time
+
+	// Sleep pauses for d of clock time. It returns ctx.Err() early if ctx is
+	// done, and ErrEnd instead of sleeping past the clock's end, if it has
+	// one.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// ErrEnd is returned by Sleep when a bounded clock reaches its end. Run
+// loops treat it like cancellation: the simulated period is over.
+var ErrEnd = errors.New("simulated time reached its end")
+
+// Real is the wall clock, in UTC.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now().UTC() }
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, d)
+}
+
+// sleep waits for d of real time or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Scaled is a clock that runs Factor times faster than real time from a
+// simulated start. It is safe for concurrent use.
+type Scaled struct {
+	start  time.Time // Simulated time at origin
+	origin time.Time // Real time the clock was created
+	factor float64
+	end    time.Time // Zero for no end
+}
+
+// NewScaled returns a clock that reads start now and then advances factor
+// times faster than real time. A non-zero end bounds it; see Clock.Sleep.
+func NewScaled(start time.Time, factor float64, end time.Time) *Scaled {
+	return &Scaled{start: start.UTC(), origin: time.Now(), factor: factor, end: end}
+}
+
+// Now implements Clock.
+func (s *Scaled) Now() time.Time {
+	return s.start.Add(time.Duration(float64(time.Since(s.origin)) * s.factor))
+}
+
+// Sleep implements Clock, sleeping d divided by the factor in real time.
+func (s *Scaled) Sleep(ctx context.Context, d time.Duration) error {
+	if !s.end.IsZero() && s.Now().Add(d).After(s.end) {
+		if err := sleep(ctx, time.Duration(float64(s.end.Sub(s.Now()))/s.factor)); err != nil {
+			return err
+		}
+		return ErrEnd
+	}
+	return sleep(ctx, time.Duration(float64(d)/s.factor))
+}
+
+// Manual is a clock that only moves when told to: Sleep advances it at once.
+// It is safe for concurrent use, but callers sharing one Manual clock all
+// advance it, so give each simulated device its own.
+type Manual struct {
+	mu  sync.Mutex
+	now time.Time
+	end time.Time // Zero for no end
+}
+
+// NewManual returns an unbounded Manual clock set to start.
+func NewManual(start time.Time) *Manual {
+	return &Manual{now: start.UTC()}
+}
+
+// NewBackfill returns a Manual clock running from start to end.
+func NewBackfill(start, end time.Time) *Manual {
+	return &Manual{now: start.UTC(), end: end.UTC()}
+}
+
+// Now implements Clock.
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Advance moves the clock forward by d.
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
+
+// Sleep implements Clock by advancing the clock by d without waiting.
+func (m *Manual) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.end.IsZero() && m.now.Add(d).After(m.end) {
+		m.now = m.end
+		return ErrEnd
+	}
+	if d > 0 {
+		m.now = m.now.Add(d)
+	}
+	return nil
+}
+
+type contextKey struct{}
+
+// WithClock returns a context carrying c, for code such as simulated devices
+// whose signatures only take a context.
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the clock carried by ctx, or Real.
+func FromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(contextKey{}).(Clock); ok {
+		return c
+	}
+	return Real
+}
+
+// Seed returns seed, or a seed derived from the wall clock if seed is 0.
+// Simulators print the seed they used so a run can be repeated.
+func Seed(seed int64) int64 {
+	if seed == 0 {
+		return time.Now().UnixNano()
+	}
+	return seed
+}
+
+// At returns a clock that reads t now and otherwise behaves like c: a Scaled
+// clock keeps its factor and end, a Manual clock its end. Other clocks,
+// including Real, are returned unchanged. It starts devices that join a
+// running simulation at the simulation's current time.
+func At(c Clock, t time.Time) Clock {
+	switch c := c.(type) {
+	case *Scaled:
+		return NewScaled(t, c.factor, c.end)
+	case *Manual:
+		return &Manual{now: t.UTC(), end: c.end}
+	}
+	return c
+}
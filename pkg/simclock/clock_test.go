@@ -0,0 +1,97 @@
+package simclock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackfill_StopsAtEnd(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewBackfill(start, start.Add(time.Hour))
+	ctx := context.Background()
+
+	for i := 1; i <= 4; i++ {
+		if err := c.Sleep(ctx, 15*time.Minute); err != nil {
+			t.Fatalf("sleep %d: %v", i, err)
+		}
+	}
+	if !c.Now().Equal(start.Add(time.Hour)) {
+		t.Fatalf("expected clock at the end, got %v", c.Now())
+	}
+	if err := c.Sleep(ctx, time.Second); !errors.Is(err, ErrEnd) {
+		t.Fatalf("expected ErrEnd past the end, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := NewManual(start).Sleep(cancelled, time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+}
+
+func TestScaled_RunsFaster(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewScaled(start, 36000, start.Add(2*time.Hour))
+
+	began := time.Now()
+	if err := c.Sleep(context.Background(), time.Hour); err != nil {
+		t.Fatalf("sleep: %v", err)
+	}
+	if real := time.Since(began); real > 500*time.Millisecond {
+		t.Errorf("expected an hour at 36000x to take 100ms, took %v", real)
+	}
+	if now := c.Now(); now.Before(start.Add(time.Hour)) {
+		t.Errorf("expected at least an hour of simulated time, got %v", now.Sub(start))
+	}
+	if err := c.Sleep(context.Background(), 2*time.Hour); !errors.Is(err, ErrEnd) {
+		t.Errorf("expected ErrEnd, got %v", err)
+	}
+}
+
+func TestAt_KeepsEnd(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := start.Add(45 * time.Minute)
+
+	c := At(NewBackfill(start, start.Add(time.Hour)), later)
+	if !c.Now().Equal(later) {
+		t.Fatalf("expected clock at %v, got %v", later, c.Now())
+	}
+	if err := c.Sleep(context.Background(), 30*time.Minute); !errors.Is(err, ErrEnd) {
+		t.Errorf("expected the original end to apply, got %v", err)
+	}
+
+	if s := At(NewScaled(start, 60, time.Time{}), later); s.Now().Before(later) || s.Now().After(later.Add(time.Minute)) {
+		t.Errorf("expected scaled clock near %v, got %v", later, s.Now())
+	}
+	if At(Real, later) != Real {
+		t.Error("expected the real clock unchanged")
+	}
+}
+
+func TestOptions_NewClock(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	c, err := Options{Backfill: 24 * time.Hour, Speed: 1}.NewClock(now)
+	if err != nil {
+		t.Fatalf("backfill: %v", err)
+	}
+	if !c.Now().Equal(now.Add(-24 * time.Hour)) {
+		t.Errorf("expected backfill to start a day ago, got %v", c.Now())
+	}
+
+	if c, _ := (Options{Speed: 1}).NewClock(now); c != Real {
+		t.Errorf("expected the real clock by default, got %T", c)
+	}
+
+	for _, o := range []Options{
+		{Speed: 0},
+		{Speed: 60, Backfill: time.Hour},
+		{Speed: 1, Duration: -time.Second},
+	} {
+		if _, err := o.NewClock(now); err == nil {
+			t.Errorf("expected an error for %+v", o)
+		}
+	}
+}
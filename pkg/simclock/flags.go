@@ -0,0 +1,91 @@
+package simclock
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// Options selects a simulator's clock from command-line flags:
+//
+//	-backfill 168h        a week of history ending now, as fast as possible
+//	-speed 60             start now and run 60x faster than real time
+//	-speed 60 -duration 24h
+//	                      a day of telemetry in 24 minutes, then stop
+//	-backfill 24h -start 2024-01-01T00:00:00Z
+//	                      a fixed day, for output that -seed can reproduce
+type Options struct {
+	Backfill time.Duration // Simulate this much history, without waiting
+	Speed    float64       // Multiple of real time; 1 is real time
+	Duration time.Duration // Stop after this much simulated time; 0 runs until stopped
+	Start    time.Time     // Simulated start; defaults to now, or now minus Backfill
+}
+
+// RegisterFlags defines -backfill, -speed, -duration and -start on fs.
+func (o *Options) RegisterFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&o.Backfill, "backfill", 0, "Generate this much history ending now, as fast as possible (e.g. 24h)")
+	fs.Float64Var(&o.Speed, "speed", 1, "Run this many times faster than real time (e.g. 60)")
+	fs.DurationVar(&o.Duration, "duration", 0, "Stop after this much simulated time (0 = run until stopped)")
+	fs.Func("start", "Simulated start time, RFC 3339 (default now, or now minus -backfill)", func(s string) error {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("invalid start time: %w", err)
+		}
+		o.Start = t
+		return nil
+	})
+}
+
+// Validate checks the options are consistent.
+func (o Options) Validate() error {
+	switch {
+	case o.Backfill < 0 || o.Duration < 0:
+		return errors.New("-backfill and -duration must not be negative")
+	case o.Speed <= 0:
+		return errors.New("-speed must be positive")
+	case o.Backfill > 0 && o.Speed != 1:
+		return errors.New("-backfill runs as fast as possible; it cannot be combined with -speed")
+	}
+	return nil
+}
+
+// Simulated reports whether the options select anything but the unbounded
+// wall clock.
+func (o Options) Simulated() bool {
+	return o.Backfill > 0 || o.Speed != 1 || o.Duration > 0 || !o.Start.IsZero()
+}
+
+// NewClock returns a new clock for a run that starts (in real time) at now.
+// Backfill clocks cannot be shared between concurrent simulated devices, so
+// call NewClock once per device with the same now.
+func (o Options) NewClock(now time.Time) (Clock, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	if o.Backfill > 0 {
+		start := o.Start
+		if start.IsZero() {
+			start = now.Add(-o.Backfill)
+		}
+		end := start.Add(o.Backfill)
+		if o.Duration > 0 && o.Duration < o.Backfill {
+			end = start.Add(o.Duration)
+		}
+		return NewBackfill(start, end), nil
+	}
+
+	if !o.Simulated() {
+		return Real, nil
+	}
+	start := o.Start
+	if start.IsZero() {
+		start = now
+	}
+	var end time.Time
+	if o.Duration > 0 {
+		end = start.Add(o.Duration)
+	}
+	return NewScaled(start, o.Speed, end), nil
+}
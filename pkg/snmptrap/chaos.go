@@ -0,0 +1,92 @@
+package snmptrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/chaos"
+)
+
+// ChaosFaults are the faults Chaos injects into traps: all but
+// chaos.InvalidPRI, which traps do not have.
+var ChaosFaults = slices.DeleteFunc(slices.Clone(chaos.Faults), func(f chaos.Fault) bool {
+	return f == chaos.InvalidPRI
+})
+
+// TrapRecord is a persisted trap, labelled with the chaos fault injected
+// into it. It encodes as a Trap with two optional fields, so trap files
+// read as []Trap.
+type TrapRecord struct {
+	Trap
+	Fault chaos.Fault `json:"fault,omitempty"` // Injected fault, if any
+	Raw   []byte      `json:"raw,omitempty"`   // Datagram as sent, if the fault altered its encoding
+}
+
+// Chaos injects faults into a stream of traps. It is not safe for concurrent
+// use.
+type Chaos struct {
+	in   *chaos.Injector // Nil when chaos is disabled
+	prev time.Time       // Timestamp of the last trap
+}
+
+// NewChaos returns a Chaos injecting faults as cfg selects, drawing from rng.
+// A disabled cfg gives a Chaos that passes traps through unchanged.
+func NewChaos(cfg chaos.Config, rng *rand.Rand) (*Chaos, error) {
+	c := &Chaos{}
+	if cfg.Enabled() {
+		in, err := chaos.New(cfg, ChaosFaults, rng)
+		if err != nil {
+			return nil, err
+		}
+		c.in = in
+	}
+	return c, nil
+}
+
+// Apply draws a fault for trap and returns the datagram to send with the
+// record to persist. Timestamp faults and Oversized change the trap itself,
+// which the record holds; Truncated and InvalidUTF8 change only the
+// datagram, which the record then holds in Raw.
+func (c *Chaos) Apply(trap Trap) ([]byte, TrapRecord, error) {
+	fault := c.in.Next()
+	trap.Timestamp = c.in.Timestamp(fault, trap.Timestamp, c.prev)
+	c.prev = trap.Timestamp
+	if fault == chaos.Oversized {
+		// Padding the message keeps the datagram valid JSON.
+		trap.Message = string(c.in.Oversize([]byte(trap.Message), 0))
+	}
+
+	data, err := json.Marshal(trap)
+	if err != nil {
+		return nil, TrapRecord{}, err
+	}
+	rec := TrapRecord{Trap: trap, Fault: fault}
+
+	switch fault {
+	case chaos.Truncated:
+		data = c.in.Truncate(data)
+		rec.Raw = data
+	case chaos.InvalidUTF8:
+		// Within the message value, since encoding/json would replace
+		// invalid bytes in the trap itself.
+		start, end := messageValue(data, trap.Message)
+		data = c.in.CorruptUTF8(data, start, len(data)-end)
+		rec.Raw = data
+	}
+	return data, rec, nil
+}
+
+// messageValue returns the offsets of the encoded message value within
+// data, the JSON encoding of a trap, without its quotes.
+func messageValue(data []byte, message string) (start, end int) {
+	value, _ := json.Marshal(message)
+	start = bytes.Index(data, append([]byte(`"message":`), value...))
+	if start < 0 {
+		return 0, len(data)
+	}
+	start += len(`"message":`) + 1
+	return start, start + len(value) - 2
+}
@@ -0,0 +1,86 @@
+package snmptrap
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/chaos"
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+)
+
+func TestChaos_Apply(t *testing.T) {
+	if _, err := NewChaos(chaos.Config{Rate: 1, Faults: []chaos.Fault{chaos.InvalidPRI}}, rand.New(rand.NewSource(1))); err == nil {
+		t.Error("expected error for a syslog-only fault")
+	}
+
+	c, err := NewChaos(chaos.Config{Rate: 1}, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	clock := simclock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	gen := NewGenerator(1, clock)
+
+	seen := map[chaos.Fault]bool{}
+	for i := 0; i < 200; i++ {
+		trap := gen.RandomTrap("router", "r-1")
+		data, rec, err := c.Apply(trap)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		seen[rec.Fault] = true
+
+		var sent Trap
+		decodeErr := json.Unmarshal(data, &sent)
+		switch rec.Fault {
+		case chaos.Truncated:
+			if decodeErr == nil || !reflect.DeepEqual(rec.Raw, data) {
+				t.Fatalf("expected a truncated datagram in raw, got %q", data)
+			}
+		case chaos.InvalidUTF8:
+			if utf8.Valid(data) || !reflect.DeepEqual(rec.Raw, data) {
+				t.Fatalf("expected invalid UTF-8 in raw, got %q", data)
+			}
+			if decodeErr != nil || sent.OID != trap.OID {
+				t.Fatalf("expected the rest of the trap intact, got %q (%v)", data, decodeErr)
+			}
+		case chaos.Oversized:
+			if len(data) <= 8*1024 || decodeErr != nil || rec.Raw != nil {
+				t.Fatalf("expected a valid oversized trap, got %d bytes (%v)", len(data), decodeErr)
+			}
+		default:
+			if decodeErr != nil || rec.Raw != nil || !sent.Timestamp.Equal(rec.Timestamp) {
+				t.Fatalf("expected the record to match the datagram, got %+v for %q", rec, data)
+			}
+		}
+		clock.Advance(time.Second)
+	}
+	if seen[chaos.InvalidPRI] || len(seen) != len(ChaosFaults) {
+		t.Errorf("expected every trap fault, got %v", seen)
+	}
+}
+
+func TestSaveRecordToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traps.json")
+	trap := NewTrap(RouterTraps[0], "r-1", nil)
+	if err := SaveTrapToFile(path, trap); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := SaveRecordToFile(path, TrapRecord{Trap: trap, Fault: chaos.Truncated, Raw: []byte(`{"ver`)}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var records []TrapRecord
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &records); err != nil || len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d (%v)", len(records), err)
+	}
+	if records[0].Fault != "" || records[1].Fault != chaos.Truncated || string(records[1].Raw) != `{"ver` {
+		t.Errorf("unexpected records %+v", records)
+	}
+}
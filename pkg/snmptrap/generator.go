@@ -5,14 +5,24 @@
 // SNMP BER encoding. This is intentional for simulation purposes — a production
 // SNMP implementation would use a library like gosnmp for proper encoding.
 //
-// TODO: Replace global rand usage with a *rand.Rand instance per generator to
-// avoid potential race conditions when multiple goroutines generate traps
-// concurrently. In Go 1.20+, prefer rand.New(rand.NewSource(...)).
+// Randomness and timestamps come from a Generator, which owns its random
+// source and clock: two generators with the same seed and clock produce the
+// same traps. The package-level functions use a shared, randomly seeded
+// generator on the wall clock.
+//
+// Chaos injects malformed traps for fuzzing consumers: truncated, oversized
+// or non-UTF-8 datagrams and duplicate, out-of-order or skewed timestamps.
+// Its TrapRecord names the fault, for SaveRecordToFile.
 package snmptrap
 
 import (
+	"fmt"
 	"math/rand"
+	"sync"
 	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
 )
 
 // Trap represents a single SNMP v2c trap event generated by the simulator.
@@ -27,10 +37,66 @@ type Trap struct {
 	Variables map[string]string `json:"variables"`  // Variable bindings (OID → value pairs)
 }
 
-// RandomTrap generates a random SNMP trap for the given device type and source.
-// Supported device types are "router", "switch", and "firewall"; unknown types
-// default to router templates.
+// Generator produces traps from its own random source and clock. It is safe
+// for concurrent use.
+type Generator struct {
+	mu         sync.Mutex
+	rng        *rand.Rand
+	seed       int64
+	clock      simclock.Clock
+	severities map[string]float64 // Relative severity weights; nil picks templates uniformly
+}
+
+// NewGenerator creates a Generator seeded with seed (0 picks one from the
+// clock; see Seed) that timestamps traps with clock (nil means
+// simclock.Real).
+func NewGenerator(seed int64, clock simclock.Clock) *Generator {
+	seed = simclock.Seed(seed)
+	if clock == nil {
+		clock = simclock.Real
+	}
+	return &Generator{rng: rand.New(rand.NewSource(seed)), seed: seed, clock: clock}
+}
+
+// Seed returns the seed the generator was created with.
+func (g *Generator) Seed() int64 { return g.seed }
+
+// SetSeverities makes RandomTrap first draw a severity ("info", "warning",
+// "error" or "critical") by the given relative weights and then a template
+// of that severity. Nil or empty weights restore uniform template choice.
+func (g *Generator) SetSeverities(weights map[string]float64) error {
+	for name := range weights {
+		switch name {
+		case "info", "warning", "error", "critical":
+		default:
+			return fmt.Errorf("unknown trap severity %q", name)
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.severities = weights
+	return nil
+}
+
+// defaultGenerator backs the package-level functions.
+var defaultGenerator = NewGenerator(0, nil)
+
+// RandomTrap generates a random SNMP trap for the given device type and
+// source using a shared generator. See Generator.RandomTrap.
 func RandomTrap(deviceType string, source string) Trap {
+	return defaultGenerator.RandomTrap(deviceType, source)
+}
+
+// NewTrap builds a v2c trap from a template with the given source and
+// variable bindings, timestamped now.
+func NewTrap(t TrapTemplate, source string, vars map[string]string) Trap {
+	return defaultGenerator.NewTrap(t, source, vars)
+}
+
+// RandomTrap generates a random SNMP trap for the given device type and source.
+// Supported device types are "router", "switch", "firewall", "server" and
+// "access-point"; unknown types default to router templates.
+func (g *Generator) RandomTrap(deviceType string, source string) Trap {
 	var templates []TrapTemplate
 
 	switch deviceType {
@@ -40,12 +106,45 @@ func RandomTrap(deviceType string, source string) Trap {
 		templates = SwitchTraps
 	case "firewall":
 		templates = FirewallTraps
+	case "server":
+		templates = ServerTraps
+	case "access-point":
+		templates = AccessPointTraps
 	default:
 		templates = RouterTraps
 	}
 
-	t := templates[rand.Intn(len(templates))]
+	g.mu.Lock()
+	if sev := traffic.PickSeverity(g.rng, g.severities); sev != "" {
+		templates = withSeverity(templates, sev)
+	}
+	t := templates[g.rng.Intn(len(templates))]
+	g.mu.Unlock()
+
+	return g.NewTrap(t, source, map[string]string{
+		"ifIndex": "1",
+		"value":   "threshold-crossed",
+	})
+}
+
+// withSeverity returns the templates of the given severity, or all of them
+// if the device type has none of that severity.
+func withSeverity(templates []TrapTemplate, severity string) []TrapTemplate {
+	var matched []TrapTemplate
+	for _, t := range templates {
+		if t.Severity == severity {
+			matched = append(matched, t)
+		}
+	}
+	if len(matched) == 0 {
+		return templates
+	}
+	return matched
+}
 
+// NewTrap builds a v2c trap from a template with the given source and
+// variable bindings, timestamped by the generator's clock.
+func (g *Generator) NewTrap(t TrapTemplate, source string, vars map[string]string) Trap {
 	return Trap{
 		Version:   "v2c",
 		Community: "public",
@@ -53,10 +152,7 @@ func RandomTrap(deviceType string, source string) Trap {
 		Source:    source,
 		Message:   t.Message,
 		Severity:  t.Severity,
-		Timestamp: time.Now().UTC(),
-		Variables: map[string]string{
-			"ifIndex": "1",
-			"value":   "threshold-crossed",
-		},
+		Timestamp: g.clock.Now(),
+		Variables: vars,
 	}
 }
@@ -0,0 +1,41 @@
+package snmptrap
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+)
+
+func TestGenerator_SameSeedSameTraps(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := NewGenerator(42, simclock.NewManual(start))
+	b := NewGenerator(42, simclock.NewManual(start))
+
+	for i := 0; i < 50; i++ {
+		ta, tb := a.RandomTrap("firewall", "fw-1"), b.RandomTrap("firewall", "fw-1")
+		if !reflect.DeepEqual(ta, tb) {
+			t.Fatalf("trap %d differs: %+v vs %+v", i, ta, tb)
+		}
+		if !ta.Timestamp.Equal(start) {
+			t.Fatalf("expected timestamp from the injected clock, got %v", ta.Timestamp)
+		}
+	}
+}
+
+func TestGenerator_SeverityWeights(t *testing.T) {
+	g := NewGenerator(1, nil)
+	if err := g.SetSeverities(map[string]float64{"critical": 1}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		if trap := g.RandomTrap("router", "r-1"); trap.Severity != "critical" {
+			t.Fatalf("expected only critical traps, got %s %q", trap.Severity, trap.Message)
+		}
+	}
+
+	if err := g.SetSeverities(map[string]float64{"fatal": 1}); err == nil {
+		t.Error("expected error for unknown severity")
+	}
+}
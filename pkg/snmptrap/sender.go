@@ -2,27 +2,68 @@ package snmptrap
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
 )
 
 // SendTrap sends a JSON-encoded trap to the specified UDP address.
 //
-// NOTE: A new UDP connection is created for each call. For high-throughput
-// scenarios, consider reusing a single connection or implementing a connection
-// pool. Additionally, there is no send timeout configured — production code
-// should set a write deadline via conn.SetWriteDeadline.
+// NOTE: A new UDP connection is created for each call; use a Sender to send
+// many traps over one connection. Additionally, there is no send timeout
+// configured — production code should set a write deadline via
+// conn.SetWriteDeadline.
 func SendTrap(addr string, trap Trap) error {
+	data, err := json.Marshal(trap)
+	if err != nil {
+		return err
+	}
+	return SendRaw(addr, data)
+}
+
+// SendRaw sends data as one UDP datagram to the specified address, e.g. a
+// trap altered by Chaos.
+func SendRaw(addr string, data []byte) error {
 	conn, err := net.Dial("udp", addr)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
+	_, err = conn.Write(data)
+	return err
+}
+
+// Sender sends traps over one UDP connection, for callers sending many
+// traps to the same address. It is not safe for concurrent use.
+type Sender struct {
+	conn net.Conn
+}
+
+// NewSender opens a UDP connection to addr.
+func NewSender(addr string) (*Sender, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trap connection to %s: %w", addr, err)
+	}
+	return &Sender{conn: conn}, nil
+}
+
+// Send sends a JSON-encoded trap as one datagram.
+func (s *Sender) Send(trap Trap) error {
 	data, err := json.Marshal(trap)
 	if err != nil {
 		return err
 	}
+	return s.SendRaw(data)
+}
 
-	_, err = conn.Write(data)
+// SendRaw sends data as one datagram, e.g. a trap altered by Chaos.
+func (s *Sender) SendRaw(data []byte) error {
+	_, err := s.conn.Write(data)
 	return err
 }
+
+// Close closes the connection.
+func (s *Sender) Close() error {
+	return s.conn.Close()
+}
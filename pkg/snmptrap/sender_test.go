@@ -0,0 +1,44 @@
+package snmptrap
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSender_ReusesConnection(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer pc.Close()
+
+	s, err := NewSender(pc.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer s.Close()
+
+	var from []string
+	for i := 0; i < 2; i++ {
+		if err := s.Send(NewTrap(LinkDown, "r-1", nil)); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+
+		pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		buf := make([]byte, 4096)
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("receive %d: %v", i, err)
+		}
+		var trap Trap
+		if err := json.Unmarshal(buf[:n], &trap); err != nil || trap.OID != LinkDown.OID {
+			t.Fatalf("receive %d: unexpected datagram %q (%v)", i, buf[:n], err)
+		}
+		from = append(from, addr.String())
+	}
+	if from[0] != from[1] {
+		t.Errorf("expected both traps from one socket, got %v", from)
+	}
+}
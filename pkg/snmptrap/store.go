@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"sync"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/jsonarray"
 )
 
 // fileLock guards concurrent access to the trap persistence file.
@@ -17,14 +19,19 @@ var fileLock sync.Mutex
 //
 // NOTE: The current implementation reads the entire file, deserializes all
 // records, appends one, and writes everything back. This is O(N) per append
-// and results in O(N^2) total cost over N inserts. For production workloads,
-// consider using JSON Lines (one JSON object per line) with append-only writes,
-// which would make each insert O(1).
+// and results in O(N^2) total cost over N inserts. Use a Recorder to persist
+// many traps.
 func SaveTrapToFile(path string, trap Trap) error {
+	return SaveRecordToFile(path, TrapRecord{Trap: trap})
+}
+
+// SaveRecordToFile appends a trap record, with its fault label, to the JSON
+// array stored at the given path, as SaveTrapToFile does.
+func SaveRecordToFile(path string, rec TrapRecord) error {
 	fileLock.Lock()
 	defer fileLock.Unlock()
 
-	var traps []Trap
+	var traps []TrapRecord
 
 	// Read existing file
 	data, err := os.ReadFile(path)
@@ -35,7 +42,7 @@ func SaveTrapToFile(path string, trap Trap) error {
 	}
 
 	// Append new trap
-	traps = append(traps, trap)
+	traps = append(traps, rec)
 
 	// Write back
 	out, err := json.MarshalIndent(traps, "", "  ")
@@ -45,3 +52,58 @@ func SaveTrapToFile(path string, trap Trap) error {
 
 	return os.WriteFile(path, out, 0644)
 }
+
+// Recorder buffers trap records in memory and appends them to a JSON array
+// file on Flush, like syslogsim.Recorder. Only records added since the last
+// Flush are kept and written, so memory and the cost of a flush do not grow
+// with the file. It is not safe for concurrent use.
+type Recorder struct {
+	path    string
+	pending []TrapRecord
+	written int // Records already in the file
+}
+
+// NewRecorder returns a Recorder appending to the JSON array at path, which
+// may not exist yet.
+func NewRecorder(path string) (*Recorder, error) {
+	written, err := jsonarray.Len(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trap file %s: %w", path, err)
+	}
+	return &Recorder{path: path, written: written}, nil
+}
+
+// Add buffers a trap record until the next Flush.
+func (r *Recorder) Add(rec TrapRecord) {
+	r.pending = append(r.pending, rec)
+}
+
+// Pending returns the number of records added since the last Flush.
+func (r *Recorder) Pending() int { return len(r.pending) }
+
+// Flush writes the pending records to the file and returns how many were
+// persisted. The first flush into an empty file creates it atomically; later
+// flushes append in place (see jsonarray.Append). A failed append is rolled
+// back and its records stay pending.
+func (r *Recorder) Flush() (int, error) {
+	pending := len(r.pending)
+	if pending == 0 {
+		return 0, nil
+	}
+
+	fileLock.Lock()
+	defer fileLock.Unlock()
+
+	var err error
+	if r.written == 0 {
+		err = jsonarray.Create(r.path, r.pending)
+	} else {
+		err = jsonarray.Append(r.path, r.pending)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write trap file %s: %w", r.path, err)
+	}
+	r.written += pending
+	r.pending = r.pending[:0]
+	return pending, nil
+}
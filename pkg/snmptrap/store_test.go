@@ -0,0 +1,57 @@
+package snmptrap
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/chaos"
+)
+
+func TestRecorder_AppendsAcrossFlushes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traps.json")
+	first := NewTrap(RouterTraps[0], "r-1", nil)
+	if err := SaveTrapToFile(path, first); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	r, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := []TrapRecord{{Trap: first}}
+	for flush := 0; flush < 3; flush++ {
+		for i := 0; i < 2; i++ {
+			rec := TrapRecord{Trap: NewTrap(LinkUp, "r-1", nil), Fault: chaos.Truncated, Raw: []byte(`{"ver`)}
+			r.Add(rec)
+			want = append(want, rec)
+		}
+		if n, err := r.Flush(); n != 2 || err != nil {
+			t.Fatalf("flush %d: expected 2 records, got %d (%v)", flush, n, err)
+		}
+		if r.Pending() != 0 || len(r.pending) != 0 {
+			t.Fatalf("flush %d: expected flushed records released, %d kept", flush, len(r.pending))
+		}
+	}
+
+	// Appending keeps the layout of a file written in one go.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	expected, _ := json.MarshalIndent(want, "", "  ")
+	if string(data) != string(expected) {
+		t.Errorf("unexpected file:\n%s\nwant:\n%s", data, expected)
+	}
+}
+
+func TestRecorder_RejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traps.json")
+	if err := os.WriteFile(path, []byte(`[{"oid": "1.3`), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := NewRecorder(path); err == nil {
+		t.Fatal("expected an error for a truncated trap file")
+	}
+}
@@ -19,10 +19,61 @@ var RouterTraps = []TrapTemplate{
 // SwitchTraps contains SNMP trap templates for switch devices.
 var SwitchTraps = []TrapTemplate{
 	{OID: "1.3.6.1.4.1.9.9.13.3.1.3", Message: "Port security violation", Severity: "error"},
-	{OID: "1.3.6.1.4.1.9.9.46.2.1.1", Message: "Spanning tree topology change", Severity: "warning"},
+	TopologyChange,
 }
 
 // FirewallTraps contains SNMP trap templates for firewall devices.
 var FirewallTraps = []TrapTemplate{
-	{OID: "1.3.6.1.4.1.9.9.147.1.2", Message: "Firewall authentication failure", Severity: "critical"},
+	FirewallAuthFailure,
+	SessionFlood,
+	{OID: "1.3.6.1.6.3.1.1.5.5", Message: "SNMP authentication failure", Severity: "warning"},
 }
+
+// ServerTraps contains SNMP trap templates for servers (Net-SNMP agent OIDs).
+var ServerTraps = []TrapTemplate{
+	{OID: "1.3.6.1.6.3.1.1.5.1", Message: "Host restarted (coldStart)", Severity: "warning"},
+	{OID: "1.3.6.1.4.1.8072.4.0.3", Message: "SNMP agent restarted", Severity: "info"},
+	{OID: "1.3.6.1.4.1.2021.9.1.100", Message: "Disk usage above threshold", Severity: "error"},
+}
+
+// AccessPointTraps contains SNMP trap templates for wireless access points.
+// OIDs reference the AIRESPACE-WIRELESS-MIB station traps.
+var AccessPointTraps = []TrapTemplate{
+	ClientAssociated,
+	ClientDisassociated,
+	{OID: "1.3.6.1.4.1.14179.2.6.3.2", Message: "Client deauthenticated", Severity: "warning"},
+	{OID: "1.3.6.1.4.1.14179.2.6.3.5", Message: "Client association failure", Severity: "error"},
+}
+
+// Paired templates for state-driven traps: every problem notification has a
+// matching recovery with its own OID. OIDs reference IF-MIB, BGP4-MIB,
+// CISCO-PROCESS-MIB, CISCO-MEMORY-POOL-MIB, CISCO-FIREWALL-MIB,
+// CISCO-RTTMON-MIB (IP SLA reachability: a probe timeout, then the generic
+// rttMonNotification) and DISMAN-EVENT-MIB (UCD-SNMP-MIB process checks
+// crossing prErrorFlag).
+var (
+	LinkDown              = TrapTemplate{OID: "1.3.6.1.6.3.1.1.5.3", Message: "Interface down", Severity: "critical"}
+	LinkUp                = TrapTemplate{OID: "1.3.6.1.6.3.1.1.5.4", Message: "Interface up", Severity: "info"}
+	BGPBackwardTransition = TrapTemplate{OID: "1.3.6.1.2.1.15.0.2", Message: "BGP session down", Severity: "error"}
+	BGPEstablished        = TrapTemplate{OID: "1.3.6.1.2.1.15.0.1", Message: "BGP session established", Severity: "info"}
+	CPURising             = TrapTemplate{OID: "1.3.6.1.4.1.9.9.109.2.0.1", Message: "CPU utilization above threshold", Severity: "warning"}
+	CPUFalling            = TrapTemplate{OID: "1.3.6.1.4.1.9.9.109.2.0.2", Message: "CPU utilization back below threshold", Severity: "info"}
+	MemoryLow             = TrapTemplate{OID: "1.3.6.1.4.1.9.9.48.2.0.1", Message: "Memory pool low", Severity: "warning"}
+	MemoryRecovered       = TrapTemplate{OID: "1.3.6.1.4.1.9.9.48.2.0.2", Message: "Memory pool recovered", Severity: "info"}
+	ServiceDown           = TrapTemplate{OID: "1.3.6.1.2.1.88.2.0.2", Message: "Monitored process not running", Severity: "error"}
+	ServiceUp             = TrapTemplate{OID: "1.3.6.1.2.1.88.2.0.3", Message: "Monitored process running", Severity: "info"}
+	SessionFlood          = TrapTemplate{OID: "1.3.6.1.4.1.9.9.147.2.0.1", Message: "Connection limit exceeded (session flood)", Severity: "error"}
+	SessionFloodCleared   = TrapTemplate{OID: "1.3.6.1.4.1.9.9.147.2.0.2", Message: "Connection count back below limit", Severity: "info"}
+	ReachabilityLost      = TrapTemplate{OID: "1.3.6.1.4.1.9.9.42.2.0.2", Message: "IP SLA reachability lost", Severity: "critical"}
+	ReachabilityRestored  = TrapTemplate{OID: "1.3.6.1.4.1.9.9.42.2.0.5", Message: "IP SLA reachability restored", Severity: "info"}
+	ColdStart             = TrapTemplate{OID: "1.3.6.1.6.3.1.1.5.1", Message: "Device restarted (coldStart)", Severity: "warning"}
+)
+
+// Further templates for state-driven traps, shared with the per-device lists
+// above so both always send the same OIDs.
+var (
+	FirewallAuthFailure = TrapTemplate{OID: "1.3.6.1.4.1.9.9.147.1.2", Message: "Firewall authentication failure", Severity: "critical"}
+	ClientAssociated    = TrapTemplate{OID: "1.3.6.1.4.1.14179.2.6.3.53", Message: "Client associated", Severity: "info"}
+	ClientDisassociated = TrapTemplate{OID: "1.3.6.1.4.1.14179.2.6.3.1", Message: "Client disassociated", Severity: "info"}
+	TopologyChange      = TrapTemplate{OID: "1.3.6.1.4.1.9.9.46.2.1.1", Message: "Spanning tree topology change", Severity: "warning"}
+)
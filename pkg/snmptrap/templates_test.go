@@ -0,0 +1,26 @@
+package snmptrap
+
+import "testing"
+
+// TestPairedTemplates_DistinctOIDs checks that a receiver can tell every
+// problem from its recovery by OID alone.
+func TestPairedTemplates_DistinctOIDs(t *testing.T) {
+	pairs := [][2]TrapTemplate{
+		{LinkDown, LinkUp},
+		{BGPBackwardTransition, BGPEstablished},
+		{CPURising, CPUFalling},
+		{MemoryLow, MemoryRecovered},
+		{ServiceDown, ServiceUp},
+		{SessionFlood, SessionFloodCleared},
+		{ReachabilityLost, ReachabilityRestored},
+	}
+	seen := make(map[string]string)
+	for _, p := range pairs {
+		for _, tmpl := range p {
+			if other, ok := seen[tmpl.OID]; ok {
+				t.Errorf("%q and %q share OID %s", other, tmpl.Message, tmpl.OID)
+			}
+			seen[tmpl.OID] = tmpl.Message
+		}
+	}
+}
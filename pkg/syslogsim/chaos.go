@@ -0,0 +1,38 @@
+package syslogsim
+
+import (
+	"bytes"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/chaos"
+)
+
+// invalidPRIs replace a message's PRI for chaos.InvalidPRI: out of range,
+// negative, empty, not a number, with a leading zero, or missing.
+var invalidPRIs = []string{"<192>", "<999>", "<-1>", "<>", "<1a>", "<013>", ""}
+
+// corrupt applies the byte faults to raw, the rendered message m. Other
+// faults leave it unchanged.
+func (s *Simulator) corrupt(f chaos.Fault, raw []byte, m Message) []byte {
+	switch f {
+	case chaos.Truncated:
+		return s.chaos.Truncate(raw)
+	case chaos.InvalidPRI:
+		return replacePRI(raw, invalidPRIs[s.chaos.Intn(len(invalidPRIs))])
+	case chaos.Oversized:
+		return s.chaos.Oversize(raw, 0)
+	case chaos.InvalidUTF8:
+		// Within the MSG, which the dialects render last, so the header
+		// stays parseable.
+		return s.chaos.CorruptUTF8(raw, len(raw)-len(m.Text), 0)
+	}
+	return raw
+}
+
+// replacePRI replaces the leading "<N>" of raw with pri.
+func replacePRI(raw []byte, pri string) []byte {
+	end := bytes.IndexByte(raw, '>')
+	if len(raw) == 0 || raw[0] != '<' || end < 0 {
+		return append([]byte(pri), raw...)
+	}
+	return append([]byte(pri), raw[end+1:]...)
+}
@@ -0,0 +1,87 @@
+package syslogsim
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/chaos"
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+)
+
+func TestSimulator_ChaosLabelsRecords(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	path := filepath.Join(t.TempDir(), "syslog.json")
+	summary, err := RunSimulation(context.Background(), Config{
+		Host:         "127.0.0.1",
+		Port:         listener.LocalAddr().(*net.UDPAddr).Port,
+		BatchSize:    40,
+		TotalBatches: 1,
+		FilePath:     path,
+		Seed:         1,
+		Clock:        simclock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Chaos:        chaos.Config{Rate: 0.5, Faults: []chaos.Fault{chaos.InvalidPRI, chaos.InvalidUTF8}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var records []SyslogRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+
+	faults := 0
+	for _, rec := range records {
+		switch rec.Fault {
+		case "":
+			if !strings.HasPrefix(rec.Raw, "<") || rec.RawBytes != nil {
+				t.Errorf("expected an intact message, got %+v", rec)
+			}
+		case chaos.InvalidPRI:
+			faults++
+			if strings.HasPrefix(rec.Raw, "<"+strconv.Itoa(rec.Priority)+">") {
+				t.Errorf("expected an invalid PRI, got %q", rec.Raw)
+			}
+		case chaos.InvalidUTF8:
+			faults++
+			if utf8.Valid(rec.RawBytes) {
+				t.Errorf("expected the invalid bytes in raw_bytes, got %+v", rec)
+			}
+		default:
+			t.Errorf("unexpected fault %q", rec.Fault)
+		}
+	}
+	if faults == 0 || faults == len(records) || faults != summary.Faults {
+		t.Errorf("expected some of %d records labelled, as many as summary %+v, got %d", len(records), summary, faults)
+	}
+}
+
+func TestReplacePRI(t *testing.T) {
+	tests := []struct{ raw, pri, want string }{
+		{"<134>1 2024-01-01T00:00:00Z h", "<999>", "<999>1 2024-01-01T00:00:00Z h"},
+		{"<13>Jan  1 00:00:00 h app: x", "", "Jan  1 00:00:00 h app: x"},
+		{"no pri", "<-1>", "<-1>no pri"},
+	}
+	for _, tt := range tests {
+		if got := string(replacePRI([]byte(tt.raw), tt.pri)); got != tt.want {
+			t.Errorf("replacePRI(%q, %q) = %q, want %q", tt.raw, tt.pri, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package syslogsim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect is an output format for syslog messages.
+type Dialect string
+
+const (
+	// DialectRFC5424 is the IETF syslog format (the default).
+	DialectRFC5424 Dialect = "rfc5424"
+	// DialectRFC3164 is the legacy BSD format:
+	//	<PRI>Mmm dd hh:mm:ss HOSTNAME APP[PROCID]: MSG
+	DialectRFC3164 Dialect = "rfc3164"
+	// DialectCisco is Cisco IOS with sequence numbers and a
+	// %FACILITY-SEVERITY-MNEMONIC tag:
+	//	<PRI>SEQ: HOSTNAME: Mmm dd hh:mm:ss.mmm TZ: %FAC-SEV-MNEMONIC: MSG
+	DialectCisco Dialect = "cisco"
+	// DialectJunos is Juniper Junos, BSD style with the event tag:
+	//	<PRI>Mmm dd hh:mm:ss HOSTNAME APP[PROCID]: MSGID: MSG
+	DialectJunos Dialect = "junos"
+	// DialectCEF is ArcSight Common Event Format behind a BSD header.
+	DialectCEF Dialect = "cef"
+	// DialectLEEF is IBM QRadar Log Event Extended Format 1.0 behind a BSD
+	// header.
+	DialectLEEF Dialect = "leef"
+)
+
+// dialects lists the dialects in the order Dialects reports them.
+var dialects = []Dialect{DialectRFC5424, DialectRFC3164, DialectCisco, DialectJunos, DialectCEF, DialectLEEF}
+
+// Dialects returns the names of the supported dialects.
+func Dialects() []string {
+	names := make([]string, len(dialects))
+	for i, d := range dialects {
+		names[i] = string(d)
+	}
+	return names
+}
+
+// ParseDialect returns the dialect called name. The empty name is
+// DialectRFC5424.
+func ParseDialect(name string) (Dialect, error) {
+	if name == "" {
+		return DialectRFC5424, nil
+	}
+	for _, d := range dialects {
+		if string(d) == strings.ToLower(name) {
+			return d, nil
+		}
+	}
+	return "", fmt.Errorf("unknown syslog dialect %q (supported: %s)", name, strings.Join(Dialects(), ", "))
+}
+
+// Vendor fields of the CEF and LEEF headers.
+const (
+	eventVendor  = "Datasource"
+	eventVersion = "1.0"
+)
+
+// Format renders the message in dialect d; the empty dialect is RFC 5424.
+// Dialects other than RFC 5424 ignore StructuredData and BOM.
+func (m Message) Format(d Dialect) string {
+	switch d {
+	case DialectRFC3164:
+		return m.bsdHeader() + m.tag() + ": " + m.Text
+	case DialectCisco:
+		return m.cisco()
+	case DialectJunos:
+		line := m.bsdHeader() + m.tag() + ": "
+		if m.MsgID != "" {
+			line += m.MsgID + ": "
+		}
+		return line + m.Text
+	case DialectCEF:
+		return m.bsdHeader() + m.cef()
+	case DialectLEEF:
+		return m.bsdHeader() + m.leef()
+	default:
+		return m.String()
+	}
+}
+
+// bsdHeader returns the RFC 3164 PRI, TIMESTAMP and HOSTNAME, with a
+// trailing space.
+func (m Message) bsdHeader() string {
+	return fmt.Sprintf("<%d>%s %s ", m.Priority(), m.Timestamp.Format(time.Stamp), nilValue(m.Hostname))
+}
+
+// tag returns the RFC 3164 TAG: the app name and, if known, its PROCID.
+func (m Message) tag() string {
+	tag := nilValue(m.AppName)
+	if m.ProcID != "" {
+		tag += "[" + m.ProcID + "]"
+	}
+	return tag
+}
+
+// cisco renders the message as Cisco IOS does with "service
+// sequence-numbers" and "logging origin-id hostname". The facility is the
+// app name and the mnemonic the MSGID, both upper-cased.
+func (m Message) cisco() string {
+	facility := ciscoToken(m.AppName, "SYS")
+	mnemonic := ciscoToken(m.MsgID, "EVENT")
+	return fmt.Sprintf("<%d>%06d: %s: %s: %%%s-%d-%s: %s",
+		m.Priority(), m.Sequence, nilValue(m.Hostname),
+		m.Timestamp.Format("Jan _2 15:04:05.000 MST"),
+		facility, m.Severity, mnemonic, m.Text)
+}
+
+// ciscoToken upper-cases s and keeps only letters, digits and underscores,
+// or returns def if nothing is left.
+func ciscoToken(s, def string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return -1
+		}
+	}, s)
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// eventSeverities maps syslog severities to the 0-10 scale of CEF and LEEF.
+var eventSeverities = [...]int{
+	SeverityEmergency: 10,
+	SeverityAlert:     9,
+	SeverityCritical:  8,
+	SeverityError:     7,
+	SeverityWarning:   5,
+	SeverityNotice:    3,
+	SeverityInfo:      2,
+	SeverityDebug:     0,
+}
+
+func (m Message) eventSeverity() int {
+	if m.Severity >= 0 && int(m.Severity) < len(eventSeverities) {
+		return eventSeverities[m.Severity]
+	}
+	return 0
+}
+
+// eventID is the CEF signature ID and LEEF event ID: the MSGID, or the app
+// name without one.
+func (m Message) eventID() string {
+	if m.MsgID != "" {
+		return m.MsgID
+	}
+	return nilValue(m.AppName)
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, "|", `\|`)
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, "=", `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+// cef renders the message as a CEF:0 event.
+func (m Message) cef() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		eventVendor, cefHeaderEscaper.Replace(nilValue(m.AppName)), eventVersion,
+		cefHeaderEscaper.Replace(m.eventID()), cefHeaderEscaper.Replace(m.Text), m.eventSeverity())
+
+	fmt.Fprintf(&b, "rt=%d dvchost=%s", m.Timestamp.UnixMilli(), cefExtensionEscaper.Replace(nilValue(m.Hostname)))
+	if m.SourceIP != "" {
+		b.WriteString(" dvc=" + m.SourceIP)
+	}
+	if m.ProcID != "" {
+		b.WriteString(" dvcpid=" + m.ProcID)
+	}
+	b.WriteString(" msg=" + cefExtensionEscaper.Replace(m.Text))
+	return b.String()
+}
+
+// leefEscaper keeps the header's and attributes' delimiters out of values.
+var leefEscaper = strings.NewReplacer("|", `\|`, "\t", " ", "\n", " ", "\r", " ")
+
+// leefTimeLayout is the default devTime format of LEEF, with milliseconds
+// and the zone, declared in devTimeFormat.
+const leefTimeLayout = "Jan 02 2006 15:04:05.000 MST"
+
+// leef renders the message as a LEEF:1.0 event with tab-separated
+// attributes.
+func (m Message) leef() string {
+	attrs := []string{
+		"devTime=" + m.Timestamp.Format(leefTimeLayout),
+		"devTimeFormat=MMM dd yyyy HH:mm:ss.SSS z",
+		"sev=" + strconv.Itoa(max(m.eventSeverity(), 1)),
+		"identHostName=" + leefEscaper.Replace(nilValue(m.Hostname)),
+	}
+	if m.SourceIP != "" {
+		attrs = append(attrs, "src="+m.SourceIP)
+	}
+	attrs = append(attrs, "msg="+leefEscaper.Replace(m.Text))
+
+	return fmt.Sprintf("LEEF:1.0|%s|%s|%s|%s|%s",
+		eventVendor, leefEscaper.Replace(nilValue(m.AppName)), eventVersion,
+		leefEscaper.Replace(m.eventID()), strings.Join(attrs, "\t"))
+}
@@ -0,0 +1,75 @@
+package syslogsim
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessage_Format(t *testing.T) {
+	m := Message{
+		Timestamp: time.Date(2024, 3, 1, 9, 5, 7, 250000000, time.UTC),
+		Facility:  FacilityLocal7,
+		Severity:  SeverityError,
+		Hostname:  "rtr-1",
+		AppName:   "link",
+		ProcID:    "412",
+		MsgID:     "UPDOWN",
+		Text:      "Interface Gi0/1 changed state to down",
+		Sequence:  42,
+		SourceIP:  "10.0.0.1",
+	}
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectRFC3164, "<187>Mar  1 09:05:07 rtr-1 link[412]: Interface Gi0/1 changed state to down"},
+		{DialectCisco, "<187>000042: rtr-1: Mar  1 09:05:07.250 UTC: %LINK-3-UPDOWN: Interface Gi0/1 changed state to down"},
+		{DialectJunos, "<187>Mar  1 09:05:07 rtr-1 link[412]: UPDOWN: Interface Gi0/1 changed state to down"},
+		{DialectCEF, "<187>Mar  1 09:05:07 rtr-1 CEF:0|Datasource|link|1.0|UPDOWN|Interface Gi0/1 changed state to down|7|" +
+			"rt=1709283907250 dvchost=rtr-1 dvc=10.0.0.1 dvcpid=412 msg=Interface Gi0/1 changed state to down"},
+		{DialectLEEF, "<187>Mar  1 09:05:07 rtr-1 LEEF:1.0|Datasource|link|1.0|UPDOWN|" +
+			"devTime=Mar 01 2024 09:05:07.250 UTC\tdevTimeFormat=MMM dd yyyy HH:mm:ss.SSS z\tsev=7\tidentHostName=rtr-1\tsrc=10.0.0.1\tmsg=Interface Gi0/1 changed state to down"},
+		{DialectRFC5424, m.String()},
+	}
+	for _, tt := range tests {
+		if got := m.Format(tt.dialect); got != tt.want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.dialect, got, tt.want)
+		}
+	}
+}
+
+func TestMessage_FormatEscaping(t *testing.T) {
+	m := Message{Timestamp: time.Unix(0, 0).UTC(), AppName: "fw|1", Text: `a=b|c\d`}
+	if got := m.Format(DialectCEF); !strings.Contains(got, `|fw\|1|`) || !strings.Contains(got, `|a=b\|c\\d|`) || !strings.HasSuffix(got, ` msg=a\=b|c\\d`) {
+		t.Errorf("unexpected CEF escaping: %s", got)
+	}
+	if got := m.Format(DialectCisco); !strings.Contains(got, "%FW1-0-EVENT: ") {
+		t.Errorf("unexpected Cisco tag: %s", got)
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	if d, err := ParseDialect(""); err != nil || d != DialectRFC5424 {
+		t.Errorf("expected RFC 5424 by default, got %q, %v", d, err)
+	}
+	if d, err := ParseDialect("Cisco"); err != nil || d != DialectCisco {
+		t.Errorf("expected cisco, got %q, %v", d, err)
+	}
+	if _, err := ParseDialect("gelf"); err == nil {
+		t.Error("expected error for unknown dialect")
+	}
+}
+
+func TestGenerator_Dialect(t *testing.T) {
+	g := NewGenerator(1, nil)
+	g.SetDialect(DialectCisco)
+	for i := 1; i <= 3; i++ {
+		msg, _ := g.GenerateDeviceMessage("switch", "sw-1")
+		if !strings.Contains(msg, fmt.Sprintf(">%06d: sw-1: ", i)) {
+			t.Errorf("expected sequence %d, got %s", i, msg)
+		}
+	}
+}
@@ -6,43 +6,48 @@
 // Messages are both sent over the network and optionally persisted to a local
 // JSON file for debugging and replay.
 //
-// TODO: Accept a context.Context in NewSimulator/Run for graceful shutdown
-// support. Replace deprecated rand.Seed with rand.New(rand.NewSource(...))
-// for Go 1.20+.
+// Message text, app names, facilities and severities come from template
+// libraries (see Library). Built-in libraries for network, server, security
+// and wireless devices are embedded from the templates directory; a
+// Generator can be given its own with SetLibrary.
+//
+// Generated messages carry the full RFC 5424 header: fractional timestamps
+// with an offset, a PROCID per app, the template's MSGID, and [meta] and
+// [origin] structured data consistent per hostname. SetHeaders selects the
+// time zone, a UTF-8 BOM, or the minimal "- - -" header.
+//
+// Randomness and timestamps come from a Generator, which owns its random
+// source and clock: two generators with the same seed and clock produce the
+// same messages. The package-level functions use a shared, randomly seeded
+// generator on the wall clock.
+//
+// RunLoad is a separate load-test mode that replays pre-generated messages
+// over many concurrent connections and reports throughput and latency.
+//
+// Simulator.Run persists messages through a Recorder, which buffers them and
+// appends them to the file at most once per batch or second, and flushes it
+// when the run ends or its context is cancelled.
+//
+// With Config.Chaos enabled, the simulator injects the faults of package
+// chaos into a fraction of its messages: truncated, oversized or non-UTF-8
+// messages, invalid PRI values, and duplicate, out-of-order or skewed
+// timestamps. The persisted record of such a message names its fault.
 package syslogsim
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"net"
+	"strconv"
+	"sync"
 	"time"
-)
-
-// Severity represents syslog severity levels per RFC 5424.
-type Severity int
 
-const (
-	SeverityInfo     Severity = iota // Informational message
-	SeverityWarn                     // Warning condition
-	SeverityError                    // Error condition
-	SeverityCritical                 // Critical condition requiring immediate action
+	"github.com/ibm-live-project-interns/datasource/pkg/chaos"
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
 )
 
-// facility is the syslog facility code (1 = user-level messages).
-const facility = 1
-
-var networkTemplates = []string{
-	"Interface Gi0/%d changed state to %s",
-	"BGP session to peer %s went %s",
-	"CPU utilization on router exceeded %d%%",
-}
-
-var serverTemplates = []string{
-	"Service %s restarted successfully",
-	"High memory usage detected on process %s",
-	"Login failure for user %s from IP %s",
-}
-
 // Config defines the configuration for the syslog simulation.
 type Config struct {
 	Host         string
@@ -51,170 +56,508 @@ type Config struct {
 	Interval     time.Duration
 	BatchSize    int
 	TotalBatches int
-	FilePath     string
+	FilePath     string         // Persistence file; empty disables persistence
+	Seed         int64          // Random seed; 0 picks one from the clock
+	Clock        simclock.Clock // Message timestamps and batch intervals; nil means simclock.Real
+
+	// Profile, if set, paces messages individually instead of in batches:
+	// the baseline is BatchSize messages per Interval, shaped by the
+	// profile, up to TotalBatches*BatchSize messages. Its severity weights
+	// replace the uniform severity mix.
+	Profile *traffic.Profile
+
+	// Library, if set, supplies the templates of all messages instead of
+	// the default network and server libraries.
+	Library *Library
+	Headers Headers // How the message header is filled in
+	Dialect Dialect // Output format; empty means RFC 5424
+
+	// Over TCP, a dropped connection is re-dialled with exponential backoff
+	// from ReconnectBackoff (default 500ms) up to MaxReconnectBackoff
+	// (default 30s). Meanwhile up to Buffer messages are held and sent once
+	// it is back; 0 drops messages during outages.
+	Buffer              int
+	ReconnectBackoff    time.Duration
+	MaxReconnectBackoff time.Duration
+
+	// Chaos, if enabled, injects faults into a fraction of the messages and
+	// labels them in the persisted records.
+	Chaos chaos.Config
 }
 
 // Simulator encapsulates syslog simulation logic and state.
 type Simulator struct {
-	cfg  Config
-	conn net.Conn
+	cfg       Config
+	out       *transport
+	gen       *Generator
+	clock     simclock.Clock
+	recorder  *Recorder       // Nil when persistence is disabled
+	chaos     *chaos.Injector // Nil when chaos is disabled
+	prev      time.Time       // Timestamp of the last message sent
+	lastFlush time.Time       // Wall time of the last flush
+	summary   Summary
 }
 
-// NewSimulator creates a new syslog Simulator using the provided configuration.
-func NewSimulator(cfg Config) (*Simulator, error) {
-	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+// Summary counts the messages of a simulation run.
+type Summary struct {
+	Sent       int // Written to the connection, including buffered messages sent after a reconnect
+	Failed     int // Never delivered: failed UDP writes, or dropped from or left in the outage buffer
+	Persisted  int // Written to the persistence file
+	Reconnects int // TCP reconnects after a dropped connection
+	Faults     int // Messages with an injected chaos fault
+
+	Disconnects int   // TCP connections lost during the run
+	SendErr     error // Last failure to send a message, if any
+	SaveErr     error // Last failure to write the persistence file, if any; retried at the next flush
+}
+
+// String formats the summary for the commands' final report. Errors are
+// left to the caller.
+func (s Summary) String() string {
+	out := fmt.Sprintf("sent=%d failed=%d persisted=%d reconnects=%d", s.Sent, s.Failed, s.Persisted, s.Reconnects)
+	if s.Disconnects > 0 {
+		out += fmt.Sprintf(" disconnects=%d", s.Disconnects)
+	}
+	if s.Faults > 0 {
+		out += fmt.Sprintf(" faults=%d", s.Faults)
+	}
+	return out
+}
 
-	var conn net.Conn
-	var err error
+// flushEvery and flushInterval bound how many messages, and for how much
+// wall time, the simulator buffers before writing them to the persistence
+// file.
+const (
+	flushEvery    = 100
+	flushInterval = time.Second
+)
 
+// NewSimulator creates a new syslog Simulator using the provided
+// configuration. It returns an error if the target cannot be reached.
+func NewSimulator(cfg Config) (*Simulator, error) {
+	network := "udp"
 	if cfg.Protocol == "tcp" {
-		conn, err = net.Dial("tcp", addr)
-	} else {
-		conn, err = net.Dial("udp", addr)
+		network = "tcp"
 	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
 
+	out, err := dialTransport(network, addr, cfg.Buffer, cfg.ReconnectBackoff, cfg.MaxReconnectBackoff)
 	if err != nil {
-		fmt.Println("warning: unable to connect:", err)
-		conn, _ = net.Dial("udp", "localhost:0")
+		return nil, err
+	}
+
+	clock := cfg.Clock
+	if clock == nil {
+		clock = simclock.Real
 	}
 
-	rand.Seed(time.Now().UnixNano())
+	gen := NewGenerator(cfg.Seed, clock)
+	gen.SetLibrary(cfg.Library)
+	gen.SetHeaders(cfg.Headers)
+	gen.SetDialect(cfg.Dialect)
+	if cfg.Profile != nil {
+		if err := gen.SetSeverities(cfg.Profile.Severities); err != nil {
+			out.close()
+			return nil, err
+		}
+	}
+
+	var injector *chaos.Injector
+	if cfg.Chaos.Enabled() {
+		// Faults get their own source, derived from the seed, so message
+		// content does not depend on the chaos rate.
+		if injector, err = chaos.New(cfg.Chaos, chaos.Faults, rand.New(rand.NewSource(gen.Seed()+2))); err != nil {
+			out.close()
+			return nil, err
+		}
+	}
+
+	var recorder *Recorder
+	if cfg.FilePath != "" {
+		if recorder, err = NewRecorder(cfg.FilePath); err != nil {
+			out.close()
+			return nil, err
+		}
+	}
 
 	return &Simulator{
-		cfg:  cfg,
-		conn: conn,
+		cfg:      cfg,
+		out:      out,
+		gen:      gen,
+		clock:    clock,
+		recorder: recorder,
+		chaos:    injector,
 	}, nil
 }
 
+// Seed returns the seed the simulator's messages are generated from.
+func (s *Simulator) Seed() int64 { return s.gen.Seed() }
+
 // Run starts the syslog traffic simulation.
 // It generates syslog messages, sends them over the network,
-// and optionally persists them to a file.
-func (s *Simulator) Run() error {
-	defer s.conn.Close()
+// and optionally persists them to a file. Batches are Interval apart on the
+// configured clock. The run ends after TotalBatches, when a bounded clock
+// reaches its end, or when ctx is cancelled; buffered records are flushed
+// to the file before Run returns. It returns an error if messages held
+// during a TCP outage could not be delivered at the end. The returned
+// summary counts the messages of the run, also on error.
+func (s *Simulator) Run(ctx context.Context) (Summary, error) {
+	if s.cfg.Profile != nil {
+		s.runShaped(ctx)
+	} else {
+		s.runBatches(ctx)
+	}
+
+	closeErr := s.out.close()
+	s.summary.Sent, s.summary.Failed, s.summary.Reconnects = s.out.sent, s.out.dropped, s.out.reconnects
+	s.summary.Disconnects = s.out.disconnects
 
+	if err := s.flush(); err != nil {
+		return s.summary, fmt.Errorf("failed to save syslog: %w", err)
+	}
+	return s.summary, closeErr
+}
+
+// runBatches sends BatchSize messages every Interval.
+func (s *Simulator) runBatches(ctx context.Context) {
 	batchCount := 0
 
-	for {
+	for ctx.Err() == nil {
 		batchCount++
 
 		for i := 0; i < s.cfg.BatchSize; i++ {
-			msg, pri := s.generateSyslog()
+			s.send()
+		}
+		s.maybeFlush(true)
 
-			_, err := s.conn.Write([]byte(msg + "\n"))
-			if err != nil {
-				fmt.Println("warning: failed to send syslog:", err)
-			}
+		if s.cfg.TotalBatches > 0 && batchCount >= s.cfg.TotalBatches {
+			break
+		}
 
-			if err := SaveSyslogToFile(s.cfg.FilePath, msg, pri); err != nil {
-				fmt.Println("warning: failed to save syslog:", err)
-			}
+		if err := s.clock.Sleep(ctx, s.cfg.Interval); err != nil {
+			break
 		}
+	}
+}
 
-		if s.cfg.TotalBatches > 0 && batchCount >= s.cfg.TotalBatches {
+// runShaped sends messages one at a time at the gaps chosen by the profile.
+func (s *Simulator) runShaped(ctx context.Context) {
+	var interval time.Duration
+	if s.cfg.BatchSize > 0 {
+		interval = s.cfg.Interval / time.Duration(s.cfg.BatchSize)
+	}
+	// The schedule gets its own source, derived from the seed, so message
+	// content does not depend on the arrival process.
+	schedule := traffic.NewSchedule(s.cfg.Profile, interval, s.clock.Now(), rand.New(rand.NewSource(s.gen.Seed()+1)))
+	total := s.cfg.TotalBatches * s.cfg.BatchSize
+
+	for sent := 0; total <= 0 || sent < total; sent++ {
+		if err := s.clock.Sleep(ctx, schedule.Next(s.clock.Now())); err != nil {
 			break
 		}
+		s.send()
+		s.maybeFlush(false)
+	}
+}
 
-		time.Sleep(s.cfg.Interval)
+// send generates one message, injects a fault if chaos is enabled, sends it
+// and buffers it for persistence if configured.
+func (s *Simulator) send() {
+	m, dialect := s.gen.message(s.randomHostname())
+
+	fault := s.chaos.Next()
+	m.Timestamp = s.chaos.Timestamp(fault, m.Timestamp, s.prev)
+	s.prev = m.Timestamp
+	raw := s.corrupt(fault, []byte(m.Format(dialect)), m)
+	if fault != "" {
+		s.summary.Faults++
 	}
 
-	return nil
+	if err := s.out.send(append(raw, '\n')); err != nil {
+		s.summary.SendErr = err
+	}
+
+	if s.recorder != nil {
+		s.recorder.AddFault(raw, m.Priority(), m.Timestamp, fault)
+	}
+}
+
+// maybeFlush writes buffered records if force is set, flushEvery records are
+// pending, or flushInterval has passed since the last write. A failed write
+// is recorded in the summary and retried at the next flush.
+func (s *Simulator) maybeFlush(force bool) {
+	if s.recorder == nil || s.recorder.Pending() == 0 {
+		return
+	}
+	if !force && s.recorder.Pending() < flushEvery && time.Since(s.lastFlush) < flushInterval {
+		return
+	}
+	if err := s.flush(); err != nil {
+		s.summary.SaveErr = err
+	}
+}
+
+// flush writes buffered records to the persistence file.
+func (s *Simulator) flush() error {
+	if s.recorder == nil {
+		return nil
+	}
+	s.lastFlush = time.Now()
+	n, err := s.recorder.Flush()
+	s.summary.Persisted += n
+	return err
 }
 
 // RunSimulation is a convenience wrapper to quickly start a syslog simulation.
-func RunSimulation(cfg Config) error {
+func RunSimulation(ctx context.Context, cfg Config) (Summary, error) {
 	sim, err := NewSimulator(cfg)
 	if err != nil {
-		return err
+		return Summary{}, err
 	}
-	return sim.Run()
+	return sim.Run(ctx)
 }
 
 // ---------------- Helper Methods ----------------
 
-func (s *Simulator) generateSyslog() (string, int) {
-	hostname := randomHostname()
-	appName := randomAppName()
-	severity := randomSeverity()
+func (s *Simulator) randomHostname() string {
+	s.gen.mu.Lock()
+	defer s.gen.mu.Unlock()
+	return s.gen.randomHostname()
+}
+
+// Generator produces syslog messages from its own random source and clock.
+// It is safe for concurrent use.
+type Generator struct {
+	mu         sync.Mutex
+	rng        *rand.Rand
+	seed       int64
+	clock      simclock.Clock
+	severities map[string]float64 // Relative severity weights; nil leaves severities to the library
+	library    *Library           // Overrides the default libraries; nil uses them
+	headers    Headers
+	dialect    Dialect
+	hosts      map[string]*hostState
+}
 
-	pri := calcPriority(facility, severity)
-	timestamp := time.Now().UTC().Format(time.RFC3339)
-	message := randomPayload()
+// Headers selects how a Generator fills in the message header. By default
+// messages carry a PROCID per app, the template's MSGID, and [meta] and
+// [origin] structured data; timestamps are in UTC with microseconds.
+type Headers struct {
+	Minimal  bool           // Leave out PROCID, MSGID and STRUCTURED-DATA ("- - -" in RFC 5424)
+	Location *time.Location // Zone of timestamps, rendered with its offset; nil means UTC
+	BOM      bool           // Mark MSG as UTF-8 with a byte order mark
+}
 
-	syslog := fmt.Sprintf(
-		"<%d>1 %s %s %s - - - %s",
-		pri, timestamp, hostname, appName, message,
-	)
+// hostState is what a Generator remembers about a hostname, so that its
+// messages are consistent: a sequence number, a boot time for sysUpTime,
+// an origin IP and a PROCID per app.
+type hostState struct {
+	sequence int
+	boot     time.Time
+	ip       string
+	pids     map[string]int
+}
 
-	return syslog, pri
+// NewGenerator creates a Generator seeded with seed (0 picks one from the
+// clock; see Seed) that timestamps messages with clock (nil means
+// simclock.Real).
+func NewGenerator(seed int64, clock simclock.Clock) *Generator {
+	seed = simclock.Seed(seed)
+	if clock == nil {
+		clock = simclock.Real
+	}
+	return &Generator{rng: rand.New(rand.NewSource(seed)), seed: seed, clock: clock, hosts: map[string]*hostState{}}
 }
 
-func calcPriority(facility int, severity Severity) int {
-	return facility*8 + int(severity)
+// Seed returns the seed the generator was created with.
+func (g *Generator) Seed() int64 { return g.seed }
+
+// SetSeverities sets the relative weights of the severities of generated
+// messages, keyed by the names ParseSeverity accepts. Nil or empty weights
+// restore the uniform mix.
+func (g *Generator) SetSeverities(weights map[string]float64) error {
+	for name := range weights {
+		if _, err := ParseSeverity(name); err != nil {
+			return err
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.severities = weights
+	return nil
 }
 
-func randomSeverity() Severity {
-	return Severity(rand.Intn(4))
+// SetLibrary makes the generator draw all messages from lib, whatever the
+// device type. Nil restores the default libraries.
+func (g *Generator) SetLibrary(lib *Library) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.library = lib
 }
 
-func randomHostname() string {
-	devTypes := []string{"router", "switch", "server"}
-	return fmt.Sprintf("%s-%02d", devTypes[rand.Intn(len(devTypes))], rand.Intn(20)+1)
+// SetHeaders selects how the generator fills in the message header.
+func (g *Generator) SetHeaders(h Headers) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.headers = h
 }
 
-func randomAppName() string {
-	apps := []string{"syslogd", "snmpd", "sshd", "nginx", "kernel"}
-	return apps[rand.Intn(len(apps))]
+// SetDialect selects the output format of generated messages; the empty
+// dialect is RFC 5424.
+func (g *Generator) SetDialect(d Dialect) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.dialect = d
 }
 
-func randomPayload() string {
-	if rand.Intn(2) == 0 {
-		return randomNetworkPayload()
+// defaultGenerator backs the package-level functions.
+var defaultGenerator = NewGenerator(0, nil)
+
+// GenerateMessage builds a random RFC 5424 syslog message originating from
+// hostname using a shared generator. See Generator.GenerateMessage.
+func GenerateMessage(hostname string) (string, int) {
+	return defaultGenerator.GenerateMessage(hostname)
+}
+
+// GenerateDeviceMessage builds a random message typical of deviceType using
+// a shared generator. See Generator.GenerateDeviceMessage.
+func GenerateDeviceMessage(deviceType, hostname string) (string, int) {
+	return defaultGenerator.GenerateDeviceMessage(deviceType, hostname)
+}
+
+// Format renders an RFC 5424 syslog message with the current time and
+// returns it with its priority value.
+func Format(hostname, appName string, severity Severity, message string) (string, int) {
+	return defaultGenerator.Format(hostname, appName, severity, message)
+}
+
+// GenerateMessage builds a random RFC 5424 syslog message originating from
+// hostname, drawn from the network or server library, and returns it with
+// its priority value.
+func (g *Generator) GenerateMessage(hostname string) (string, int) {
+	m, dialect := g.message(hostname)
+	return m.Format(dialect), m.Priority()
+}
+
+// message builds the message GenerateMessage renders and returns it with
+// the dialect to render it in.
+func (g *Generator) message(hostname string) (Message, Dialect) {
+	g.mu.Lock()
+	lib := g.library
+	if lib == nil {
+		lib = libraries[[]string{"network", "server"}[g.rng.Intn(2)]]
+	}
+	return g.compose(lib, hostname)
+}
+
+// Format renders an RFC 5424 syslog message timestamped by the generator's
+// clock and returns it with its priority value.
+func (g *Generator) Format(hostname, appName string, severity Severity, message string) (string, int) {
+	return FormatAt(g.clock.Now(), hostname, appName, severity, message)
+}
+
+// FormatAt renders an RFC 5424 syslog message from the user facility with
+// timestamp t, in UTC, and returns it with its priority value. PROCID, MSGID
+// and STRUCTURED-DATA are left empty.
+func FormatAt(t time.Time, hostname, appName string, severity Severity, message string) (string, int) {
+	m := Message{
+		Timestamp: t.UTC(),
+		Facility:  FacilityUser,
+		Severity:  severity,
+		Hostname:  hostname,
+		AppName:   appName,
+		Text:      message,
 	}
-	return randomServerPayload()
+	return m.String(), m.Priority()
 }
 
-func randomNetworkPayload() string {
-	template := networkTemplates[rand.Intn(len(networkTemplates))]
+// GenerateDeviceMessage builds a random RFC 5424 syslog message typical of
+// deviceType ("router", "switch", "server", "firewall" or "access-point")
+// originating from hostname, drawn from the device type's default library.
+// Unknown types get the mixed messages of GenerateMessage.
+func (g *Generator) GenerateDeviceMessage(deviceType, hostname string) (string, int) {
+	name, ok := deviceLibraries[deviceType]
+	if !ok {
+		return g.GenerateMessage(hostname)
+	}
+	g.mu.Lock()
+	lib := g.library
+	if lib == nil {
+		lib = libraries[name]
+	}
+	m, dialect := g.compose(lib, hostname)
+	return m.Format(dialect), m.Priority()
+}
 
-	switch template {
-	case networkTemplates[0]:
-		return fmt.Sprintf(template, rand.Intn(48), randomState())
-	case networkTemplates[1]:
-		return fmt.Sprintf(template, randomIP(), randomState())
-	case networkTemplates[2]:
-		return fmt.Sprintf(template, rand.Intn(40)+60)
-	default:
-		return "Network event"
+// compose builds a message from lib and returns it with the generator's
+// dialect. It must be called with g.mu held, and unlocks it.
+func (g *Generator) compose(lib *Library, hostname string) (Message, Dialect) {
+	var sev *Severity
+	if len(g.severities) > 0 {
+		s, _ := ParseSeverity(traffic.PickSeverity(g.rng, g.severities))
+		sev = &s
 	}
+	t := lib.pick(g.rng, sev)
+	m := lib.render(g.rng, t, sev)
+	m.Timestamp, m.Hostname = g.clock.Now(), hostname
+	g.fillHeader(&m, t)
+	dialect := g.dialect
+	g.mu.Unlock()
+
+	return m, dialect
 }
 
-func randomServerPayload() string {
-	template := serverTemplates[rand.Intn(len(serverTemplates))]
+// fillHeader sets the header fields of m chosen by g.headers. It must be
+// called with g.mu held.
+func (g *Generator) fillHeader(m *Message, t *Template) {
+	loc := g.headers.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	m.Timestamp = m.Timestamp.In(loc)
+	m.BOM = g.headers.BOM
+
+	host := g.hosts[m.Hostname]
+	if host == nil {
+		host = &hostState{
+			boot: m.Timestamp.Add(-time.Duration(g.rng.Int63n(int64(30 * 24 * time.Hour)))),
+			ip:   g.randomIP(),
+			pids: map[string]int{},
+		}
+		g.hosts[m.Hostname] = host
+	}
+	// sequenceId wraps to 1 after 2147483647 (RFC 5424, section 7.3.1).
+	host.sequence = host.sequence%2147483647 + 1
+	m.Sequence, m.SourceIP = host.sequence, host.ip
+	if g.headers.Minimal {
+		return
+	}
 
-	switch template {
-	case serverTemplates[0]:
-		services := []string{"nginx", "postgres", "redis", "sshd"}
-		return fmt.Sprintf(template, services[rand.Intn(len(services))])
-	case serverTemplates[1]:
-		processes := []string{"java", "python", "node", "go-app"}
-		return fmt.Sprintf(template, processes[rand.Intn(len(processes))])
-	case serverTemplates[2]:
-		users := []string{"admin", "root", "guest", "developer"}
-		return fmt.Sprintf(template, users[rand.Intn(len(users))], randomIP())
-	default:
-		return "Server event"
+	pid, ok := host.pids[m.AppName]
+	if !ok {
+		pid = g.rng.Intn(65000) + 300
+		host.pids[m.AppName] = pid
+	}
+	// sysUpTime counts hundredths of a second, as in SNMP.
+	upTime := max(m.Timestamp.Sub(host.boot), 0) / (10 * time.Millisecond)
+
+	m.ProcID, m.MsgID = strconv.Itoa(pid), t.MsgID
+	m.StructuredData = []SDElement{
+		{ID: "meta", Params: []SDParam{
+			{Name: "sequenceId", Value: strconv.Itoa(host.sequence)},
+			{Name: "sysUpTime", Value: strconv.FormatInt(int64(upTime), 10)},
+		}},
+		{ID: "origin", Params: []SDParam{{Name: "ip", Value: host.ip}}},
 	}
 }
 
-func randomState() string {
-	states := []string{"up", "down", "flapping"}
-	return states[rand.Intn(len(states))]
+// The random* helpers draw from g.rng and must be called with g.mu held.
+
+func (g *Generator) randomHostname() string {
+	devTypes := []string{"router", "switch", "server"}
+	return fmt.Sprintf("%s-%02d", devTypes[g.rng.Intn(len(devTypes))], g.rng.Intn(20)+1)
 }
 
-func randomIP() string {
-	return fmt.Sprintf("10.%d.%d.%d",
-		rand.Intn(255),
-		rand.Intn(255),
-		rand.Intn(255),
-	)
+func (g *Generator) randomIP() string {
+	return fmt.Sprintf("10.%d.%d.%d", g.rng.Intn(255), g.rng.Intn(255), g.rng.Intn(254)+1)
 }
\ No newline at end of file
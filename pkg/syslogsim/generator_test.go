@@ -0,0 +1,138 @@
+package syslogsim
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+)
+
+func TestGenerator_SameSeedSameMessages(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := simclock.NewManual(start)
+	a := NewGenerator(7, clock)
+	b := NewGenerator(7, clock)
+	other := NewGenerator(8, clock)
+
+	same := true
+	for i := 0; i < 50; i++ {
+		ma, _ := a.GenerateDeviceMessage("firewall", "fw-1")
+		mb, _ := b.GenerateDeviceMessage("firewall", "fw-1")
+		mo, _ := other.GenerateDeviceMessage("firewall", "fw-1")
+		if ma != mb {
+			t.Fatalf("message %d differs:\n%s\n%s", i, ma, mb)
+		}
+		if !strings.Contains(ma, " "+clock.Now().Format(time.RFC3339)+" fw-1 ") {
+			t.Fatalf("expected timestamp from the injected clock, got %s", ma)
+		}
+		same = same && ma == mo
+		clock.Advance(time.Second)
+	}
+	if same {
+		t.Error("expected a different seed to produce different messages")
+	}
+}
+
+func TestGenerator_SeverityWeights(t *testing.T) {
+	g := NewGenerator(1, nil)
+	if err := g.SetSeverities(map[string]float64{"error": 3, "warning": 0}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		if _, pri := g.GenerateMessage("sw-1"); Severity(pri%8) != SeverityError {
+			t.Fatalf("expected severity error, got priority %d", pri)
+		}
+	}
+
+	if err := g.SetSeverities(map[string]float64{"fatal": 1}); err == nil {
+		t.Error("expected error for unknown severity")
+	}
+}
+
+func TestSimulator_RunFlushesOnCancel(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	path := filepath.Join(t.TempDir(), "syslog.json")
+	sim, err := NewSimulator(Config{
+		Host:      "127.0.0.1",
+		Port:      listener.LocalAddr().(*net.UDPAddr).Port,
+		Interval:  time.Hour,
+		BatchSize: 3,
+		FilePath:  path,
+		Seed:      1,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The first batch is sent, then the run waits an hour for the next one
+	// until it is cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	summary, err := sim.Run(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if summary != (Summary{Sent: 3, Persisted: 3}) {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var records []SyslogRecord
+	if err := json.Unmarshal(data, &records); err != nil || len(records) != 3 {
+		t.Errorf("expected 3 persisted records, got %d (%v)", len(records), err)
+	}
+}
+
+func TestSimulator_RecordsUseTheClock(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "syslog.json")
+	sim, err := NewSimulator(Config{
+		Host:      "127.0.0.1",
+		Port:      listener.LocalAddr().(*net.UDPAddr).Port,
+		Interval:  10 * time.Minute,
+		BatchSize: 1,
+		FilePath:  path,
+		Seed:      1,
+		Clock:     simclock.NewBackfill(start, start.Add(time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := sim.Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var records []SyslogRecord
+	if err := json.Unmarshal(data, &records); err != nil || len(records) == 0 {
+		t.Fatalf("expected persisted records, got %d (%v)", len(records), err)
+	}
+	for i, rec := range records {
+		if rec.Timestamp.Before(start) || rec.Timestamp.After(start.Add(time.Hour)) {
+			t.Errorf("record %d: expected a timestamp in the backfill window, got %v", i, rec.Timestamp)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package syslogsim
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
+	"gopkg.in/yaml.v2"
+)
+
+// Library is a set of weighted message templates with the facility, app
+// names and severity mix used to render them. Libraries are read from YAML:
+//
+//	facility: local7
+//	apps: [ifmgr, bgpd]
+//	severities: {info: 60, warning: 30, error: 10}
+//	values:
+//	  state: [up, down]
+//	templates:
+//	  - message: "Interface {interface} changed state to {state}"
+//	    msgid: LINK
+//	    weight: 5
+//	  - message: "CPU utilization {percent:85-100}% exceeds threshold"
+//	    severity: warning
+//	    apps: [sysmon]
+//
+// Placeholders in braces are filled in for each message. The built-in types
+// are listed in placeholderTypes; any other name must be defined under
+// values, in the template or the library, and is drawn from that list.
+// Templates may override facility, apps and severity; a template without a
+// severity draws one from the library's severities, or uniformly from info,
+// warning, error and critical if the library has none.
+type Library struct {
+	Facility   string              `yaml:"facility"`
+	Apps       []string            `yaml:"apps"`
+	Severities map[string]float64  `yaml:"severities"`
+	Values     map[string][]string `yaml:"values"`
+	Templates  []Template          `yaml:"templates"`
+
+	facility Facility
+	total    float64 // Sum of template weights
+}
+
+// Template is one message template of a Library.
+type Template struct {
+	Message  string              `yaml:"message"`
+	MsgID    string              `yaml:"msgid"`  // RFC 5424 MSGID, e.g. "LINK"; empty for none
+	Weight   float64             `yaml:"weight"` // Relative likelihood; defaults to 1
+	Severity string              `yaml:"severity"`
+	Facility string              `yaml:"facility"`
+	Apps     []string            `yaml:"apps"`
+	Values   map[string][]string `yaml:"values"`
+
+	parts    []part
+	severity *Severity
+	facility *Facility
+}
+
+// part is a literal piece of a template or a placeholder to fill in.
+type part struct {
+	literal string
+	fill    func(rng *rand.Rand) string // Nil for literals
+}
+
+//go:embed templates/*.yml
+var defaultLibraries embed.FS
+
+// libraries holds the parsed default libraries by name.
+var libraries = map[string]*Library{}
+
+func init() {
+	entries, err := defaultLibraries.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		data, err := defaultLibraries.ReadFile("templates/" + e.Name())
+		if err != nil {
+			panic(err)
+		}
+		lib, err := ParseLibrary(data)
+		if err != nil {
+			panic(fmt.Sprintf("default syslog library %s: %v", e.Name(), err))
+		}
+		libraries[strings.TrimSuffix(e.Name(), ".yml")] = lib
+	}
+}
+
+// deviceLibraries maps device types to the default library they use.
+var deviceLibraries = map[string]string{
+	"router":       "network",
+	"switch":       "network",
+	"server":       "server",
+	"firewall":     "security",
+	"access-point": "wireless",
+}
+
+// DefaultLibrary returns the built-in library called name ("network",
+// "server", "security" or "wireless").
+func DefaultLibrary(name string) (*Library, error) {
+	lib, ok := libraries[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog template library %q (built in: %s)", name, strings.Join(DefaultLibraries(), ", "))
+	}
+	return lib, nil
+}
+
+// DefaultLibraries returns the names of the built-in libraries.
+func DefaultLibraries() []string {
+	names := make([]string, 0, len(libraries))
+	for name := range libraries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadLibrary returns the built-in library called nameOrPath, or reads one
+// from the YAML file at that path.
+func LoadLibrary(nameOrPath string) (*Library, error) {
+	if lib, ok := libraries[nameOrPath]; ok {
+		return lib, nil
+	}
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read syslog template library: %w", err)
+	}
+	lib, err := ParseLibrary(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog template library %s: %w", nameOrPath, err)
+	}
+	return lib, nil
+}
+
+// ParseLibrary parses and validates a YAML template library. All problems
+// are reported together.
+func ParseLibrary(data []byte) (*Library, error) {
+	var lib Library
+	if err := yaml.UnmarshalStrict(data, &lib); err != nil {
+		return nil, err
+	}
+	if err := lib.compile(); err != nil {
+		return nil, err
+	}
+	return &lib, nil
+}
+
+// compile validates the library and prepares its templates for rendering.
+func (l *Library) compile() error {
+	var errs []error
+
+	l.facility = FacilityUser
+	if l.Facility != "" {
+		f, err := ParseFacility(l.Facility)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		l.facility = f
+	}
+	for name := range l.Severities {
+		if _, err := ParseSeverity(name); err != nil {
+			errs = append(errs, fmt.Errorf("severities: %w", err))
+		}
+	}
+	if len(l.Templates) == 0 {
+		errs = append(errs, errors.New("no templates defined"))
+	}
+
+	l.total = 0
+	for i := range l.Templates {
+		t := &l.Templates[i]
+		if err := t.compile(l); err != nil {
+			errs = append(errs, fmt.Errorf("templates[%d]: %w", i, err))
+		}
+		if t.Weight == 0 {
+			t.Weight = 1
+		}
+		if t.Weight < 0 {
+			errs = append(errs, fmt.Errorf("templates[%d]: weight must not be negative", i))
+		}
+		l.total += t.Weight
+	}
+	if len(l.Apps) == 0 {
+		for i, t := range l.Templates {
+			if len(t.Apps) == 0 {
+				errs = append(errs, fmt.Errorf("templates[%d]: no apps defined for the template or the library", i))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// placeholderPattern matches "{name}" and "{name:arg}".
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)(?::([^{}]*))?\}`)
+
+// compile splits the template into parts and resolves its overrides.
+func (t *Template) compile(l *Library) error {
+	if strings.TrimSpace(t.Message) == "" {
+		return errors.New("message is required")
+	}
+	if !validMsgID(t.MsgID) {
+		return fmt.Errorf("msgid %q must be at most 32 printable ASCII characters without spaces", t.MsgID)
+	}
+	if t.Severity != "" {
+		sev, err := ParseSeverity(t.Severity)
+		if err != nil {
+			return err
+		}
+		t.severity = &sev
+	}
+	if t.Facility != "" {
+		f, err := ParseFacility(t.Facility)
+		if err != nil {
+			return err
+		}
+		t.facility = &f
+	}
+
+	t.parts = nil
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(t.Message, -1) {
+		if m[0] > last {
+			t.parts = append(t.parts, part{literal: t.Message[last:m[0]]})
+		}
+		name, arg := t.Message[m[2]:m[3]], ""
+		if m[4] >= 0 {
+			arg = t.Message[m[4]:m[5]]
+		}
+
+		fill, err := t.placeholder(l, name, arg)
+		if err != nil {
+			return fmt.Errorf("placeholder {%s}: %w", t.Message[m[2]:m[1]-1], err)
+		}
+		t.parts = append(t.parts, part{fill: fill})
+		last = m[1]
+	}
+	if last < len(t.Message) {
+		t.parts = append(t.parts, part{literal: t.Message[last:]})
+	}
+	return nil
+}
+
+// validMsgID reports whether id is empty or a valid RFC 5424 MSGID.
+func validMsgID(id string) bool {
+	if len(id) > 32 || id == "-" {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 33 || id[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+// placeholder returns the function filling in placeholder name: a list from
+// the template's or library's values, or a built-in type.
+func (t *Template) placeholder(l *Library, name, arg string) (func(*rand.Rand) string, error) {
+	values := t.Values[name]
+	if values == nil {
+		values = l.Values[name]
+	}
+	if values != nil {
+		if len(values) == 0 {
+			return nil, errors.New("values list is empty")
+		}
+		return func(rng *rand.Rand) string { return values[rng.Intn(len(values))] }, nil
+	}
+
+	typ, ok := placeholderTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown placeholder (built in: %s; or define it under values)", strings.Join(placeholderTypeNames(), ", "))
+	}
+	return typ(arg)
+}
+
+// placeholderTypes are the built-in placeholders. Each takes the text after
+// the colon, if any, and returns the function filling it in.
+var placeholderTypes = map[string]func(arg string) (func(*rand.Rand) string, error){
+	"interface": noArg(func(rng *rand.Rand) string {
+		switch rng.Intn(6) {
+		case 0:
+			return fmt.Sprintf("TenGigabitEthernet1/1/%d", rng.Intn(4)+1)
+		case 1:
+			return fmt.Sprintf("Port-channel%d", rng.Intn(8)+1)
+		case 2:
+			return fmt.Sprintf("Vlan%d", rng.Intn(400)+1)
+		default:
+			return fmt.Sprintf("GigabitEthernet1/0/%d", rng.Intn(48)+1)
+		}
+	}),
+	"ip": noArg(func(rng *rand.Rand) string {
+		return fmt.Sprintf("10.%d.%d.%d", rng.Intn(255), rng.Intn(255), rng.Intn(254)+1)
+	}),
+	"public_ip": noArg(func(rng *rand.Rand) string {
+		nets := []string{"192.0.2", "198.51.100", "203.0.113"}
+		return fmt.Sprintf("%s.%d", nets[rng.Intn(len(nets))], rng.Intn(254)+1)
+	}),
+	"user": noArg(func(rng *rand.Rand) string {
+		users := []string{"admin", "root", "guest", "developer", "backup", "vpnuser", "svc-deploy", "jdoe", "asmith", "oracle"}
+		return users[rng.Intn(len(users))]
+	}),
+	"host": noArg(func(rng *rand.Rand) string {
+		types := []string{"router", "switch", "server", "fw", "ap"}
+		return fmt.Sprintf("%s-%02d", types[rng.Intn(len(types))], rng.Intn(20)+1)
+	}),
+	"mac": noArg(func(rng *rand.Rand) string {
+		return fmt.Sprintf("02:%02x:%02x:%02x:%02x:%02x",
+			rng.Intn(256), rng.Intn(256), rng.Intn(256), rng.Intn(256), rng.Intn(256))
+	}),
+	"percent": intRange(0, 100),
+	"int":     intRange(0, -1),
+	"port":    intRange(1024, 65535),
+	"vlan":    intRange(1, 4094),
+}
+
+func placeholderTypeNames() []string {
+	names := make([]string, 0, len(placeholderTypes))
+	for name := range placeholderTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// noArg wraps a placeholder that takes no argument.
+func noArg(fill func(*rand.Rand) string) func(string) (func(*rand.Rand) string, error) {
+	return func(arg string) (func(*rand.Rand) string, error) {
+		if arg != "" {
+			return nil, errors.New("takes no argument")
+		}
+		return fill, nil
+	}
+}
+
+// intRange returns a placeholder drawing an integer from an inclusive
+// "MIN-MAX" argument, or from lo to hi without one. A negative hi makes the
+// argument required.
+func intRange(lo, hi int) func(string) (func(*rand.Rand) string, error) {
+	return func(arg string) (func(*rand.Rand) string, error) {
+		from, to := lo, hi
+		if arg != "" {
+			a, b, ok := strings.Cut(arg, "-")
+			var errA, errB error
+			from, errA = strconv.Atoi(a)
+			to, errB = strconv.Atoi(b)
+			if !ok || errA != nil || errB != nil || to < from {
+				return nil, fmt.Errorf("argument must be MIN-MAX, got %q", arg)
+			}
+		} else if hi < 0 {
+			return nil, errors.New("requires a MIN-MAX argument")
+		}
+		return func(rng *rand.Rand) string { return strconv.Itoa(from + rng.Intn(to-from+1)) }, nil
+	}
+}
+
+// pick draws a template by weight. If sev is set, templates with another
+// fixed severity are skipped, unless none are left.
+func (l *Library) pick(rng *rand.Rand, sev *Severity) *Template {
+	candidates, total := l.Templates, l.total
+	if sev != nil {
+		var matched []Template
+		total = 0
+		for _, t := range l.Templates {
+			if t.severity == nil || *t.severity == *sev {
+				matched = append(matched, t)
+				total += t.Weight
+			}
+		}
+		if len(matched) > 0 {
+			candidates = matched
+		} else {
+			total = l.total
+		}
+	}
+
+	x := rng.Float64() * total
+	for i := range candidates {
+		if x < candidates[i].Weight {
+			return &candidates[i]
+		}
+		x -= candidates[i].Weight
+	}
+	return &candidates[len(candidates)-1]
+}
+
+// render fills in t and returns the message without timestamp and hostname.
+// sev, if set, overrides the severity of the template and library.
+func (l *Library) render(rng *rand.Rand, t *Template, sev *Severity) Message {
+	var b strings.Builder
+	for _, p := range t.parts {
+		if p.fill == nil {
+			b.WriteString(p.literal)
+		} else {
+			b.WriteString(p.fill(rng))
+		}
+	}
+
+	m := Message{Facility: l.facility, Text: b.String()}
+	if t.facility != nil {
+		m.Facility = *t.facility
+	}
+
+	apps := t.Apps
+	if len(apps) == 0 {
+		apps = l.Apps
+	}
+	m.AppName = apps[rng.Intn(len(apps))]
+
+	switch {
+	case sev != nil:
+		m.Severity = *sev
+	case t.severity != nil:
+		m.Severity = *t.severity
+	case len(l.Severities) > 0:
+		m.Severity, _ = ParseSeverity(traffic.PickSeverity(rng, l.Severities))
+	default:
+		m.Severity = defaultSeverities[rng.Intn(len(defaultSeverities))]
+	}
+	return m
+}
+
+// defaultSeverities is the uniform mix used when neither the template nor
+// the library chooses a severity.
+var defaultSeverities = []Severity{SeverityInfo, SeverityWarning, SeverityError, SeverityCritical}
@@ -0,0 +1,119 @@
+package syslogsim
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+)
+
+func TestParseLibrary(t *testing.T) {
+	lib, err := ParseLibrary([]byte(`
+facility: local3
+apps: [edge]
+values:
+  state: [up]
+templates:
+  - message: "{interface} is {state}, load {percent:90-90}% on vlan {int:7-7}"
+    severity: notice
+  - message: "login by {user}"
+    weight: 0
+    facility: authpriv
+    apps: [sshd]
+  - message: "count {int:3-3}"
+`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	m := lib.render(rng, &lib.Templates[0], nil)
+	if m.Facility != FacilityLocal0+3 || m.Severity != SeverityNotice || m.AppName != "edge" {
+		t.Errorf("unexpected message: %+v", m)
+	}
+	if !strings.HasSuffix(m.Text, " is up, load 90% on vlan 7") {
+		t.Errorf("unexpected text: %q", m.Text)
+	}
+
+	m = lib.render(rng, &lib.Templates[1], nil)
+	if m.Facility != FacilityAuthPriv || m.AppName != "sshd" || !strings.HasPrefix(m.Text, "login by ") {
+		t.Errorf("unexpected message: %+v", m)
+	}
+	if lib.Templates[1].Weight != 1 {
+		t.Errorf("expected default weight 1, got %v", lib.Templates[1].Weight)
+	}
+}
+
+func TestParseLibrary_Errors(t *testing.T) {
+	_, err := ParseLibrary([]byte(`
+facility: local9
+severities: {loud: 1}
+templates:
+  - message: "{nosuch} {int} {percent:9} {ip:x}"
+  - message: "ok"
+    severity: fatal
+`))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{
+		`unknown syslog facility "local9"`,
+		`unknown syslog severity "loud"`,
+		"placeholder {nosuch}: unknown placeholder",
+		`unknown syslog severity "fatal"`,
+		"no apps defined",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %v", want, err)
+		}
+	}
+
+	if _, err := ParseLibrary([]byte("templates: []\napps: [a]")); err == nil || !strings.Contains(err.Error(), "no templates") {
+		t.Errorf("expected error for an empty library, got %v", err)
+	}
+}
+
+func TestGenerator_DefaultLibraries(t *testing.T) {
+	g := NewGenerator(3, simclock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	for _, deviceType := range []string{"router", "switch", "server", "firewall", "access-point", "printer"} {
+		for i := 0; i < 200; i++ {
+			msg, pri := g.GenerateDeviceMessage(deviceType, "host-1")
+			if strings.ContainsAny(msg, "{}") {
+				t.Fatalf("unfilled placeholder in %s message: %s", deviceType, msg)
+			}
+			if pri < 0 || pri > 191 || !strings.HasPrefix(msg, "<") {
+				t.Fatalf("unexpected %s message: %s", deviceType, msg)
+			}
+		}
+	}
+}
+
+func TestGenerator_SetLibrary(t *testing.T) {
+	lib, err := ParseLibrary([]byte(`
+facility: local0
+apps: [custom]
+templates:
+  - message: "fixed critical"
+    severity: critical
+  - message: "free"
+`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	g := NewGenerator(1, nil)
+	g.SetLibrary(lib)
+	g.SetHeaders(Headers{Minimal: true})
+	if err := g.SetSeverities(map[string]float64{"debug": 1}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		msg, pri := g.GenerateDeviceMessage("router", "r1")
+		// Profile severities skip templates with another fixed severity.
+		if pri != int(FacilityLocal0)*8+int(SeverityDebug) || !strings.HasSuffix(msg, " custom - - - free") {
+			t.Fatalf("unexpected message %d: %s", pri, msg)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package syslogsim
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoadConfig configures a load test: many concurrent senders replaying a
+// pool of pre-generated messages as fast as possible or at a fixed total
+// rate. Nothing is persisted, and message timestamps are those of the pool,
+// fixed when it is generated.
+type LoadConfig struct {
+	Host     string
+	Port     int
+	Protocol string // "udp" (default) or "tcp"
+
+	Senders  int           // Concurrent connections; defaults to 8
+	Rate     float64       // Target messages per second across all senders; 0 = unlimited
+	Duration time.Duration // Stop after this long; 0 = until Total or cancellation
+	Total    int64         // Stop after this many messages; 0 = unlimited
+	PoolSize int           // Distinct pre-generated messages; defaults to 10000
+	Batch    int           // Messages per write over TCP; defaults to 64 (UDP sends one per datagram)
+	Seed     int64         // Random seed for the pool; 0 picks one from the clock
+	Library  *Library      // Templates for the pool; nil mixes the network and server libraries
+	Headers  Headers       // How the pool's message headers are filled in
+	Dialect  Dialect       // Output format of the pool; empty means RFC 5424
+
+	ReportEvery time.Duration // Interval between live reports; defaults to 1s
+}
+
+// LoadReport summarizes a load test or one reporting window of it.
+type LoadReport struct {
+	Elapsed time.Duration // Since the test started
+	Sent    int64         // Messages written, since the start
+	Errors  int64         // Failed writes, since the start
+	Rate    float64       // Messages per second over the window
+
+	// Write latency percentiles over the window. Over TCP a write carries a
+	// whole batch.
+	P50, P90, P99, P999 time.Duration
+}
+
+// String formats the report as one line for live output.
+func (r LoadReport) String() string {
+	return fmt.Sprintf("%7.1fs  sent=%d  rate=%.0f/s  errors=%d  p50=%v p90=%v p99=%v p99.9=%v",
+		r.Elapsed.Seconds(), r.Sent, r.Rate, r.Errors, r.P50, r.P90, r.P99, r.P999)
+}
+
+// RunLoad runs a load test until ctx is done, cfg.Duration passes or
+// cfg.Total messages are sent, calling report (if non-nil) with the stats of
+// each ReportEvery window. It returns the stats of the whole run. Connection
+// failures at the start are returned as errors; write failures during the
+// run are counted, and a TCP sender whose connection breaks stops.
+func RunLoad(ctx context.Context, cfg LoadConfig, report func(LoadReport)) (LoadReport, error) {
+	cfg = cfg.withDefaults()
+	if cfg.Protocol != "udp" && cfg.Protocol != "tcp" {
+		return LoadReport{}, fmt.Errorf("unsupported protocol %q", cfg.Protocol)
+	}
+
+	pool := newPool(cfg.PoolSize, cfg.Seed, cfg.Library, cfg.Headers, cfg.Dialect)
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	conns := make([]net.Conn, 0, cfg.Senders)
+	defer func() {
+		for _, c := range conns {
+			c.Close()
+		}
+	}()
+	for i := 0; i < cfg.Senders; i++ {
+		conn, err := net.Dial(cfg.Protocol, addr)
+		if err != nil {
+			return LoadReport{}, fmt.Errorf("failed to connect sender %d to %s: %w", i, addr, err)
+		}
+		conns = append(conns, conn)
+	}
+
+	if cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	run := &loadRun{cfg: cfg, pool: pool, start: time.Now()}
+	if cfg.Total > 0 {
+		run.remaining.Store(cfg.Total)
+	}
+
+	var wg sync.WaitGroup
+	for i, conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run.send(ctx, conn, i)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	if report == nil {
+		<-done
+	} else {
+		ticker := time.NewTicker(cfg.ReportEvery)
+		defer ticker.Stop()
+		prev, prevAt := run.latency.snapshot(), run.start
+		prevSent := int64(0)
+	loop:
+		for {
+			select {
+			case <-done:
+				break loop
+			case now := <-ticker.C:
+				cur := run.latency.snapshot()
+				r := run.report(now, cur.sub(prev))
+				r.Rate = float64(r.Sent-prevSent) / now.Sub(prevAt).Seconds()
+				report(r)
+				prev, prevAt, prevSent = cur, now, r.Sent
+			}
+		}
+	}
+
+	return run.report(time.Now(), run.latency.snapshot()), nil
+}
+
+func (c LoadConfig) withDefaults() LoadConfig {
+	if c.Protocol == "" {
+		c.Protocol = "udp"
+	}
+	if c.Senders <= 0 {
+		c.Senders = 8
+	}
+	if c.PoolSize <= 0 {
+		c.PoolSize = 10000
+	}
+	if c.Batch <= 0 {
+		c.Batch = 64
+	}
+	if c.Protocol == "udp" {
+		c.Batch = 1
+	}
+	if c.ReportEvery <= 0 {
+		c.ReportEvery = time.Second
+	}
+	return c
+}
+
+// newPool pre-generates n newline-terminated messages from lib, or from the
+// default libraries if lib is nil.
+func newPool(n int, seed int64, lib *Library, headers Headers, dialect Dialect) [][]byte {
+	gen := NewGenerator(seed, nil)
+	gen.SetLibrary(lib)
+	gen.SetHeaders(headers)
+	gen.SetDialect(dialect)
+	pool := make([][]byte, n)
+	for i := range pool {
+		gen.mu.Lock()
+		hostname := gen.randomHostname()
+		gen.mu.Unlock()
+		msg, _ := gen.GenerateMessage(hostname)
+		pool[i] = []byte(msg + "\n")
+	}
+	return pool
+}
+
+// loadRun is the shared state of a running load test.
+type loadRun struct {
+	cfg       LoadConfig
+	pool      [][]byte
+	start     time.Time
+	remaining atomic.Int64 // Messages left to send when cfg.Total is set
+	sent      atomic.Int64
+	errors    atomic.Int64
+	latency   histogram
+}
+
+// send is the loop of one sender.
+func (l *loadRun) send(ctx context.Context, conn net.Conn, id int) {
+	perSender := l.cfg.Rate / float64(l.cfg.Senders)
+	next := id * len(l.pool) / l.cfg.Senders // Senders start at different points of the pool
+	buf := make([]byte, 0, 64*1024)
+	var attempted int64
+
+	for ctx.Err() == nil {
+		n := l.claim()
+		if n == 0 {
+			return
+		}
+
+		if perSender > 0 {
+			due := l.start.Add(time.Duration(float64(attempted) / perSender * float64(time.Second)))
+			if wait := time.Until(due); wait > time.Millisecond {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+			}
+		}
+
+		buf = buf[:0]
+		for i := 0; i < n; i++ {
+			buf = append(buf, l.pool[next]...)
+			next = (next + 1) % len(l.pool)
+		}
+
+		began := time.Now()
+		_, err := conn.Write(buf)
+		l.latency.record(time.Since(began))
+		attempted += int64(n)
+		if err != nil {
+			l.errors.Add(int64(n))
+			if l.cfg.Protocol == "tcp" {
+				return
+			}
+			continue
+		}
+		l.sent.Add(int64(n))
+	}
+}
+
+// claim reserves up to one batch of messages to send, returning 0 once
+// cfg.Total messages have been claimed.
+func (l *loadRun) claim() int {
+	if l.cfg.Total <= 0 {
+		return l.cfg.Batch
+	}
+	left := l.remaining.Add(-int64(l.cfg.Batch)) + int64(l.cfg.Batch)
+	if left <= 0 {
+		return 0
+	}
+	return int(min(left, int64(l.cfg.Batch)))
+}
+
+// report builds a report at now from the latency counts of a window, with
+// Rate averaged over the whole run.
+func (l *loadRun) report(now time.Time, window histogramSnapshot) LoadReport {
+	elapsed := now.Sub(l.start)
+	sent := l.sent.Load()
+	return LoadReport{
+		Elapsed: elapsed,
+		Sent:    sent,
+		Errors:  l.errors.Load(),
+		Rate:    float64(sent) / elapsed.Seconds(),
+		P50:     window.quantile(0.5),
+		P90:     window.quantile(0.9),
+		P99:     window.quantile(0.99),
+		P999:    window.quantile(0.999),
+	}
+}
+
+// ---------------- Latency histogram ----------------
+
+// subBuckets is the number of buckets per power of two, bounding the
+// relative error of a percentile to 1/subBuckets.
+const subBuckets = 8
+
+// histogram counts durations in log-linear buckets. It is safe for
+// concurrent use.
+type histogram struct {
+	counts [64 * subBuckets]atomic.Int64
+}
+
+type histogramSnapshot []int64
+
+func (h *histogram) record(d time.Duration) {
+	h.counts[bucketOf(int64(d))].Add(1)
+}
+
+func (h *histogram) snapshot() histogramSnapshot {
+	s := make(histogramSnapshot, len(h.counts))
+	for i := range h.counts {
+		s[i] = h.counts[i].Load()
+	}
+	return s
+}
+
+// sub returns the counts recorded between prev and s.
+func (s histogramSnapshot) sub(prev histogramSnapshot) histogramSnapshot {
+	out := make(histogramSnapshot, len(s))
+	for i := range s {
+		out[i] = s[i] - prev[i]
+	}
+	return out
+}
+
+// quantile returns the lower bound of the bucket holding quantile q, or 0 if
+// nothing was recorded.
+func (s histogramSnapshot) quantile(q float64) time.Duration {
+	var total int64
+	for _, c := range s {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+	target := int64(math.Ceil(q * float64(total)))
+	var seen int64
+	for i, c := range s {
+		if seen += c; seen >= target {
+			return time.Duration(bucketValue(i))
+		}
+	}
+	return time.Duration(bucketValue(len(s) - 1))
+}
+
+// bucketOf returns the bucket of ns: values below subBuckets get their own
+// bucket, larger ones share each power of two among subBuckets buckets.
+func bucketOf(ns int64) int {
+	if ns < subBuckets {
+		return int(max(ns, 0))
+	}
+	e := bits.Len64(uint64(ns)) - 1
+	return e*subBuckets + int(ns>>(e-3))&(subBuckets-1)
+}
+
+// bucketValue returns the smallest value in bucket i.
+func bucketValue(i int) int64 {
+	if i < subBuckets {
+		return int64(i)
+	}
+	e, sub := i/subBuckets, int64(i%subBuckets)
+	return (subBuckets + sub) << (e - 3)
+}
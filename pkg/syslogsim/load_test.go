@@ -0,0 +1,108 @@
+package syslogsim
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRunLoad_TCPDeliversTotal(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	// Count lines across the four sender connections.
+	lines := make(chan int, 4)
+	go func() {
+		for i := 0; i < 4; i++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				n := 0
+				for sc := bufio.NewScanner(conn); sc.Scan(); n++ {
+				}
+				lines <- n
+			}()
+		}
+	}()
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	cfg := LoadConfig{Host: "127.0.0.1", Port: port, Protocol: "tcp", Senders: 4, Total: 10000, PoolSize: 100, Batch: 32, Seed: 1}
+	report, err := RunLoad(context.Background(), cfg, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.Sent != 10000 || report.Errors != 0 {
+		t.Errorf("expected 10000 sent and no errors, got %+v", report)
+	}
+
+	received := 0
+	for i := 0; i < 4; i++ {
+		select {
+		case n := <-lines:
+			received += n
+		case <-time.After(5 * time.Second):
+			t.Fatal("listener did not finish")
+		}
+	}
+	if received != 10000 {
+		t.Errorf("listener received %d messages, want 10000", received)
+	}
+}
+
+func TestRunLoad_RateAndReports(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer conn.Close()
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			if _, _, err := conn.ReadFrom(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	cfg := LoadConfig{Host: "127.0.0.1", Port: port, Senders: 2, Rate: 2000, Duration: 500 * time.Millisecond, ReportEvery: 100 * time.Millisecond}
+
+	var reports []LoadReport
+	final, err := RunLoad(context.Background(), cfg, func(r LoadReport) { reports = append(reports, r) })
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if final.Sent < 600 || final.Sent > 1200 {
+		t.Errorf("expected about 1000 messages at 2000/s for 0.5s, got %d", final.Sent)
+	}
+	if len(reports) < 3 {
+		t.Errorf("expected live reports every 100ms, got %d", len(reports))
+	}
+	if final.P50 <= 0 || final.P99 < final.P50 {
+		t.Errorf("unexpected latency percentiles: %+v", final)
+	}
+}
+
+func TestHistogram_Quantiles(t *testing.T) {
+	var h histogram
+	for i := 1; i <= 1000; i++ {
+		h.record(time.Duration(i) * time.Microsecond)
+	}
+	s := h.snapshot()
+	for _, tt := range []struct {
+		q    float64
+		want time.Duration
+	}{{0.5, 500 * time.Microsecond}, {0.99, 990 * time.Microsecond}} {
+		got := s.quantile(tt.q)
+		if got > tt.want || got < tt.want*7/8 {
+			t.Errorf("quantile %g: got %v, want within 12.5%% below %v", tt.q, got, tt.want)
+		}
+	}
+}
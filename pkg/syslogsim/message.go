@@ -0,0 +1,188 @@
+package syslogsim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity represents syslog severity levels per RFC 5424.
+type Severity int
+
+const (
+	SeverityEmergency Severity = iota // System is unusable
+	SeverityAlert                     // Action must be taken immediately
+	SeverityCritical                  // Critical condition requiring immediate action
+	SeverityError                     // Error condition
+	SeverityWarning                   // Warning condition
+	SeverityNotice                    // Normal but significant condition
+	SeverityInfo                      // Informational message
+	SeverityDebug                     // Debug-level message
+)
+
+// severityNames are the canonical names of the severities, by value.
+var severityNames = [...]string{"emergency", "alert", "critical", "error", "warning", "notice", "info", "debug"}
+
+// severityAliases are the other names ParseSeverity accepts, including the
+// keywords of syslog.conf.
+var severityAliases = map[string]Severity{
+	"emerg":         SeverityEmergency,
+	"panic":         SeverityEmergency,
+	"crit":          SeverityCritical,
+	"err":           SeverityError,
+	"warn":          SeverityWarning,
+	"informational": SeverityInfo,
+}
+
+// String returns the severity's canonical name, e.g. "warning".
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return strconv.Itoa(int(s))
+}
+
+// ParseSeverity returns the severity called name: one of "emergency",
+// "alert", "critical", "error", "warning", "notice", "info" and "debug", a
+// syslog.conf keyword such as "crit" or "warn", or a number from 0 to 7.
+func ParseSeverity(name string) (Severity, error) {
+	name = strings.ToLower(name)
+	for i, n := range severityNames {
+		if n == name {
+			return Severity(i), nil
+		}
+	}
+	if sev, ok := severityAliases[name]; ok {
+		return sev, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 0 && n < len(severityNames) {
+		return Severity(n), nil
+	}
+	return 0, fmt.Errorf("unknown syslog severity %q", name)
+}
+
+// Facility is a syslog facility code per RFC 5424.
+type Facility int
+
+const (
+	FacilityKern     Facility = 0
+	FacilityUser     Facility = 1
+	FacilityDaemon   Facility = 3
+	FacilityAuth     Facility = 4
+	FacilitySyslog   Facility = 5
+	FacilityAuthPriv Facility = 10
+	FacilityLocal0   Facility = 16
+	FacilityLocal7   Facility = 23
+)
+
+// facilityNames are the syslog.conf names of the facilities, by code.
+var facilityNames = [...]string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// String returns the facility's syslog.conf name, e.g. "local7".
+func (f Facility) String() string {
+	if f >= 0 && int(f) < len(facilityNames) {
+		return facilityNames[f]
+	}
+	return strconv.Itoa(int(f))
+}
+
+// ParseFacility returns the facility called name, such as "daemon" or
+// "local7", or numbered from 0 to 23.
+func ParseFacility(name string) (Facility, error) {
+	name = strings.ToLower(name)
+	for i, n := range facilityNames {
+		if n == name {
+			return Facility(i), nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 0 && n < len(facilityNames) {
+		return Facility(n), nil
+	}
+	return 0, fmt.Errorf("unknown syslog facility %q", name)
+}
+
+// Message is one syslog message before it is rendered. Empty header fields
+// are rendered as the RFC 5424 NILVALUE "-".
+type Message struct {
+	Timestamp      time.Time // Rendered in its own location, with that offset
+	Facility       Facility
+	Severity       Severity
+	Hostname       string
+	AppName        string
+	ProcID         string
+	MsgID          string
+	StructuredData []SDElement
+	Text           string // MSG
+	BOM            bool   // Prefix MSG with the UTF-8 byte order mark
+
+	// Sequence numbers the host's messages and SourceIP is its address, for
+	// dialects that show them (see Format).
+	Sequence int
+	SourceIP string
+}
+
+// SDElement is an RFC 5424 structured-data element, such as
+// [meta sequenceId="1"].
+type SDElement struct {
+	ID     string
+	Params []SDParam
+}
+
+// SDParam is a parameter of an SDElement.
+type SDParam struct {
+	Name, Value string
+}
+
+// bom is the UTF-8 byte order mark RFC 5424 puts before UTF-8 messages.
+const bom = "\ufeff"
+
+// timestampLayout is the RFC 5424 TIMESTAMP: RFC 3339 with at most six
+// fractional digits, trailing zeros dropped.
+const timestampLayout = "2006-01-02T15:04:05.999999Z07:00"
+
+// Priority returns the PRI value, facility*8 + severity.
+func (m Message) Priority() int {
+	return int(m.Facility)*8 + int(m.Severity)
+}
+
+// String renders the message in RFC 5424 format. See Format for other
+// dialects.
+func (m Message) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s ",
+		m.Priority(), m.Timestamp.Truncate(time.Microsecond).Format(timestampLayout),
+		nilValue(m.Hostname), nilValue(m.AppName), nilValue(m.ProcID), nilValue(m.MsgID))
+
+	if len(m.StructuredData) == 0 {
+		b.WriteByte('-')
+	}
+	for _, e := range m.StructuredData {
+		b.WriteString("[" + e.ID)
+		for _, p := range e.Params {
+			b.WriteString(" " + p.Name + `="` + sdEscaper.Replace(p.Value) + `"`)
+		}
+		b.WriteByte(']')
+	}
+
+	b.WriteByte(' ')
+	if m.BOM {
+		b.WriteString(bom)
+	}
+	b.WriteString(m.Text)
+	return b.String()
+}
+
+// sdEscaper escapes the characters RFC 5424 requires escaped in PARAM-VALUE.
+var sdEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, "]", `\]`)
+
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package syslogsim
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+)
+
+func TestMessage_String(t *testing.T) {
+	zone := time.FixedZone("", -5*3600)
+	m := Message{
+		Timestamp: time.Date(2024, 3, 1, 9, 30, 0, 123456789, zone),
+		Facility:  FacilityLocal7,
+		Severity:  SeverityNotice,
+		Hostname:  "rtr-1",
+		AppName:   "bgpd",
+		ProcID:    "812",
+		MsgID:     "ADJCHANGE",
+		StructuredData: []SDElement{
+			{ID: "meta", Params: []SDParam{{Name: "sequenceId", Value: "7"}}},
+			{ID: "x@32473", Params: []SDParam{{Name: "note", Value: `a "b" \c]`}}},
+		},
+		Text: "neighbor up",
+		BOM:  true,
+	}
+	want := `<189>1 2024-03-01T09:30:00.123456-05:00 rtr-1 bgpd 812 ADJCHANGE [meta sequenceId="7"][x@32473 note="a \"b\" \\c\]"] ` + "\ufeffneighbor up"
+	if got := m.String(); got != want {
+		t.Errorf("unexpected message:\n got %s\nwant %s", got, want)
+	}
+
+	m = Message{Timestamp: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC), Text: "bare"}
+	if got := m.String(); got != "<0>1 2024-03-01T09:30:00Z - - - - - bare" {
+		t.Errorf("unexpected message: %s", got)
+	}
+}
+
+func TestGenerator_Headers(t *testing.T) {
+	clock := simclock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	g := NewGenerator(4, clock)
+	zone := time.FixedZone("", 2*3600)
+	g.SetHeaders(Headers{Location: zone})
+
+	header := regexp.MustCompile(`^<\d+>1 (\S+) (\S+) (\S+) (\d+) (\S+) \[meta sequenceId="(\d+)" sysUpTime="(\d+)"\]\[origin ip="([\d.]+)"\] [^\x{feff}]`)
+	pids := map[string]string{}
+	origin := map[string]string{}
+	seq := map[string]int{}
+	upTimes := map[string]int{}
+	for i := 0; i < 40; i++ {
+		host := []string{"a", "b"}[i%2]
+		msg, _ := g.GenerateDeviceMessage("router", host)
+		f := header.FindStringSubmatch(msg)
+		if f == nil {
+			t.Fatalf("unexpected header: %s", msg)
+		}
+		if f[1] != clock.Now().In(zone).Format("2006-01-02T15:04:05-07:00") {
+			t.Errorf("expected timestamp in the configured zone, got %s", f[1])
+		}
+		if f[5] == "-" {
+			t.Errorf("expected a MSGID from the template, got %s", msg)
+		}
+		if pid, ok := pids[host+f[3]]; ok && pid != f[4] {
+			t.Errorf("expected a stable PROCID for %s on %s, got %s and %s", f[3], host, pid, f[4])
+		}
+		pids[host+f[3]] = f[4]
+		if ip, ok := origin[host]; ok && ip != f[8] {
+			t.Errorf("expected a stable origin IP for %s, got %s and %s", host, ip, f[8])
+		}
+		origin[host] = f[8]
+		seq[host]++
+		if f[6] != strconv.Itoa(seq[host]) {
+			t.Errorf("expected sequenceId %d for %s, got %s", seq[host], host, f[6])
+		}
+		upTime, _ := strconv.Atoi(f[7])
+		if last, ok := upTimes[host]; ok && upTime != last+200 {
+			t.Errorf("expected sysUpTime to grow by 200 between messages from %s, got %d and %d", host, last, upTime)
+		}
+		upTimes[host] = upTime
+		clock.Advance(time.Second)
+	}
+
+	g.SetHeaders(Headers{Minimal: true, BOM: true})
+	msg, _ := g.GenerateDeviceMessage("server", "a")
+	if !strings.Contains(msg, " - - - \ufeff") {
+		t.Errorf("expected minimal header and BOM, got %q", msg)
+	}
+}
@@ -7,13 +7,24 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+	"unicode/utf8"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/chaos"
+	"github.com/ibm-live-project-interns/datasource/pkg/jsonarray"
 )
 
 // SyslogRecord represents a single persisted syslog event.
 type SyslogRecord struct {
 	Raw       string    `json:"raw"`       // The full RFC 5424 formatted syslog message
 	Priority  int       `json:"priority"`  // Calculated priority (facility * 8 + severity)
-	Timestamp time.Time `json:"timestamp"` // When the record was persisted (UTC)
+	Timestamp time.Time `json:"timestamp"` // The message's timestamp, on the simulator's clock (UTC)
+
+	// Fault names the chaos fault injected into the message, if any. An
+	// invalid_pri message keeps the Priority it was generated with.
+	Fault chaos.Fault `json:"fault,omitempty"`
+	// RawBytes holds the message as sent when it is not valid UTF-8, which
+	// Raw cannot represent exactly.
+	RawBytes []byte `json:"raw_bytes,omitempty"`
 }
 
 // fileLock guards concurrent access to the syslog persistence file.
@@ -21,12 +32,13 @@ type SyslogRecord struct {
 // For multi-file persistence, consider a per-path lock map.
 var fileLock sync.Mutex
 
-// SaveSyslogToFile appends a syslog record to the JSON array at the given path.
+// SaveSyslogToFile appends a record of a syslog message timestamped at to
+// the JSON array at the given path.
 //
 // NOTE: Same O(N^2) pattern as snmptrap/store.go — reads all records,
 // appends one, writes everything back. For production use, consider JSON Lines
 // (one JSON object per line) with append-only writes for O(1) per insert.
-func SaveSyslogToFile(path string, raw string, priority int) error {
+func SaveSyslogToFile(path string, raw string, priority int, at time.Time) error {
 	fileLock.Lock()
 	defer fileLock.Unlock()
 
@@ -50,7 +62,7 @@ func SaveSyslogToFile(path string, raw string, priority int) error {
 	records = append(records, SyslogRecord{
 		Raw:       raw,
 		Priority:  priority,
-		Timestamp: time.Now().UTC(),
+		Timestamp: at.UTC(),
 	})
 
 	// Write back to file
@@ -61,3 +73,73 @@ func SaveSyslogToFile(path string, raw string, priority int) error {
 
 	return os.WriteFile(path, out, 0644)
 }
+
+// Recorder buffers syslog records in memory and appends them to a JSON array
+// file on Flush. Only records added since the last Flush are kept and
+// written, so memory and the cost of a flush do not grow with the file. It
+// is not safe for concurrent use.
+type Recorder struct {
+	path    string
+	pending []SyslogRecord
+	written int // Records already in the file
+}
+
+// NewRecorder returns a Recorder appending to the JSON array at path, which
+// may not exist yet.
+func NewRecorder(path string) (*Recorder, error) {
+	written, err := jsonarray.Len(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read syslog file %s: %w", path, err)
+	}
+	return &Recorder{path: path, written: written}, nil
+}
+
+// Add buffers a record of a message timestamped at until the next Flush.
+func (r *Recorder) Add(raw string, priority int, at time.Time) {
+	r.AddFault([]byte(raw), priority, at, "")
+}
+
+// AddFault buffers a record of a message timestamped at with an injected
+// fault, or none if fault is empty, until the next Flush.
+func (r *Recorder) AddFault(raw []byte, priority int, at time.Time, fault chaos.Fault) {
+	rec := SyslogRecord{
+		Raw:       string(raw),
+		Priority:  priority,
+		Timestamp: at.UTC(),
+		Fault:     fault,
+	}
+	if !utf8.Valid(raw) {
+		rec.RawBytes = raw
+	}
+	r.pending = append(r.pending, rec)
+}
+
+// Pending returns the number of records added since the last Flush.
+func (r *Recorder) Pending() int { return len(r.pending) }
+
+// Flush writes the pending records to the file and returns how many were
+// persisted. The first flush into an empty file creates it atomically; later
+// flushes append in place (see jsonarray.Append). A failed append is rolled
+// back and its records stay pending.
+func (r *Recorder) Flush() (int, error) {
+	pending := len(r.pending)
+	if pending == 0 {
+		return 0, nil
+	}
+
+	fileLock.Lock()
+	defer fileLock.Unlock()
+
+	var err error
+	if r.written == 0 {
+		err = jsonarray.Create(r.path, r.pending)
+	} else {
+		err = jsonarray.Append(r.path, r.pending)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write syslog file %s: %w", r.path, err)
+	}
+	r.written += pending
+	r.pending = r.pending[:0]
+	return pending, nil
+}
@@ -0,0 +1,71 @@
+package syslogsim
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecorder_AppendsAcrossFlushes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "syslog.json")
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := SaveSyslogToFile(path, "<14>1 first", 14, at); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	r, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for flush := 0; flush < 3; flush++ {
+		for i := 0; i < 2; i++ {
+			r.Add("<11>1 next", 11, at)
+		}
+		if n, err := r.Flush(); n != 2 || err != nil {
+			t.Fatalf("flush %d: expected 2 records, got %d (%v)", flush, n, err)
+		}
+		if r.Pending() != 0 || len(r.pending) != 0 {
+			t.Fatalf("flush %d: expected flushed records released, %d kept", flush, len(r.pending))
+		}
+	}
+	want := []SyslogRecord{{Raw: "<14>1 first", Priority: 14, Timestamp: at}}
+	for i := 0; i < 6; i++ {
+		want = append(want, SyslogRecord{Raw: "<11>1 next", Priority: 11, Timestamp: at})
+	}
+
+	// Appending keeps the layout of a file written in one go.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	expected, _ := json.MarshalIndent(want, "", "  ")
+	if string(data) != string(expected) {
+		t.Errorf("unexpected file:\n%s\nwant:\n%s", data, expected)
+	}
+}
+
+func TestRecorder_RejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "syslog.json")
+	r, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	r.Add("<14>1 first", 14, time.Now())
+	if _, err := r.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	// Another writer truncates the file between flushes.
+	if err := os.WriteFile(path, []byte(`[{"raw": "<14>1 first"`), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	r.Add("<14>1 second", 14, time.Now())
+	if _, err := r.Flush(); err == nil {
+		t.Fatal("expected an error for a file without a closing bracket")
+	}
+	if r.Pending() != 1 {
+		t.Errorf("expected the record kept for a retry, got %d pending", r.Pending())
+	}
+}
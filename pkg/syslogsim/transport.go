@@ -0,0 +1,172 @@
+package syslogsim
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// Reconnect defaults for TCP.
+const (
+	defaultReconnectBackoff    = 500 * time.Millisecond
+	defaultMaxReconnectBackoff = 30 * time.Second
+)
+
+// transport delivers messages over a UDP or TCP connection. A broken TCP
+// connection is re-dialled with exponential backoff; meanwhile up to
+// maxBuffer messages are held and sent, in order, once it is back. Backoff
+// runs on wall time, so it behaves the same in backfill and live runs.
+//
+// Reconnects happen on the next send once the backoff has passed, never in
+// the background, so a transport is only used by the Run goroutine and is
+// not safe for concurrent use.
+//
+// NOTE: TCP only reports a dead peer on a later write, so messages written
+// just before a drop may be lost without an error.
+type transport struct {
+	network, addr string
+	conn          net.Conn // Nil while disconnected
+
+	buffer    [][]byte // Messages waiting for a reconnect, oldest first
+	maxBuffer int
+
+	backoff, minBackoff, maxBackoff time.Duration
+	nextDial                        time.Time
+	lastErr                         error
+
+	sent, dropped, disconnects, reconnects int
+}
+
+// dialTransport connects to addr, returning the error if the target cannot
+// be reached.
+func dialTransport(network, addr string, maxBuffer int, minBackoff, maxBackoff time.Duration) (*transport, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s over %s: %w", addr, network, err)
+	}
+	if minBackoff <= 0 {
+		minBackoff = defaultReconnectBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxReconnectBackoff
+	}
+	return &transport{
+		network:    network,
+		addr:       addr,
+		conn:       conn,
+		maxBuffer:  maxBuffer,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+		backoff:    minBackoff,
+	}, nil
+}
+
+// send writes msg, or buffers it while a TCP connection is down. It returns
+// an error when msg was not delivered and not buffered.
+func (t *transport) send(msg []byte) error {
+	if t.network != "tcp" {
+		if _, err := t.conn.Write(msg); err != nil {
+			t.dropped++
+			return err
+		}
+		t.sent++
+		return nil
+	}
+
+	if t.conn == nil && !t.redial() {
+		return t.hold(msg)
+	}
+	if err := t.drain(); err != nil {
+		return t.hold(msg)
+	}
+	if _, err := t.conn.Write(msg); err != nil {
+		t.disconnect(err)
+		return t.hold(msg)
+	}
+	t.sent++
+	return nil
+}
+
+// hold buffers msg for the next reconnect, dropping the oldest buffered
+// message when the buffer is full. It returns an error for the dropped
+// message, if any.
+func (t *transport) hold(msg []byte) error {
+	if t.maxBuffer <= 0 {
+		t.dropped++
+		return fmt.Errorf("connection to %s is down: %w", t.addr, t.lastErr)
+	}
+	var err error
+	if len(t.buffer) >= t.maxBuffer {
+		t.buffer = t.buffer[1:]
+		t.dropped++
+		err = fmt.Errorf("outage buffer full, dropped oldest message: %w", t.lastErr)
+	}
+	t.buffer = append(t.buffer, msg)
+	return err
+}
+
+// drain sends buffered messages, stopping at the first failure.
+func (t *transport) drain() error {
+	for len(t.buffer) > 0 {
+		if _, err := t.conn.Write(t.buffer[0]); err != nil {
+			t.disconnect(err)
+			return err
+		}
+		t.buffer = t.buffer[1:]
+		t.sent++
+	}
+	return nil
+}
+
+// disconnect closes a failed connection and schedules a reconnect.
+func (t *transport) disconnect(err error) {
+	t.conn.Close()
+	t.conn = nil
+	t.disconnects++
+	t.lastErr = err
+	t.nextDial = time.Now().Add(t.backoff)
+}
+
+// redial tries to reconnect once the backoff has passed, doubling the
+// backoff after each failure. It reports whether the transport is connected.
+func (t *transport) redial() bool {
+	if time.Now().Before(t.nextDial) {
+		return false
+	}
+	conn, err := net.Dial(t.network, t.addr)
+	if err != nil {
+		t.lastErr = err
+		t.backoff = min(2*t.backoff, t.maxBackoff)
+		t.nextDial = time.Now().Add(t.backoff)
+		return false
+	}
+	t.conn = conn
+	t.backoff = t.minBackoff
+	t.reconnects++
+	return true
+}
+
+// close makes a last attempt to deliver buffered messages, counts any left
+// as dropped and closes the connection. It returns an error if messages
+// were left undelivered.
+func (t *transport) close() error {
+	if len(t.buffer) > 0 && (t.conn != nil || t.redialNow()) {
+		t.drain()
+	}
+	if t.conn != nil {
+		t.conn.Close()
+		t.conn = nil
+	}
+	if n := len(t.buffer); n > 0 {
+		t.buffer = nil
+		t.dropped += n
+		return fmt.Errorf("%d buffered messages undelivered to %s: %w", n, t.addr, t.lastErr)
+	}
+	return nil
+}
+
+// redialNow reconnects without waiting for the backoff.
+func (t *transport) redialNow() bool {
+	t.nextDial = time.Time{}
+	return t.redial()
+}
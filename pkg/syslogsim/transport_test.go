@@ -0,0 +1,116 @@
+package syslogsim
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// acceptLines accepts one connection on ln, sends its lines to out and
+// returns the server side of the connection.
+func acceptLines(ln net.Listener, out chan<- string) <-chan net.Conn {
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		accepted <- conn
+		for sc := bufio.NewScanner(conn); sc.Scan(); {
+			out <- sc.Text()
+		}
+	}()
+	return accepted
+}
+
+func TestTransport_ReconnectsAndDrainsBuffer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	lines := make(chan string, 100)
+	server := acceptLines(ln, lines)
+
+	tr, err := dialTransport("tcp", addr, 2, 10*time.Millisecond, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := tr.send([]byte("m1\n")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got := <-lines; got != "m1" {
+		t.Fatalf("expected m1, got %q", got)
+	}
+
+	// Take the collector down. The first writes after the drop may still be
+	// accepted by the kernel; keep sending until the transport notices.
+	ln.Close()
+	(<-server).Close()
+	for i := 0; tr.conn != nil; i++ {
+		if i > 100 {
+			t.Fatal("transport did not notice the dropped connection")
+		}
+		tr.send([]byte("lost\n"))
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Start from an empty buffer, dropping the probes above; it then holds
+	// the two newest messages.
+	tr.buffer = nil
+	for _, m := range []string{"b1", "b2", "b3"} {
+		tr.send([]byte(m + "\n"))
+	}
+	if len(tr.buffer) != 2 || string(tr.buffer[0]) != "b2\n" {
+		t.Fatalf("expected b2 and b3 buffered, got %q", tr.buffer)
+	}
+
+	// Bring the collector back on the same address.
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("relisten: %v", err)
+	}
+	defer ln.Close()
+	acceptLines(ln, lines)
+
+	time.Sleep(60 * time.Millisecond) // Past the maximum backoff
+	if err := tr.send([]byte("m2\n")); err != nil {
+		t.Fatalf("send after reconnect: %v", err)
+	}
+	var got []string
+	for len(got) < 3 {
+		select {
+		case l := <-lines:
+			if l != "lost" {
+				got = append(got, l)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out, received %v", got)
+		}
+	}
+	if strings.Join(got, ",") != "b2,b3,m2" {
+		t.Errorf("expected buffered messages first, got %v", got)
+	}
+	if tr.reconnects != 1 || tr.disconnects != 1 {
+		t.Errorf("expected 1 disconnect and reconnect, got %d and %d", tr.disconnects, tr.reconnects)
+	}
+	if err := tr.close(); err != nil {
+		t.Errorf("expected clean close, got %v", err)
+	}
+}
+
+func TestNewSimulator_ConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	_, err = NewSimulator(Config{Host: "127.0.0.1", Port: port, Protocol: "tcp"})
+	if err == nil || !strings.Contains(err.Error(), "failed to connect") {
+		t.Fatalf("expected connection error, got %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+// Package traffic shapes the rate at which simulators emit events. A Profile
+// combines a baseline rate with Poisson or fixed arrivals, a diurnal
+// (time-of-day) curve, burst and storm episodes, and a severity mix; a
+// Schedule turns it into the wait before each next event.
+//
+// Profiles are read from YAML, either as a preset name:
+//
+//	profile: storm
+//
+// or spelled out:
+//
+//	profile:
+//	  rate: 5              # events per second; omit to use the device's interval
+//	  arrivals: poisson    # or fixed
+//	  diurnal: {amplitude: 0.7, peak: "14:00"}
+//	  bursts:
+//	    - {after: 10m, every: 1h, duration: 30s, rate: 10000}
+//	  severities: {info: 70, warning: 20, error: 8, critical: 2}
+//
+// Times of day are evaluated on the simulator's clock, so backfilled and
+// accelerated runs follow the same curve as live ones.
+package traffic
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Profile describes how an event rate varies over time.
+type Profile struct {
+	Rate       float64            `yaml:"rate"`       // Baseline events per second; 0 uses the caller's interval
+	Arrivals   string             `yaml:"arrivals"`   // "fixed" (default) or "poisson"
+	Diurnal    *Diurnal           `yaml:"diurnal"`    // Optional time-of-day modulation
+	Bursts     []Burst            `yaml:"bursts"`     // Episodes with their own rate
+	Severities map[string]float64 `yaml:"severities"` // Relative weights by severity name; empty leaves severity to the generator
+}
+
+// Diurnal modulates the rate over the day as a sine wave: at Peak the rate is
+// (1+Amplitude) times the baseline, twelve hours later (1-Amplitude) times.
+type Diurnal struct {
+	Amplitude float64 `yaml:"amplitude"` // In [0, 1)
+	Peak      string  `yaml:"peak"`      // Time of day, "HH:MM" UTC; defaults to 14:00
+}
+
+// Burst is an episode during which events arrive at Rate instead of the
+// shaped baseline, e.g. a link-flap storm. It starts After the beginning of
+// the run, lasts Duration, and repeats Every interval if set.
+type Burst struct {
+	After    time.Duration `yaml:"after"`
+	Every    time.Duration `yaml:"every"`
+	Duration time.Duration `yaml:"duration"`
+	Rate     float64       `yaml:"rate"` // Events per second during the burst
+}
+
+// Arrival processes.
+const (
+	Fixed   = "fixed"   // Evenly spaced events
+	Poisson = "poisson" // Exponentially distributed gaps with the same mean
+)
+
+// presets are the built-in profiles, selectable by name.
+var presets = map[string]Profile{
+	"steady":  {Arrivals: Fixed},
+	"poisson": {Arrivals: Poisson},
+	"diurnal": {Arrivals: Poisson, Diurnal: &Diurnal{Amplitude: 0.8, Peak: "14:00"}},
+	"storm": {Arrivals: Poisson, Bursts: []Burst{
+		{After: time.Minute, Every: 10 * time.Minute, Duration: 20 * time.Second, Rate: 10000},
+	}},
+}
+
+// Presets returns the names of the built-in profiles.
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preset returns a copy of the named built-in profile, which the caller may
+// modify without affecting the preset.
+func Preset(name string) (*Profile, error) {
+	p, ok := presets[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown traffic profile %q (presets: %s)", name, strings.Join(Presets(), ", "))
+	}
+	return p.clone(), nil
+}
+
+// clone returns a deep copy of p.
+func (p Profile) clone() *Profile {
+	if p.Diurnal != nil {
+		d := *p.Diurnal
+		p.Diurnal = &d
+	}
+	p.Bursts = slices.Clone(p.Bursts)
+	p.Severities = maps.Clone(p.Severities)
+	return &p
+}
+
+// Load returns the preset called nameOrPath, or reads and validates a
+// profile from the YAML file at that path.
+func Load(nameOrPath string) (*Profile, error) {
+	if _, ok := presets[strings.ToLower(nameOrPath)]; ok {
+		return Preset(nameOrPath)
+	}
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read traffic profile %s (presets: %s): %w",
+			nameOrPath, strings.Join(Presets(), ", "), err)
+	}
+	var p Profile
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid traffic profile %s: %w", nameOrPath, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, accepting a preset name or a
+// mapping.
+func (p *Profile) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		preset, err := Preset(name)
+		if err != nil {
+			return err
+		}
+		*p = *preset
+		return nil
+	}
+
+	type plain Profile
+	return unmarshal((*plain)(p))
+}
+
+// Validate checks the profile's values. All problems are reported together.
+func (p *Profile) Validate() error {
+	var errs []error
+	if p.Rate < 0 {
+		errs = append(errs, fmt.Errorf("rate must not be negative, got %g", p.Rate))
+	}
+	if p.Arrivals != "" && p.Arrivals != Fixed && p.Arrivals != Poisson {
+		errs = append(errs, fmt.Errorf("arrivals must be %s or %s, got %q", Fixed, Poisson, p.Arrivals))
+	}
+	if d := p.Diurnal; d != nil {
+		if d.Amplitude < 0 || d.Amplitude >= 1 {
+			errs = append(errs, fmt.Errorf("diurnal amplitude must be in [0, 1), got %g", d.Amplitude))
+		}
+		if _, err := d.peak(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i, b := range p.Bursts {
+		switch {
+		case b.Duration <= 0 || b.Rate <= 0:
+			errs = append(errs, fmt.Errorf("bursts[%d]: duration and rate must be positive", i))
+		case b.After < 0 || b.Every < 0:
+			errs = append(errs, fmt.Errorf("bursts[%d]: after and every must not be negative", i))
+		case b.Every > 0 && b.Every < b.Duration:
+			errs = append(errs, fmt.Errorf("bursts[%d]: every (%s) is shorter than duration (%s)", i, b.Every, b.Duration))
+		}
+	}
+	total := 0.0
+	for name, w := range p.Severities {
+		if w < 0 {
+			errs = append(errs, fmt.Errorf("severities: weight for %s must not be negative", name))
+		}
+		total += w
+	}
+	if len(p.Severities) > 0 && total == 0 {
+		errs = append(errs, errors.New("severities: at least one weight must be positive"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid traffic profile: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// peak returns the time of day of the diurnal maximum.
+func (d *Diurnal) peak() (time.Duration, error) {
+	if d.Peak == "" {
+		return 14 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", d.Peak)
+	if err != nil {
+		return 0, fmt.Errorf("diurnal peak must be HH:MM, got %q", d.Peak)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// PickSeverity returns a severity name drawn from weights, or "" if weights
+// is empty. Names are visited in sorted order so a seeded rng gives
+// reproducible picks.
+func PickSeverity(rng *rand.Rand, weights map[string]float64) string {
+	if len(weights) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(weights))
+	total := 0.0
+	for name, w := range weights {
+		names = append(names, name)
+		total += w
+	}
+	sort.Strings(names)
+
+	x := rng.Float64() * total
+	for _, name := range names {
+		if x < weights[name] {
+			return name
+		}
+		x -= weights[name]
+	}
+	return names[len(names)-1]
+}
+
+// ---------------- Schedules ----------------
+
+// Schedule produces the gaps between events for one stream. It is not safe
+// for concurrent use.
+type Schedule struct {
+	p     Profile
+	base  float64 // Events per second before shaping
+	start time.Time
+	rng   *rand.Rand
+	peak  time.Duration
+}
+
+// NewSchedule returns a schedule for p starting at start. The baseline is
+// p.Rate, or one event per interval if p.Rate is 0; a nil p gives evenly
+// spaced events every interval.
+func NewSchedule(p *Profile, interval time.Duration, start time.Time, rng *rand.Rand) *Schedule {
+	s := &Schedule{start: start, rng: rng}
+	if p != nil {
+		s.p = *p
+	}
+	s.base = s.p.Rate
+	if s.base == 0 && interval > 0 {
+		s.base = float64(time.Second) / float64(interval)
+	}
+	if s.p.Diurnal != nil {
+		s.peak, _ = s.p.Diurnal.peak()
+	}
+	return s
+}
+
+// Rate returns the event rate, per second, at time t.
+func (s *Schedule) Rate(t time.Time) float64 {
+	elapsed := t.Sub(s.start)
+	for _, b := range s.p.Bursts {
+		if b.active(elapsed) {
+			return b.Rate
+		}
+	}
+
+	rate := s.base
+	if d := s.p.Diurnal; d != nil {
+		sinceMidnight := t.UTC().Sub(t.UTC().Truncate(24 * time.Hour))
+		phase := 2 * math.Pi * float64(sinceMidnight-s.peak) / float64(24*time.Hour)
+		rate *= 1 + d.Amplitude*math.Cos(phase)
+	}
+	return rate
+}
+
+// Next returns the wait from t until the next event. The rate is taken at t,
+// which is accurate while gaps are short compared with how fast the profile
+// changes; a wait never runs past the start of the next burst, so storms
+// begin on time.
+func (s *Schedule) Next(t time.Time) time.Duration {
+	rate := s.Rate(t)
+	if rate <= 0 {
+		return time.Hour // No baseline: wait for the next burst
+	}
+
+	gap := 1 / rate
+	if s.p.Arrivals == Poisson {
+		gap = s.rng.ExpFloat64() / rate
+	}
+	wait := time.Duration(math.Round(gap * float64(time.Second)))
+
+	elapsed := t.Sub(s.start)
+	for _, b := range s.p.Bursts {
+		if until, ok := b.untilStart(elapsed); ok && until > 0 && until < wait {
+			wait = until
+		}
+	}
+	return max(wait, time.Microsecond)
+}
+
+// active reports whether the burst is on at elapsed time since the start.
+func (b Burst) active(elapsed time.Duration) bool {
+	if elapsed < b.After {
+		return false
+	}
+	into := elapsed - b.After
+	if b.Every > 0 {
+		into %= b.Every
+	}
+	return into < b.Duration
+}
+
+// untilStart returns the time from elapsed until the burst next starts.
+func (b Burst) untilStart(elapsed time.Duration) (time.Duration, bool) {
+	if elapsed < b.After {
+		return b.After - elapsed, true
+	}
+	if b.Every <= 0 {
+		return 0, false
+	}
+	return b.Every - (elapsed-b.After)%b.Every, true
+}
@@ -0,0 +1,138 @@
+package traffic
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestSchedule_NilProfileIsFixed(t *testing.T) {
+	s := NewSchedule(nil, 7*time.Second, start, rand.New(rand.NewSource(1)))
+	for i := 0; i < 10; i++ {
+		if got := s.Next(start.Add(time.Duration(i) * time.Minute)); got != 7*time.Second {
+			t.Fatalf("expected a fixed 7s gap, got %v", got)
+		}
+	}
+}
+
+func TestSchedule_PoissonMean(t *testing.T) {
+	s := NewSchedule(&Profile{Arrivals: Poisson, Rate: 10}, 0, start, rand.New(rand.NewSource(1)))
+
+	const n = 20000
+	var total time.Duration
+	now := start
+	for i := 0; i < n; i++ {
+		gap := s.Next(now)
+		total += gap
+		now = now.Add(gap)
+	}
+	if mean := total.Seconds() / n; math.Abs(mean-0.1) > 0.005 {
+		t.Errorf("expected a mean gap of about 100ms, got %.4fs", mean)
+	}
+}
+
+func TestSchedule_Diurnal(t *testing.T) {
+	p := &Profile{Rate: 10, Diurnal: &Diurnal{Amplitude: 0.5, Peak: "14:00"}}
+	s := NewSchedule(p, 0, start, rand.New(rand.NewSource(1)))
+
+	peak, trough := s.Rate(start.Add(14*time.Hour)), s.Rate(start.Add(2*time.Hour))
+	if math.Abs(peak-15) > 1e-9 || math.Abs(trough-5) > 1e-9 {
+		t.Errorf("expected 15/s at the peak and 5/s at the trough, got %g and %g", peak, trough)
+	}
+}
+
+func TestSchedule_Bursts(t *testing.T) {
+	p := &Profile{Rate: 1, Bursts: []Burst{{After: 10 * time.Second, Every: time.Minute, Duration: 5 * time.Second, Rate: 1000}}}
+	s := NewSchedule(p, 0, start, rand.New(rand.NewSource(1)))
+
+	tests := []struct {
+		at   time.Duration
+		want float64
+	}{
+		{0, 1},
+		{10 * time.Second, 1000},
+		{14 * time.Second, 1000},
+		{15 * time.Second, 1},
+		{70 * time.Second, 1000},
+		{76 * time.Second, 1},
+	}
+	for _, tt := range tests {
+		if got := s.Rate(start.Add(tt.at)); got != tt.want {
+			t.Errorf("rate at %v: got %g, want %g", tt.at, got, tt.want)
+		}
+	}
+
+	// A wait never skips the start of a burst.
+	if got := s.Next(start.Add(9500 * time.Millisecond)); got != 500*time.Millisecond {
+		t.Errorf("expected the gap to end at the burst, got %v", got)
+	}
+}
+
+func TestPickSeverity(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	weights := map[string]float64{"info": 90, "critical": 10, "error": 0}
+
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		counts[PickSeverity(rng, weights)]++
+	}
+	if counts["error"] != 0 {
+		t.Errorf("zero-weight severity picked %d times", counts["error"])
+	}
+	if counts["info"] < 8700 || counts["info"] > 9300 {
+		t.Errorf("expected about 9000 info picks, got %d", counts["info"])
+	}
+	if got := PickSeverity(rng, nil); got != "" {
+		t.Errorf("expected no severity without weights, got %q", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if p, err := Load("Storm"); err != nil || len(p.Bursts) != 1 {
+		t.Fatalf("expected the storm preset, got %+v, %v", p, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.yml")
+	data := "arrivals: bursty\ndiurnal: {amplitude: 1.5, peak: noon}\nbursts: [{duration: 1s}]\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, err := Load(path)
+	for _, want := range []string{"arrivals must be", "amplitude must be", "peak must be", "bursts[0]"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+
+	if _, err := Load("no-such-profile"); err == nil || !strings.Contains(err.Error(), "presets:") {
+		t.Errorf("expected unknown profile error listing presets, got %v", err)
+	}
+}
+
+func TestPreset_ReturnsIndependentCopy(t *testing.T) {
+	for _, name := range Presets() {
+		p, err := Preset(name)
+		if err != nil {
+			t.Fatalf("preset %s: %v", name, err)
+		}
+		if p.Diurnal != nil {
+			p.Diurnal.Amplitude = 0
+		}
+		for i := range p.Bursts {
+			p.Bursts[i].Rate = 1
+		}
+	}
+
+	if p, _ := Preset("diurnal"); p.Diurnal.Amplitude != 0.8 {
+		t.Errorf("expected the diurnal preset unchanged, got amplitude %v", p.Diurnal.Amplitude)
+	}
+	if p, _ := Preset("storm"); p.Bursts[0].Rate != 10000 {
+		t.Errorf("expected the storm preset unchanged, got burst rate %v", p.Bursts[0].Rate)
+	}
+}
@@ -0,0 +1,154 @@
+package simulator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ibm-live-project-interns/datasource/config"
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
+)
+
+// DeviceRequest is the JSON body accepted by POST /devices. Name may use the
+// same {start..end} range syntax as the YAML configuration, Profile names
+// a built-in traffic profile (see traffic.Presets) and Dialect a syslog
+// format (see syslogsim.Dialects).
+type DeviceRequest struct {
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Count          int    `json:"count,omitempty"`
+	TrapInterval   int    `json:"trap_interval,omitempty"`
+	SyslogInterval int    `json:"syslog_interval,omitempty"`
+	TrapTarget     string `json:"trap_target,omitempty"`
+	SyslogTarget   string `json:"syslog_target,omitempty"`
+	Profile        string `json:"profile,omitempty"`
+	Dialect        string `json:"dialect,omitempty"`
+}
+
+// IntervalRequest is the JSON body accepted by PATCH /devices/{name}.
+// Omitted fields keep their current value.
+type IntervalRequest struct {
+	TrapInterval   *int `json:"trap_interval,omitempty"`
+	SyslogInterval *int `json:"syslog_interval,omitempty"`
+}
+
+// Handler returns the HTTP control API for the Manager:
+//
+//	GET    /devices              list device status
+//	POST   /devices              add devices (DeviceRequest)
+//	GET    /devices/{name}       device status
+//	DELETE /devices/{name}       stop and remove a device
+//	PATCH  /devices/{name}       change intervals (IntervalRequest)
+//	POST   /devices/{name}/pause stop telemetry until resumed
+//	POST   /devices/{name}/resume
+//
+// Responses are JSON; errors are returned as {"error": "..."}.
+func (m *Manager) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /devices", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.Status())
+	})
+
+	mux.HandleFunc("POST /devices", func(w http.ResponseWriter, r *http.Request) {
+		var req DeviceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		var profile *traffic.Profile
+		if req.Profile != "" {
+			p, err := traffic.Preset(req.Profile)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			profile = p
+		}
+
+		added, err := m.Add(config.DeviceConfig{
+			Name:           req.Name,
+			Type:           req.Type,
+			Count:          req.Count,
+			TrapInterval:   req.TrapInterval,
+			SyslogInterval: req.SyslogInterval,
+			TrapTarget:     req.TrapTarget,
+			SyslogTarget:   req.SyslogTarget,
+			Profile:        profile,
+			Dialect:        req.Dialect,
+		})
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, added)
+	})
+
+	mux.HandleFunc("GET /devices/{name}", func(w http.ResponseWriter, r *http.Request) {
+		st, err := m.DeviceStatus(r.PathValue("name"))
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, st)
+	})
+
+	mux.HandleFunc("DELETE /devices/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if err := m.Remove(r.PathValue("name")); err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("PATCH /devices/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var req IntervalRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		st, err := m.SetIntervals(r.PathValue("name"), req.TrapInterval, req.SyslogInterval)
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, st)
+	})
+
+	for action, fn := range map[string]func(string) error{"pause": m.Pause, "resume": m.Resume} {
+		mux.HandleFunc("POST /devices/{name}/"+action, func(w http.ResponseWriter, r *http.Request) {
+			name := r.PathValue("name")
+			if err := fn(name); err != nil {
+				writeError(w, statusFor(err), err)
+				return
+			}
+			st, _ := m.DeviceStatus(name)
+			writeJSON(w, http.StatusOK, st)
+		})
+	}
+
+	return mux
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrDeviceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrDeviceExists):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,117 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/config"
+)
+
+func do(t *testing.T, srv *httptest.Server, method, path, body string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp, data
+}
+
+// waitState polls until the named device reaches state.
+func waitState(t *testing.T, m *Manager, name string, state State) DeviceStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		st, err := m.DeviceStatus(name)
+		if err == nil && st.State == state {
+			return st
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("device %s did not reach %s: %+v, %v", name, state, st, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHandler_Lifecycle(t *testing.T) {
+	m, err := NewManagerFromConfig(&config.SimulatorConfig{
+		TrapTarget:   "127.0.0.1:9",
+		SyslogTarget: "127.0.0.1:9",
+		Devices:      []config.DeviceConfig{{Name: "router-1", Type: "router", TrapInterval: 60}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	resp, body := do(t, srv, "POST", "/devices", `{"name": "sw-{1..2}", "type": "switch", "syslog_interval": 60}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("add: expected 201, got %d: %s", resp.StatusCode, body)
+	}
+	waitState(t, m, "sw-2", StateRunning)
+
+	resp, body = do(t, srv, "POST", "/devices", `{"name": "sw-1", "type": "switch", "syslog_interval": 60}`)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("duplicate add: expected 409, got %d: %s", resp.StatusCode, body)
+	}
+
+	resp, body = do(t, srv, "GET", "/devices", "")
+	var list []DeviceStatus
+	if err := json.Unmarshal(body, &list); err != nil || len(list) != 3 {
+		t.Fatalf("list: expected 3 devices, got %s (%v)", body, err)
+	}
+
+	do(t, srv, "POST", "/devices/router-1/pause", "")
+	waitState(t, m, "router-1", StatePaused)
+	do(t, srv, "POST", "/devices/router-1/resume", "")
+	waitState(t, m, "router-1", StateRunning)
+
+	resp, body = do(t, srv, "PATCH", "/devices/router-1", `{"trap_interval": 30}`)
+	var st DeviceStatus
+	if err := json.Unmarshal(body, &st); err != nil || resp.StatusCode != http.StatusOK || st.TrapInterval != 30 {
+		t.Errorf("patch: unexpected response %d: %s", resp.StatusCode, body)
+	}
+	if st := waitState(t, m, "router-1", StateRunning); st.Restarts != 0 {
+		t.Errorf("expected reconfiguration not to count as a restart, got %d", st.Restarts)
+	}
+
+	resp, body = do(t, srv, "PATCH", "/devices/router-1", `{"trap_interval": 0}`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid patch: expected 400, got %d: %s", resp.StatusCode, body)
+	}
+
+	if resp, _ = do(t, srv, "DELETE", "/devices/sw-1", ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("delete: expected 204, got %d", resp.StatusCode)
+	}
+	if resp, _ = do(t, srv, "GET", "/devices/sw-1", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("get removed device: expected 404, got %d", resp.StatusCode)
+	}
+	if m.Len() != 2 {
+		t.Errorf("expected 2 devices after removal, got %d", m.Len())
+	}
+}
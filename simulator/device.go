@@ -2,26 +2,25 @@
 // generate telemetry data (SNMP traps, syslog events, metadata updates).
 //
 // The package defines a Device interface and concrete implementations for
-// routers and switches, managed by a central Manager that runs them
-// concurrently. Each device type is intended to generate its specific
-// telemetry type (Router → SNMP traps, Switch → syslog events).
-//
-// NOTE: The current Router and Switch implementations are placeholder stubs
-// that log messages without generating actual telemetry. Future iterations
-// should integrate with the pkg/snmptrap and pkg/syslogsim packages to
-// produce real simulated events.
+// routers, switches, firewalls, servers and wireless access points, managed by
+// a central Manager that runs them concurrently. Each device type generates
+// its specific telemetry (Router → SNMP traps via pkg/snmptrap, Switch →
+// RFC 5424 syslog via pkg/syslogsim, and an Appliance for firewalls, servers
+// and access points → a mix of both), using its own name as the event source.
+// Telemetry is derived from each device's internal state model (see
+// model.go), so traps and syslog describe a consistent sequence of faults and
+// recoveries.
 package simulator
 
 import "context"
 
 // Device represents a simulated network device capable of generating telemetry.
-// Implementations should respect context cancellation for graceful shutdown.
 //
-// TODO: Consider adding an error return to Run() so the Manager can detect
-// and handle device failures. Also consider adding Name() and Type() methods
-// for better observability and logging.
+// Run blocks until the context is cancelled, in which case it returns nil, or
+// until the device fails, in which case it returns the cause. The Manager
+// restarts failed devices according to its RestartPolicy.
 type Device interface {
-	Run(ctx context.Context)
+	Name() string // Unique device name, also used as the telemetry source
+	Type() string // Device type as used in configuration (e.g. "router")
+	Run(ctx context.Context) error
 }
-
-
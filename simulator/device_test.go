@@ -0,0 +1,152 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/config"
+	"github.com/ibm-live-project-interns/datasource/pkg/snmptrap"
+)
+
+// listenUDP returns a local UDP listener and its address.
+func listenUDP(t *testing.T) (net.PacketConn, string) {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	return pc, pc.LocalAddr().String()
+}
+
+// receive reads one datagram, failing the test after a timeout.
+func receive(t *testing.T, pc net.PacketConn) []byte {
+	t.Helper()
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 4096)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("expected a datagram, got %v", err)
+	}
+	return buf[:n]
+}
+
+func TestRouter_SendsTraps(t *testing.T) {
+	pc, addr := listenUDP(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := &Router{emitter{name: "edge-rtr-7", kind: "router", trapInterval: 10 * time.Millisecond, trapTarget: addr}}
+	go r.Run(ctx)
+
+	var trap snmptrap.Trap
+	if err := json.Unmarshal(receive(t, pc), &trap); err != nil {
+		t.Fatalf("expected JSON trap, got %v", err)
+	}
+	if trap.Source != "edge-rtr-7" {
+		t.Errorf("expected trap source edge-rtr-7, got %q", trap.Source)
+	}
+	if trap.OID == "" {
+		t.Errorf("expected trap OID to be set")
+	}
+}
+
+func TestSwitch_SendsSyslog(t *testing.T) {
+	pc, addr := listenUDP(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &Switch{emitter{name: "access-sw-3", kind: "switch", syslogInterval: 10 * time.Millisecond, syslogTarget: addr}}
+	go s.Run(ctx)
+
+	fields := strings.Fields(string(receive(t, pc)))
+	if len(fields) < 4 || !strings.HasSuffix(fields[0], ">1") {
+		t.Fatalf("expected RFC 5424 message, got %q", strings.Join(fields, " "))
+	}
+	if fields[2] != "access-sw-3" {
+		t.Errorf("expected HOSTNAME access-sw-3, got %q", fields[2])
+	}
+}
+
+func TestRouterAndSwitch_SendBothKinds(t *testing.T) {
+	trapConn, trapAddr := listenUDP(t)
+	syslogConn, syslogAddr := listenUDP(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	both := emitter{trapInterval: 10 * time.Millisecond, syslogInterval: 10 * time.Millisecond, trapTarget: trapAddr, syslogTarget: syslogAddr}
+	r := &Router{both}
+	r.name, r.kind = "rtr-1", "router"
+	s := &Switch{both}
+	s.name, s.kind = "sw-1", "switch"
+	go r.Run(ctx)
+	go s.Run(ctx)
+
+	traps, syslogs := map[string]bool{}, map[string]bool{}
+	for len(traps) < 2 {
+		var trap snmptrap.Trap
+		if err := json.Unmarshal(receive(t, trapConn), &trap); err != nil {
+			t.Fatalf("expected JSON trap, got %v", err)
+		}
+		traps[trap.Source] = true
+	}
+	for len(syslogs) < 2 {
+		fields := strings.Fields(string(receive(t, syslogConn)))
+		if len(fields) < 4 {
+			t.Fatalf("expected syslog message, got %q", strings.Join(fields, " "))
+		}
+		syslogs[fields[2]] = true
+	}
+	if !traps["rtr-1"] || !traps["sw-1"] || !syslogs["rtr-1"] || !syslogs["sw-1"] {
+		t.Errorf("expected traps and syslog from both devices, got traps %v, syslog %v", traps, syslogs)
+	}
+}
+
+func TestFirewall_SendsTrapsAndSyslog(t *testing.T) {
+	trapConn, trapAddr := listenUDP(t)
+	syslogConn, syslogAddr := listenUDP(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fw := &Appliance{emitter: emitter{
+		name:           "fw-1",
+		kind:           "firewall",
+		trapInterval:   10 * time.Millisecond,
+		syslogInterval: 10 * time.Millisecond,
+		trapTarget:     trapAddr,
+		syslogTarget:   syslogAddr,
+	}}
+	go fw.Run(ctx)
+
+	var trap snmptrap.Trap
+	if err := json.Unmarshal(receive(t, trapConn), &trap); err != nil {
+		t.Fatalf("expected JSON trap, got %v", err)
+	}
+	if trap.Source != "fw-1" {
+		t.Errorf("expected trap source fw-1, got %q", trap.Source)
+	}
+
+	fields := strings.Fields(string(receive(t, syslogConn)))
+	if len(fields) < 4 || fields[2] != "fw-1" {
+		t.Errorf("expected syslog from fw-1, got %q", strings.Join(fields, " "))
+	}
+}
+
+func TestNewEmitter_RequiresInterval(t *testing.T) {
+	if _, err := deviceTypes["server"](config.DeviceConfig{Name: "srv-1", Type: "server"}); err == nil {
+		t.Fatal("expected error for server without intervals")
+	}
+
+	d, err := deviceTypes["ap"](config.DeviceConfig{Name: "ap-1", Type: "ap", TrapInterval: 3})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ap := d.(*Appliance)
+	if ap.trapInterval != 3*time.Second || ap.syslogInterval != 0 || ap.kind != "access-point" {
+		t.Errorf("unexpected access point: %+v", ap)
+	}
+}
@@ -2,57 +2,796 @@ package simulator
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"log"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/config"
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
 )
 
 // Manager orchestrates multiple concurrent Device simulators.
-// It launches each device in its own goroutine and waits for all to complete.
+// It launches each device in its own goroutine, restarts devices that fail
+// according to its RestartPolicy, and waits for all to complete.
+//
+// Devices are normally built from a YAML configuration with
+// NewManagerFromConfig; NewManager provides a fixed two-device default.
+// While Start is running, devices can be added, removed, paused, resumed and
+// reconfigured; see Handler for the HTTP control API built on these methods.
+//
+// Devices run on the wall clock unless Clock is set, e.g. to backfill a day
+// of telemetry as fast as possible or to replay it 60x faster (see
+// simclock.Options). Each device gets its own clock when it is first
+// launched and keeps it across restarts, resumes and reconfigurations.
+// Devices added while Start is running join at the simulation's current
+// time: the latest time any device's clock has reached.
 //
-// NOTE: The device list is currently hardcoded in NewManager. Future iterations
-// should accept a configuration (e.g. from YAML or CLI flags) to define which
-// devices to simulate and their parameters.
+// Device randomness derives from Seed and each device's name, so a run
+// with the same seed and configuration sends the same telemetry, whichever
+// other devices run alongside.
 type Manager struct {
-	devices []Device
+	Restart RestartPolicy         // How failed devices are restarted
+	Clock   func() simclock.Clock // Creates each device's clock; nil means simclock.Real
+	Seed    int64                 // Random seed for all devices; 0 picks one from the clock at Start
+
+	mu           sync.Mutex
+	devices      []*supervisor
+	trapTarget   string // Defaults for devices added at runtime
+	syslogTarget string
+	ctx          context.Context // Set while Start is running
+	settle       chan struct{}   // Signalled when a device stops on its own or fails
+	wg           sync.WaitGroup
 }
 
-// NewManager creates a Manager with a default set of simulated devices.
-//
-// TODO: Accept a device configuration parameter instead of hardcoding the
-// device list. This would allow dynamic device counts and types.
-func NewManager() *Manager {
-	return &Manager{
-		devices: []Device{
-			&Router{Name: "router-1"},
-			&Switch{Name: "switch-1"},
+// Errors returned by the runtime control methods.
+var (
+	ErrDeviceNotFound = errors.New("device not found")
+	ErrDeviceExists   = errors.New("device already exists")
+)
+
+// State is the lifecycle state of a managed device.
+type State string
+
+const (
+	StatePending    State = "pending"    // Not started yet
+	StateRunning    State = "running"    // Run is executing
+	StatePaused     State = "paused"     // Stopped by Pause; waiting for Resume
+	StateRestarting State = "restarting" // Failed; waiting for the backoff before restarting
+	StateStopped    State = "stopped"    // Run returned after cancellation or on its own
+	StateFailed     State = "failed"     // Failed more often than the restart policy allows
+)
+
+// DeviceStatus is a snapshot of a managed device's state.
+type DeviceStatus struct {
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	State          State     `json:"state"`
+	TrapInterval   int       `json:"trap_interval,omitempty"`   // Seconds, as configured
+	SyslogInterval int       `json:"syslog_interval,omitempty"` // Seconds, as configured
+	Restarts       int       `json:"restarts"`                  // Total restarts since Start
+	LastError      string    `json:"last_error,omitempty"`      // Most recent failure, if any
+	Since          time.Time `json:"since"`                     // When the device entered State
+}
+
+// RestartPolicy controls how the Manager restarts failed devices. The delay
+// starts at InitialBackoff and doubles after each consecutive failure up to
+// MaxBackoff; a device that ran for at least MaxBackoff before failing starts
+// over with InitialBackoff.
+type RestartPolicy struct {
+	MaxRestarts    int           // Consecutive restarts before giving up; negative means unlimited
+	InitialBackoff time.Duration // Delay before the first restart
+	MaxBackoff     time.Duration // Upper bound for the delay
+}
+
+// DefaultRestartPolicy is used by managers created with the package
+// constructors.
+var DefaultRestartPolicy = RestartPolicy{
+	MaxRestarts:    5,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// supervisor tracks one device, its configuration and its status. The
+// device is replaced, never mutated, when its configuration changes.
+type supervisor struct {
+	mu          sync.Mutex
+	dev         Device
+	cfg         config.DeviceConfig // Zero for devices not built from configuration
+	status      DeviceStatus
+	paused      bool
+	interrupted bool               // The current Run was cancelled by Pause or a reconfiguration
+	cancelRun   context.CancelFunc // Cancels the current Run
+	stop        context.CancelFunc // Stops the supervisor; set while it runs
+	done        chan struct{}      // Closed when the supervisor exits
+	wake        chan struct{}      // Signalled by Resume and reconfigurations
+	state       *deviceState       // Model kept across runs and reconfigurations
+	clock       simclock.Clock     // Created at the first launch, then kept
+}
+
+func newSupervisor(dev Device, cfg config.DeviceConfig) *supervisor {
+	return &supervisor{
+		dev: dev,
+		cfg: cfg,
+		status: DeviceStatus{
+			Name:           dev.Name(),
+			Type:           dev.Type(),
+			State:          StatePending,
+			TrapInterval:   cfg.TrapInterval,
+			SyslogInterval: cfg.SyslogInterval,
+			Since:          time.Now(),
 		},
+		wake:  make(chan struct{}, 1),
+		state: &deviceState{},
 	}
 }
 
-// Start launches all device simulators concurrently and blocks until all
-// have stopped (either via context cancellation or completion).
+// deviceFactory builds a Device from its configuration, returning an error if
+// the configuration is unsuitable for the device type.
+type deviceFactory func(cfg config.DeviceConfig) (Device, error)
+
+// deviceTypes maps the YAML `type` values to device constructors. The
+// appliances report:
 //
-// NOTE: Panics if no devices are configured. Consider returning an error
-// instead to allow callers to handle this gracefully.
-func (m *Manager) Start(ctx context.Context) {
-	if len(m.devices) == 0 {
-		panic("no devices configured for simulation")
+//	firewall      authentication failures, session floods, denied connections
+//	server        service restarts, resource alerts, login failures
+//	access-point  client association churn (alias ap)
+var deviceTypes = map[string]deviceFactory{
+	"router":       newRouterFromConfig,
+	"switch":       newSwitchFromConfig,
+	"firewall":     newApplianceFromConfig("firewall"),
+	"server":       newApplianceFromConfig("server"),
+	"access-point": newApplianceFromConfig("access-point"),
+	"ap":           newApplianceFromConfig("access-point"),
+}
+
+// NewManager creates a Manager with a default set of simulated devices.
+func NewManager() *Manager {
+	m, err := NewManagerFromConfig(&config.SimulatorConfig{Devices: []config.DeviceConfig{
+		{Name: "router-1", Type: "router", TrapInterval: 5},
+		{Name: "switch-1", Type: "switch", SyslogInterval: 7},
+	}})
+	if err != nil {
+		panic(err) // the default configuration is static and valid
+	}
+	return m
+}
+
+// NewManagerFromConfig creates a Manager with one device per entry in cfg,
+// after expanding name templates and counts. Unknown device types and
+// missing or invalid intervals are reported with the offending device name.
+func NewManagerFromConfig(cfg *config.SimulatorConfig) (*Manager, error) {
+	if cfg == nil {
+		return nil, errors.New("simulator config is nil")
+	}
+
+	devices, err := cfg.Expand()
+	if err != nil {
+		return nil, fmt.Errorf("invalid simulator config: %w", err)
+	}
+
+	m := &Manager{
+		Restart:      DefaultRestartPolicy,
+		Seed:         cfg.Seed,
+		trapTarget:   cfg.TrapTarget,
+		syslogTarget: cfg.SyslogTarget,
 	}
+	var errs []error
 
-	log.Printf("Starting simulator with %d devices", len(m.devices))
+	for _, d := range devices {
+		dev, err := buildDevice(d)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.devices = append(m.devices, newSupervisor(dev, d))
+	}
 
-	var wg sync.WaitGroup
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid simulator config: %w", errors.Join(errs...))
+	}
 
-	for _, d := range m.devices {
-		wg.Add(1)
-		log.Printf("Launching device simulator: %T", d)
+	return m, nil
+}
 
-		go func(dev Device) {
-			defer wg.Done()
-			dev.Run(ctx)
-		}(d)
+// buildDevice constructs a Device from an expanded configuration entry.
+func buildDevice(d config.DeviceConfig) (Device, error) {
+	factory, ok := deviceTypes[strings.ToLower(d.Type)]
+	if !ok {
+		return nil, fmt.Errorf("device %s: unknown type %q (supported: %s)",
+			d.Name, d.Type, strings.Join(SupportedTypes(), ", "))
 	}
 
-	wg.Wait()
+	dev, err := factory(d)
+	if err != nil {
+		return nil, fmt.Errorf("device %s: %w", d.Name, err)
+	}
+	return dev, nil
+}
+
+// LoadManager reads the YAML configuration at path and builds a Manager from it.
+func LoadManager(path string) (*Manager, error) {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load simulator config %s: %w", path, err)
+	}
+	return NewManagerFromConfig(cfg)
+}
+
+// SupportedTypes returns the device types accepted in configuration, sorted.
+func SupportedTypes() []string {
+	types := make([]string, 0, len(deviceTypes))
+	for t := range deviceTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Len returns the number of configured devices.
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.devices)
+}
+
+// Status returns a snapshot of every device's state, in configuration order.
+func (m *Manager) Status() []DeviceStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]DeviceStatus, len(m.devices))
+	for i, sv := range m.devices {
+		statuses[i] = sv.snapshot()
+	}
+	return statuses
+}
+
+// DeviceStatus returns the state of the named device.
+func (m *Manager) DeviceStatus(name string) (DeviceStatus, error) {
+	sv, err := m.lookup(name)
+	if err != nil {
+		return DeviceStatus{}, err
+	}
+	return sv.snapshot(), nil
+}
+
+// Start launches all device simulators concurrently and blocks until the
+// context is cancelled and every device has stopped, or until no device is
+// left running: each has either finished its simulated period (its clock
+// reached its end), failed or been removed. Devices that fail more often than the restart policy allows stay
+// in StateFailed. Start returns an error if there are no devices or if any
+// device ended in StateFailed.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if len(m.devices) == 0 {
+		m.mu.Unlock()
+		return errors.New("no devices configured for simulation")
+	}
+	if m.ctx != nil {
+		m.mu.Unlock()
+		return errors.New("simulator already started")
+	}
+
+	m.Seed = simclock.Seed(m.Seed)
+	log.Printf("Starting simulator with %d devices (seed %d)", len(m.devices), m.Seed)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	m.ctx = runCtx
+	m.settle = make(chan struct{}, 1)
+	for _, sv := range m.devices {
+		m.launch(sv)
+	}
+	m.mu.Unlock()
+
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-m.settle:
+			done = m.finished()
+		}
+	}
+	// Clearing ctx first keeps Add and Resume from launching devices
+	// while the running ones are waited for.
+	m.mu.Lock()
+	m.ctx = nil
+	devices := append([]*supervisor(nil), m.devices...)
+	m.mu.Unlock()
+
+	cancel()
+	m.wg.Wait()
 	log.Println("All device simulators stopped")
+
+	var errs []error
+	for _, sv := range devices {
+		if st := sv.snapshot(); st.State == StateFailed {
+			errs = append(errs, fmt.Errorf("device %s failed after %d restarts: %s", st.Name, st.Restarts, st.LastError))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// finished reports whether every device has stopped on its own or failed.
+// Devices never stop on their own on the wall clock, so there this only
+// ends runs in which every device failed or was removed.
+func (m *Manager) finished() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, sv := range m.devices {
+		switch sv.snapshot().State {
+		case StateStopped, StateFailed:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// settled wakes Start to check whether the run is finished.
+func (m *Manager) settled() {
+	select {
+	case m.settle <- struct{}{}:
+	default:
+	}
+}
+
+// launch starts the supervisor goroutine for sv, giving it a clock if it has
+// none yet. The caller must hold m.mu and m.ctx must be set.
+func (m *Manager) launch(sv *supervisor) {
+	ctx, stop := context.WithCancel(m.ctx)
+
+	sv.mu.Lock()
+	sv.stop = stop
+	sv.done = make(chan struct{})
+	sv.state.seed = deviceSeed(m.Seed, sv.dev.Name())
+	if sv.clock == nil {
+		sv.clock = m.newClock()
+	}
+	ctx = simclock.WithClock(ctx, sv.clock)
+	log.Printf("Launching device simulator: %s (%s)", sv.dev.Name(), sv.dev.Type())
+	sv.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer close(sv.done)
+		defer func() {
+			// Lets Resume and SetIntervals relaunch a device that stopped on its own.
+			sv.mu.Lock()
+			sv.stop = nil
+			sv.mu.Unlock()
+		}()
+		defer stop()
+		m.supervise(ctx, sv)
+	}()
+}
+
+// newClock creates a device clock from m.Clock.
+func (m *Manager) newClock() simclock.Clock {
+	if m.Clock == nil {
+		return simclock.Real
+	}
+	return m.Clock()
+}
+
+// now returns the simulation's current time: the latest time any device's
+// clock has reached, or a new clock's start if no device has run yet. The
+// caller must hold m.mu.
+func (m *Manager) now() time.Time {
+	var latest time.Time
+	for _, sv := range m.devices {
+		sv.mu.Lock()
+		if sv.clock != nil && sv.clock.Now().After(latest) {
+			latest = sv.clock.Now()
+		}
+		sv.mu.Unlock()
+	}
+	if latest.IsZero() {
+		return m.newClock().Now()
+	}
+	return latest
+}
+
+// deviceSeed derives a device's seed from the Manager's seed and the
+// device's name.
+func deviceSeed(seed int64, name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return seed + int64(h.Sum64())
+}
+
+// Add creates devices from a configuration entry (name ranges and counts are
+// expanded; unset targets default to the manager's) and starts them if the
+// Manager is running. No device is added if any name is already in use.
+func (m *Manager) Add(d config.DeviceConfig) ([]DeviceStatus, error) {
+	cfg := config.SimulatorConfig{
+		TrapTarget:   m.trapTarget,
+		SyslogTarget: m.syslogTarget,
+		Devices:      []config.DeviceConfig{d},
+	}
+	expanded, err := cfg.Expand()
+	if err != nil {
+		return nil, err
+	}
+
+	added := make([]*supervisor, 0, len(expanded))
+	for _, dc := range expanded {
+		dev, err := buildDevice(dc)
+		if err != nil {
+			return nil, err
+		}
+		added = append(added, newSupervisor(dev, dc))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, sv := range added {
+		if m.find(sv.dev.Name()) != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDeviceExists, sv.dev.Name())
+		}
+	}
+
+	var now time.Time
+	if m.ctx != nil {
+		now = m.now()
+	}
+
+	statuses := make([]DeviceStatus, len(added))
+	for i, sv := range added {
+		m.devices = append(m.devices, sv)
+		if m.ctx != nil {
+			sv.clock = simclock.At(m.newClock(), now)
+			m.launch(sv)
+		}
+		statuses[i] = sv.snapshot()
+	}
+	return statuses, nil
+}
+
+// Remove stops the named device, waits for it to exit and removes it.
+func (m *Manager) Remove(name string) error {
+	m.mu.Lock()
+	idx := -1
+	for i, sv := range m.devices {
+		if sv.dev.Name() == name {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrDeviceNotFound, name)
+	}
+	sv := m.devices[idx]
+	m.devices = append(m.devices[:idx:idx], m.devices[idx+1:]...)
+	m.mu.Unlock()
+
+	sv.mu.Lock()
+	stop, done := sv.stop, sv.done
+	sv.mu.Unlock()
+
+	if stop != nil {
+		stop()
+		<-done
+	}
+	m.settled()
+	return nil
+}
+
+// Pause stops the named device's telemetry until Resume is called. Pausing
+// does not count as a failure or a restart.
+func (m *Manager) Pause(name string) error {
+	sv, err := m.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	if !sv.paused {
+		sv.paused = true
+		sv.interruptLocked()
+		if sv.status.State != StateFailed && sv.status.State != StateStopped {
+			sv.setStateLocked(StatePaused, nil)
+		}
+	}
+	return nil
+}
+
+// Resume restarts a device that was paused while it was running or waiting
+// to restart, or one that stopped on its own (its clock reached its end). It
+// is a no-op for other devices: a failed device stays failed, even if it was
+// paused, and is only revived by SetIntervals.
+func (m *Manager) Resume(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sv := m.find(name)
+	if sv == nil {
+		return fmt.Errorf("%w: %s", ErrDeviceNotFound, name)
+	}
+
+	sv.mu.Lock()
+	exited := sv.stop == nil
+	resume := (sv.paused && sv.status.State == StatePaused) || (exited && sv.status.State == StateStopped)
+	sv.paused = false
+	if resume {
+		if exited {
+			sv.setStateLocked(StatePending, nil)
+		} else {
+			sv.signal()
+		}
+	}
+	sv.mu.Unlock()
+
+	if resume && exited && m.ctx != nil {
+		m.launch(sv)
+	}
+	return nil
+}
+
+// SetIntervals changes the named device's trap and syslog intervals
+// (seconds). A nil value keeps the current interval. The device is rebuilt
+// with the new configuration and, if running, restarted immediately; a
+// failed device is revived, and one that stopped on its own is relaunched if
+// the Manager is running. The change is rejected if the device type does not
+// accept it.
+func (m *Manager) SetIntervals(name string, trapInterval, syslogInterval *int) (DeviceStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sv := m.find(name)
+	if sv == nil {
+		return DeviceStatus{}, fmt.Errorf("%w: %s", ErrDeviceNotFound, name)
+	}
+
+	sv.mu.Lock()
+	if sv.cfg.Type == "" {
+		sv.mu.Unlock()
+		return DeviceStatus{}, fmt.Errorf("device %s was not built from configuration and cannot be reconfigured", name)
+	}
+
+	cfg := sv.cfg
+	if trapInterval != nil {
+		cfg.TrapInterval = *trapInterval
+	}
+	if syslogInterval != nil {
+		cfg.SyslogInterval = *syslogInterval
+	}
+	if cfg.TrapInterval < 0 || cfg.SyslogInterval < 0 {
+		sv.mu.Unlock()
+		return DeviceStatus{}, fmt.Errorf("device %s: intervals must not be negative", name)
+	}
+
+	dev, err := buildDevice(cfg)
+	if err != nil {
+		sv.mu.Unlock()
+		return DeviceStatus{}, err
+	}
+
+	sv.dev, sv.cfg = dev, cfg
+	sv.status.TrapInterval, sv.status.SyslogInterval = cfg.TrapInterval, cfg.SyslogInterval
+	relaunch := sv.stop == nil && m.ctx != nil
+	if relaunch {
+		sv.setStateLocked(StatePending, nil)
+	} else {
+		sv.interruptLocked()
+		sv.signal()
+	}
+	sv.mu.Unlock()
+
+	if relaunch {
+		m.launch(sv)
+	}
+	return sv.snapshot(), nil
+}
+
+func (m *Manager) lookup(name string) (*supervisor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if sv := m.find(name); sv != nil {
+		return sv, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrDeviceNotFound, name)
+}
+
+// find returns the named device's supervisor. The caller must hold m.mu.
+func (m *Manager) find(name string) *supervisor {
+	for _, sv := range m.devices {
+		if sv.dev.Name() == name {
+			return sv
+		}
+	}
+	return nil
+}
+
+// supervise runs a device until the context is cancelled, restarting it with
+// exponential backoff when it fails and holding it while paused.
+func (m *Manager) supervise(ctx context.Context, sv *supervisor) {
+	policy := m.Restart
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = DefaultRestartPolicy.InitialBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+
+	backoff := policy.InitialBackoff
+	consecutive := 0
+	restart := false // The next run follows a failure
+
+	ctx = withState(ctx, sv.state)
+
+	for {
+		dev, runCtx, ok := sv.begin(ctx, restart)
+		if !ok {
+			if !sv.waitResume(ctx) {
+				sv.setState(StateStopped, nil)
+				return
+			}
+			continue
+		}
+
+		restart = false
+		started := time.Now()
+		err := runDevice(runCtx, dev)
+		interrupted := sv.end()
+
+		if ctx.Err() != nil {
+			sv.setState(StateStopped, nil)
+			return
+		}
+		if interrupted {
+			consecutive, backoff = 0, policy.InitialBackoff
+			continue
+		}
+		if err == nil {
+			sv.setState(StateStopped, nil)
+			m.settled()
+			return
+		}
+
+		log.Printf("Device %s failed: %v", dev.Name(), err)
+
+		if time.Since(started) >= policy.MaxBackoff {
+			consecutive, backoff = 0, policy.InitialBackoff
+		}
+		if policy.MaxRestarts >= 0 && consecutive >= policy.MaxRestarts {
+			sv.setState(StateFailed, err)
+			m.settled()
+			// A failed device can still be revived by a reconfiguration.
+			if !sv.waitResume(ctx) {
+				return
+			}
+			consecutive, backoff = 0, policy.InitialBackoff
+			continue
+		}
+		consecutive++
+		sv.setState(StateRestarting, err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			sv.setState(StateStopped, nil)
+			return
+		case <-sv.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		restart = true
+		backoff = min(backoff*2, policy.MaxBackoff)
+	}
+}
+
+// runDevice calls dev.Run, converting a panic into an error so a crashing
+// device cannot take down the simulator.
+func runDevice(ctx context.Context, dev Device) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return dev.Run(ctx)
+}
+
+// begin marks the device running and returns it with a run context that
+// Pause and SetIntervals can cancel, counting the run as a restart if it
+// follows a failure. It reports false if the device is paused.
+func (sv *supervisor) begin(ctx context.Context, restart bool) (Device, context.Context, bool) {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	sv.interrupted = false
+	if sv.paused {
+		if sv.status.State != StatePaused {
+			sv.setStateLocked(StatePaused, nil)
+		}
+		return nil, nil, false
+	}
+
+	// Drop any stale wake-up; the device is about to run anyway.
+	select {
+	case <-sv.wake:
+	default:
+	}
+
+	if restart {
+		sv.status.Restarts++
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	sv.cancelRun = cancel
+	sv.setStateLocked(StateRunning, nil)
+	return sv.dev, runCtx, true
+}
+
+// end releases the run context and reports whether the run was cancelled on
+// purpose by Pause or SetIntervals rather than failing.
+func (sv *supervisor) end() (interrupted bool) {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	sv.cancelRun()
+	sv.cancelRun = nil
+	interrupted = sv.interrupted
+	sv.interrupted = false
+	return interrupted
+}
+
+// waitResume blocks until the device is resumed or reconfigured, returning
+// false if the context is cancelled first.
+func (sv *supervisor) waitResume(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-sv.wake:
+		return true
+	}
+}
+
+// interruptLocked cancels the current Run, if any. The caller must hold sv.mu.
+func (sv *supervisor) interruptLocked() {
+	if sv.cancelRun != nil {
+		sv.interrupted = true
+		sv.cancelRun()
+	}
+}
+
+// signal wakes a supervisor waiting in pause, failure or backoff.
+func (sv *supervisor) signal() {
+	select {
+	case sv.wake <- struct{}{}:
+	default:
+	}
+}
+
+// setState records a state change. A nil err keeps the previous LastError.
+func (sv *supervisor) setState(state State, err error) {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	sv.setStateLocked(state, err)
+}
+
+func (sv *supervisor) setStateLocked(state State, err error) {
+	sv.status.State = state
+	sv.status.Since = time.Now()
+	if err != nil {
+		sv.status.LastError = err.Error()
+	}
+}
+
+func (sv *supervisor) snapshot() DeviceStatus {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	return sv.status
 }
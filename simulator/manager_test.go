@@ -0,0 +1,497 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/config"
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+	"github.com/ibm-live-project-interns/datasource/pkg/snmptrap"
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
+)
+
+func TestNewManagerFromConfig(t *testing.T) {
+	cfg := &config.SimulatorConfig{Devices: []config.DeviceConfig{
+		{Name: "router-{01..50}", Type: "router", TrapInterval: 5},
+		{Name: "switch-1", Type: "Switch", SyslogInterval: 10},
+	}}
+
+	m, err := NewManagerFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if m.Len() != 51 {
+		t.Fatalf("expected 51 devices, got %d", m.Len())
+	}
+
+	r, ok := m.devices[0].dev.(*Router)
+	if !ok || r.name != "router-01" || r.trapInterval.Seconds() != 5 {
+		t.Errorf("unexpected first device: %+v", m.devices[0].dev)
+	}
+}
+
+func TestNewManagerFromConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		device  config.DeviceConfig
+		wantErr string
+	}{
+		{"unknown type", config.DeviceConfig{Name: "lb-1", Type: "loadbalancer"}, `device lb-1: unknown type "loadbalancer" (supported: access-point, ap, firewall, router, server, switch)`},
+		{"router without trap interval", config.DeviceConfig{Name: "r1", Type: "router"}, "device r1: router requires a positive trap_interval"},
+		{"switch without syslog interval", config.DeviceConfig{Name: "s1", Type: "switch", TrapInterval: 5}, "device s1: switch requires a positive syslog_interval"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewManagerFromConfig(&config.SimulatorConfig{Devices: []config.DeviceConfig{tt.device}})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// flakyDevice fails the first failures runs, then runs until cancelled.
+type flakyDevice struct {
+	name     string
+	failures int32
+	runs     atomic.Int32
+	panics   bool
+}
+
+func (d *flakyDevice) Name() string { return d.name }
+func (d *flakyDevice) Type() string { return "fake" }
+
+func (d *flakyDevice) Run(ctx context.Context) error {
+	if d.runs.Add(1) <= d.failures {
+		if d.panics {
+			panic("boom")
+		}
+		return errors.New("link lost")
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func fastRestarts(maxRestarts int) RestartPolicy {
+	return RestartPolicy{MaxRestarts: maxRestarts, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestManager_StartEmpty(t *testing.T) {
+	if err := (&Manager{}).Start(context.Background()); err == nil {
+		t.Fatal("expected error for empty device list")
+	}
+}
+
+func TestManager_RestartsFailedDevice(t *testing.T) {
+	dev := &flakyDevice{name: "r1", failures: 2, panics: true}
+	m := &Manager{Restart: fastRestarts(5), devices: []*supervisor{newSupervisor(dev, config.DeviceConfig{})}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		st := m.Status()[0]
+		if st.State == StateRunning && st.Restarts == 2 {
+			if st.LastError != "panic: boom" {
+				t.Errorf("expected last error from panic, got %q", st.LastError)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("device not restarted: %+v", st)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected clean stop, got %v", err)
+	}
+	if st := m.Status()[0]; st.State != StateStopped {
+		t.Errorf("expected stopped, got %s", st.State)
+	}
+}
+
+func TestManager_GivesUpAfterMaxRestarts(t *testing.T) {
+	dev := &flakyDevice{name: "r1", failures: 100}
+	healthy := &flakyDevice{name: "s1"}
+	m := &Manager{Restart: fastRestarts(3), devices: []*supervisor{newSupervisor(dev, config.DeviceConfig{}), newSupervisor(healthy, config.DeviceConfig{})}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for m.Status()[0].State != StateFailed {
+		if time.Now().After(deadline) {
+			t.Fatalf("device never marked failed: %+v", m.Status()[0])
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if m.Status()[1].State != StateRunning {
+		t.Errorf("expected healthy device still running, got %s", m.Status()[1].State)
+	}
+
+	cancel()
+	err := <-done
+	if err == nil || !strings.Contains(err.Error(), "device r1 failed after 3 restarts: link lost") {
+		t.Errorf("unexpected Start error: %v", err)
+	}
+	if runs := dev.runs.Load(); runs != 4 {
+		t.Errorf("expected 4 runs (1 + 3 restarts), got %d", runs)
+	}
+}
+
+func TestManager_BackfillEndsOnItsOwn(t *testing.T) {
+	trapConn, trapAddr := listenUDP(t)
+	syslogConn, syslogAddr := listenUDP(t)
+
+	m, err := NewManagerFromConfig(&config.SimulatorConfig{
+		TrapTarget:   trapAddr,
+		SyslogTarget: syslogAddr,
+		Devices: []config.DeviceConfig{
+			{Name: "fw-1", Type: "firewall", TrapInterval: 60, SyslogInterval: 300},
+		},
+	})
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	m.Clock = func() simclock.Clock { return simclock.NewBackfill(start, start.Add(time.Hour)) }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("expected the backfill to finish without cancellation")
+	}
+	if st := m.Status()[0]; st.State != StateStopped {
+		t.Errorf("expected stopped device, got %s", st.State)
+	}
+
+	// One trap a minute and one message every five minutes, in time order
+	var last time.Time
+	for i := 0; i < 60; i++ {
+		var trap snmptrap.Trap
+		if err := json.Unmarshal(receive(t, trapConn), &trap); err != nil {
+			t.Fatalf("trap %d: %v", i, err)
+		}
+		if want := start.Add(time.Duration(i+1) * time.Minute); !trap.Timestamp.Equal(want) {
+			t.Fatalf("trap %d: expected timestamp %v, got %v", i, want, trap.Timestamp)
+		}
+		last = trap.Timestamp
+	}
+	if !last.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected the last trap at the end of the period, got %v", last)
+	}
+	for i := 0; i < 12; i++ {
+		want := start.Add(time.Duration(i+1) * 5 * time.Minute).Format(time.RFC3339)
+		if msg := string(receive(t, syslogConn)); !strings.Contains(msg, " "+want+" fw-1 ") {
+			t.Fatalf("syslog %d: expected timestamp %s, got %q", i, want, msg)
+		}
+	}
+}
+
+func TestManager_StopsWhenAllDevicesFail(t *testing.T) {
+	m := &Manager{Restart: fastRestarts(1), devices: []*supervisor{
+		newSupervisor(&flakyDevice{name: "r1", failures: 100}, config.DeviceConfig{}),
+		newSupervisor(&flakyDevice{name: "r2", failures: 100}, config.DeviceConfig{}),
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := m.Start(ctx)
+	if ctx.Err() != nil {
+		t.Fatal("expected Start to return once every device failed")
+	}
+	if err == nil || !strings.Contains(err.Error(), "device r1 failed") || !strings.Contains(err.Error(), "device r2 failed") {
+		t.Errorf("unexpected Start error: %v", err)
+	}
+}
+
+// endingDevice stops on its own, like a device on a bounded clock.
+type endingDevice struct {
+	runs atomic.Int32
+}
+
+func (d *endingDevice) Name() string { return "r1" }
+func (d *endingDevice) Type() string { return "fake" }
+
+func (d *endingDevice) Run(context.Context) error {
+	d.runs.Add(1)
+	return nil
+}
+
+func TestManager_ResumesStoppedDevice(t *testing.T) {
+	dev := &endingDevice{}
+	m := &Manager{devices: []*supervisor{
+		newSupervisor(dev, config.DeviceConfig{}),
+		newSupervisor(&flakyDevice{name: "s1"}, config.DeviceConfig{}),
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	waitFor := func(runs int32) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for dev.runs.Load() < runs || m.Status()[0].State != StateStopped {
+			if time.Now().After(deadline) {
+				t.Fatalf("device did not stop after run %d: %+v", runs, m.Status()[0])
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitFor(1)
+	if err := m.Resume("r1"); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	waitFor(2)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected clean stop, got %v", err)
+	}
+}
+
+func TestManager_ResumeKeepsFailedDeviceFailed(t *testing.T) {
+	dev := &flakyDevice{name: "r1", failures: 100}
+	m := &Manager{Restart: fastRestarts(1), devices: []*supervisor{
+		newSupervisor(dev, config.DeviceConfig{}),
+		newSupervisor(&flakyDevice{name: "s1"}, config.DeviceConfig{}),
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	waitState(t, m, "r1", StateFailed)
+	runs := dev.runs.Load()
+	if err := m.Pause("r1"); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if err := m.Resume("r1"); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if st := m.Status()[0]; st.State != StateFailed || st.Restarts != 1 || dev.runs.Load() != runs {
+		t.Errorf("expected the failed device to stay failed, got %+v after %d runs", st, dev.runs.Load())
+	}
+
+	cancel()
+	<-done
+}
+
+func TestManager_PausedBackoffIsNotARestart(t *testing.T) {
+	dev := &flakyDevice{name: "r1", failures: 1}
+	m := &Manager{
+		Restart: RestartPolicy{MaxRestarts: 5, InitialBackoff: 50 * time.Millisecond, MaxBackoff: time.Second},
+		devices: []*supervisor{newSupervisor(dev, config.DeviceConfig{})},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	waitState(t, m, "r1", StateRestarting)
+	if err := m.Pause("r1"); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if st := m.Status()[0]; st.State != StatePaused || st.Restarts != 0 {
+		t.Errorf("expected a paused device without restarts, got %+v", st)
+	}
+
+	if err := m.Resume("r1"); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if st := waitState(t, m, "r1", StateRunning); st.Restarts != 1 {
+		t.Errorf("expected one restart after resuming, got %d", st.Restarts)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected clean stop, got %v", err)
+	}
+}
+
+func TestManager_SetIntervalsRelaunchesStoppedDevice(t *testing.T) {
+	dev := &endingDevice{}
+	deviceTypes["ending"] = func(config.DeviceConfig) (Device, error) { return dev, nil }
+	defer delete(deviceTypes, "ending")
+
+	m := &Manager{devices: []*supervisor{
+		newSupervisor(dev, config.DeviceConfig{Name: "r1", Type: "ending"}),
+		newSupervisor(&flakyDevice{name: "s1"}, config.DeviceConfig{}),
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	waitState(t, m, "r1", StateStopped)
+	trap := 30
+	st, err := m.SetIntervals("r1", &trap, nil)
+	if err != nil {
+		t.Fatalf("set intervals: %v", err)
+	}
+	if st.TrapInterval != 30 {
+		t.Errorf("expected the new interval, got %+v", st)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for dev.runs.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("device was not relaunched: %+v", m.Status()[0])
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected clean stop, got %v", err)
+	}
+}
+
+// clockedDevice records its clock's time at the start of every run, then
+// advances the clock by an hour and fails the first failures runs.
+type clockedDevice struct {
+	name     string
+	failures int
+	mu       sync.Mutex
+	seen     []time.Time
+	slept    int
+}
+
+func (d *clockedDevice) Name() string { return d.name }
+func (d *clockedDevice) Type() string { return "fake" }
+
+func (d *clockedDevice) Run(ctx context.Context) error {
+	clock := simclock.FromContext(ctx)
+	d.mu.Lock()
+	d.seen = append(d.seen, clock.Now())
+	runs := len(d.seen)
+	d.mu.Unlock()
+
+	if err := clock.Sleep(ctx, time.Hour); err != nil {
+		return nil
+	}
+	d.mu.Lock()
+	d.slept++
+	d.mu.Unlock()
+
+	if runs <= d.failures {
+		return errors.New("link lost")
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (d *clockedDevice) snapshot() ([]time.Time, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]time.Time(nil), d.seen...), d.slept
+}
+
+func TestManager_DevicesKeepTheirClock(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	dev := &clockedDevice{name: "r1", failures: 2}
+	late := &clockedDevice{name: "late"}
+	deviceTypes["clocked"] = func(config.DeviceConfig) (Device, error) { return late, nil }
+	defer delete(deviceTypes, "clocked")
+
+	m := &Manager{
+		Restart: fastRestarts(5),
+		Clock:   func() simclock.Clock { return simclock.NewManual(start) },
+		devices: []*supervisor{newSupervisor(dev, config.DeviceConfig{})},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for _, slept := dev.snapshot(); slept < 3; _, slept = dev.snapshot() {
+		if time.Now().After(deadline) {
+			t.Fatalf("device did not run three times: %+v", m.Status()[0])
+		}
+		time.Sleep(time.Millisecond)
+	}
+	seen, _ := dev.snapshot()
+	for i, at := range seen {
+		if want := start.Add(time.Duration(i) * time.Hour); !at.Equal(want) {
+			t.Errorf("run %d: expected the clock at %v, got %v", i+1, want, at)
+		}
+	}
+
+	if _, err := m.Add(config.DeviceConfig{Name: "late", Type: "clocked"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	for seen, _ := late.snapshot(); len(seen) == 0; seen, _ = late.snapshot() {
+		if time.Now().After(deadline) {
+			t.Fatal("added device did not run")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if seen, _ := late.snapshot(); !seen[0].Equal(start.Add(3 * time.Hour)) {
+		t.Errorf("expected the added device to start at the current simulated time, got %v", seen[0])
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected clean stop, got %v", err)
+	}
+}
+
+func TestManager_SameSeedSameTelemetry(t *testing.T) {
+	profile, err := traffic.Preset("poisson")
+	if err != nil {
+		t.Fatalf("preset: %v", err)
+	}
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	run := func(seed int64) []string {
+		conn, addr := listenUDP(t)
+		m, err := NewManagerFromConfig(&config.SimulatorConfig{
+			TrapTarget: addr,
+			Seed:       seed,
+			Devices:    []config.DeviceConfig{{Name: "r1", Type: "router", TrapInterval: 60, Profile: profile}},
+		})
+		if err != nil {
+			t.Fatalf("config: %v", err)
+		}
+		m.Clock = func() simclock.Clock { return simclock.NewBackfill(start, start.Add(time.Hour)) }
+
+		done := make(chan error, 1)
+		go func() { done <- m.Start(context.Background()) }()
+		var traps []string
+		for i := 0; i < 20; i++ {
+			traps = append(traps, string(receive(t, conn)))
+		}
+		<-done
+		return traps
+	}
+
+	a, b := run(42), run(42)
+	if !slices.Equal(a, b) {
+		t.Errorf("expected the same traps from the same seed:\n%q\n%q", a, b)
+	}
+	if c := run(43); slices.Equal(a, c) {
+		t.Error("expected different traps from a different seed")
+	}
+}
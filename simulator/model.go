@@ -0,0 +1,554 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+	"github.com/ibm-live-project-interns/datasource/pkg/snmptrap"
+	"github.com/ibm-live-project-interns/datasource/pkg/syslogsim"
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
+)
+
+// model is the internal state of a simulated device. Telemetry is derived
+// from its state transitions, so a device never reports an interface as up
+// in syslog while its last trap said it was down, and every problem (link
+// down, BGP session lost, CPU spike, ...) is eventually followed by the
+// matching recovery.
+//
+// A model is owned by one Run goroutine at a time and is not safe for
+// concurrent use; deviceState carries it from one run to the next. It
+// starts healthy: all interfaces up, BGP sessions established, services
+// running and CPU/memory below their thresholds.
+type model struct {
+	rng  *rand.Rand
+	kind string
+
+	ifaces   []*iface
+	peers    []*bgpPeer
+	services []*service
+	clients  []wifiClient // Associated wireless clients (access points)
+
+	cpu, mem         int // Utilization in percent
+	cpuHigh, memHigh bool
+	flood            bool // Firewall connection limit exceeded
+	sessions         int  // Firewall embryonic sessions
+
+	actions    []action
+	severities map[string]float64 // Transition weights by syslog severity; see step
+}
+
+type iface struct {
+	name   string
+	index  int
+	operUp bool
+}
+
+type bgpPeer struct {
+	addr string
+	as   int
+	up   bool
+}
+
+type service struct {
+	name string
+	up   bool
+}
+
+type wifiClient struct {
+	mac  string
+	ssid string
+}
+
+// action is a kind of state transition and its relative likelihood. fn
+// reports false if the transition is not possible in the current state.
+type action struct {
+	weight int
+	fn     func(*model) (change, bool)
+}
+
+// change is one state transition rendered as both a trap and a syslog line.
+type change struct {
+	app      string // Syslog APP-NAME
+	msgid    string // Syslog MSGID, the mnemonic in the Cisco dialect
+	severity syslogsim.Severity
+	message  string // Syslog MSG
+	trap     snmptrap.TrapTemplate
+	vars     map[string]string // Trap variable bindings
+}
+
+// Trap renders the change as an SNMP trap from source, timestamped at.
+func (c change) Trap(source string, at time.Time) snmptrap.Trap {
+	trap := snmptrap.NewTrap(c.trap, source, c.vars)
+	trap.Timestamp = at.UTC()
+	return trap
+}
+
+// Syslog renders the change as a message from hostname in dialect,
+// timestamped at. seq numbers the device's messages for dialects that show
+// it.
+func (c change) Syslog(hostname string, at time.Time, dialect syslogsim.Dialect, seq int) string {
+	m := syslogsim.Message{
+		Timestamp: at.UTC(),
+		Facility:  syslogsim.FacilityUser,
+		Severity:  c.severity,
+		Hostname:  hostname,
+		AppName:   c.app,
+		MsgID:     c.msgid,
+		Text:      c.message,
+		Sequence:  seq,
+	}
+	return m.Format(dialect)
+}
+
+// Utilization thresholds: crossing high raises an alarm, which clears when
+// the value drops back to normal.
+const (
+	highUtilization   = 85
+	normalUtilization = 60
+)
+
+// newModel creates the initial state for a device of the given kind.
+func newModel(kind string, rng *rand.Rand) *model {
+	m := &model{rng: rng, kind: kind}
+	m.cpu = 10 + rng.Intn(30)
+	m.mem = 30 + rng.Intn(20)
+
+	switch kind {
+	case "router":
+		m.addIfaces("GigabitEthernet0/%d", 0, 4)
+		m.peers = []*bgpPeer{
+			{addr: fmt.Sprintf("10.255.%d.1", rng.Intn(255)), as: 65001, up: true},
+			{addr: fmt.Sprintf("10.255.%d.1", rng.Intn(255)), as: 65002, up: true},
+		}
+		m.actions = []action{{4, (*model).ifaceChange}, {3, (*model).bgpChange}, {2, (*model).cpuChange}, {1, (*model).memChange}}
+	case "switch":
+		m.addIfaces("GigabitEthernet1/0/%d", 1, 8)
+		m.actions = []action{{6, (*model).ifaceChange}, {2, (*model).cpuChange}, {1, (*model).memChange}}
+	case "firewall":
+		m.ifaces = []*iface{{"outside", 1, true}, {"inside", 2, true}, {"dmz", 3, true}}
+		m.actions = []action{{2, (*model).ifaceChange}, {3, (*model).sessionChange}, {3, (*model).authFailure}, {1, (*model).cpuChange}, {1, (*model).memChange}}
+	case "server":
+		m.ifaces = []*iface{{"eth0", 1, true}, {"eth1", 2, true}}
+		m.services = []*service{{"nginx", true}, {"postgres", true}, {"redis", true}, {"sshd", true}}
+		m.actions = []action{{1, (*model).ifaceChange}, {4, (*model).serviceChange}, {2, (*model).cpuChange}, {2, (*model).memChange}}
+	case "access-point":
+		m.ifaces = []*iface{{"radio0", 1, true}, {"radio1", 2, true}}
+		m.actions = []action{{1, (*model).ifaceChange}, {6, (*model).clientChange}, {1, (*model).cpuChange}, {1, (*model).memChange}}
+	default:
+		m.addIfaces("eth%d", 0, 2)
+		m.actions = []action{{3, (*model).ifaceChange}, {1, (*model).cpuChange}, {1, (*model).memChange}}
+	}
+	return m
+}
+
+// deviceState keeps a device's random seed and model across runs. The
+// Manager holds one per device, so a restart, pause or reconfiguration
+// continues from the state the device was in, and an outstanding fault
+// still gets its recovery.
+type deviceState struct {
+	seed  int64 // Base of the device's random streams
+	model *model
+}
+
+type stateKey struct{}
+
+// withState returns a context carrying st for the device runs it starts.
+func withState(ctx context.Context, st *deviceState) context.Context {
+	return context.WithValue(ctx, stateKey{}, st)
+}
+
+// loadModel returns the model kept in ctx's device state, creating it with
+// newModel on the first run, and weighted by the severity mix of profile,
+// if any. Without a device state (a device run outside a Manager), every
+// run starts from a healthy model.
+func loadModel(ctx context.Context, kind string, profile *traffic.Profile) *model {
+	st, ok := ctx.Value(stateKey{}).(*deviceState)
+	if !ok {
+		st = &deviceState{}
+	}
+	if st.model == nil {
+		st.model = newModel(kind, deviceRand(ctx, 0))
+	}
+	st.model.severities = nil
+	if profile != nil {
+		st.model.severities = profile.Severities
+	}
+	return st.model
+}
+
+// deviceRand returns the device's random stream n: 0 drives its model, the
+// others its traffic schedules. Streams are seeded from ctx's device state,
+// or from the clock for a device run outside a Manager.
+func deviceRand(ctx context.Context, n int64) *rand.Rand {
+	seed := simclock.Seed(0)
+	if st, ok := ctx.Value(stateKey{}).(*deviceState); ok {
+		seed = st.seed
+	}
+	return rand.New(rand.NewSource(seed + n))
+}
+
+func (m *model) addIfaces(format string, first, n int) {
+	for i := 0; i < n; i++ {
+		m.ifaces = append(m.ifaces, &iface{name: fmt.Sprintf(format, first+i), index: i + 1, operUp: true})
+	}
+}
+
+// maxSeverityDraws bounds how many transitions step tries for the severity
+// it drew, so a severity the device cannot report does not stall it.
+const maxSeverityDraws = 20
+
+// step advances the model by one state transition and returns it. With
+// severity weights, it draws a severity first and then a transition that
+// reports it, trying each candidate on a copy of the model; if none does
+// within maxSeverityDraws, the last candidate is taken.
+func (m *model) step() change {
+	if len(m.severities) == 0 {
+		return m.transition()
+	}
+	want, _ := syslogsim.ParseSeverity(traffic.PickSeverity(m.rng, m.severities))
+
+	var next *model
+	var c change
+	for i := 0; i < maxSeverityDraws && (next == nil || c.severity != want); i++ {
+		next = m.clone()
+		c = next.transition()
+	}
+	*m = *next
+	return c
+}
+
+// clone returns a copy of the model sharing only its random source, actions
+// and severity weights.
+func (m *model) clone() *model {
+	c := *m
+	c.ifaces = clonePointers(m.ifaces)
+	c.peers = clonePointers(m.peers)
+	c.services = clonePointers(m.services)
+	c.clients = slices.Clone(m.clients)
+	return &c
+}
+
+func clonePointers[T any](s []*T) []*T {
+	out := make([]*T, len(s))
+	for i, p := range s {
+		v := *p
+		out[i] = &v
+	}
+	return out
+}
+
+// transition applies one state transition, drawn by action weight, and
+// returns it.
+func (m *model) transition() change {
+	total := 0
+	for _, a := range m.actions {
+		total += a.weight
+	}
+
+	for {
+		n := m.rng.Intn(total)
+		for _, a := range m.actions {
+			if n -= a.weight; n < 0 {
+				if c, ok := a.fn(m); ok {
+					return c
+				}
+				break
+			}
+		}
+	}
+}
+
+// recover reports whether to repair an existing fault rather than introduce
+// a new one. Faults are usually repaired, so they do not accumulate.
+func (m *model) recover(faults, healthy int) bool {
+	return faults > 0 && (healthy == 0 || m.rng.Float64() < 0.7)
+}
+
+func (m *model) ifaceChange() (change, bool) {
+	var up, down []*iface
+	for _, i := range m.ifaces {
+		if i.operUp {
+			up = append(up, i)
+		} else {
+			down = append(down, i)
+		}
+	}
+	if len(m.ifaces) == 0 {
+		return change{}, false
+	}
+
+	var i *iface
+	if m.recover(len(down), len(up)) {
+		i = down[m.rng.Intn(len(down))]
+	} else {
+		i = up[m.rng.Intn(len(up))]
+	}
+	i.operUp = !i.operUp
+	return linkChange(i.name, i.index, i.operUp), true
+}
+
+func (m *model) bgpChange() (change, bool) {
+	var up, down []*bgpPeer
+	for _, p := range m.peers {
+		if p.up {
+			up = append(up, p)
+		} else {
+			down = append(down, p)
+		}
+	}
+	if len(m.peers) == 0 {
+		return change{}, false
+	}
+
+	var p *bgpPeer
+	if m.recover(len(down), len(up)) {
+		p = down[m.rng.Intn(len(down))]
+	} else {
+		p = up[m.rng.Intn(len(up))]
+	}
+	p.up = !p.up
+	return bgpChange(p.addr, p.as, p.up), true
+}
+
+func (m *model) cpuChange() (change, bool) {
+	if m.cpuHigh {
+		m.cpu = 15 + m.rng.Intn(normalUtilization-15)
+	} else {
+		m.cpu = highUtilization + m.rng.Intn(100-highUtilization)
+	}
+	m.cpuHigh = !m.cpuHigh
+	return cpuChange(m.cpu, m.cpuHigh), true
+}
+
+func (m *model) memChange() (change, bool) {
+	if m.memHigh {
+		m.mem = 30 + m.rng.Intn(normalUtilization-30)
+	} else {
+		m.mem = highUtilization + m.rng.Intn(100-highUtilization)
+	}
+	m.memHigh = !m.memHigh
+	return memChange(m.mem, m.memHigh), true
+}
+
+func (m *model) serviceChange() (change, bool) {
+	var up, down []*service
+	for _, s := range m.services {
+		if s.up {
+			up = append(up, s)
+		} else {
+			down = append(down, s)
+		}
+	}
+	if len(m.services) == 0 {
+		return change{}, false
+	}
+
+	var s *service
+	if m.recover(len(down), len(up)) {
+		s = down[m.rng.Intn(len(down))]
+	} else {
+		s = up[m.rng.Intn(len(up))]
+	}
+	s.up = !s.up
+	return serviceChange(s.name, s.up), true
+}
+
+func (m *model) sessionChange() (change, bool) {
+	if m.flood {
+		m.sessions = 100 + m.rng.Intn(400)
+	} else {
+		m.sessions = 5000 + m.rng.Intn(5000)
+	}
+	m.flood = !m.flood
+	return sessionChange(m.sessions, randomAddr(m.rng), m.flood), true
+}
+
+// authFailure is a stateless event: failed logins do not change device state.
+func (m *model) authFailure() (change, bool) {
+	users := []string{"admin", "root", "vpnuser", "backup"}
+	return authFailureChange(users[m.rng.Intn(len(users))], randomAddr(m.rng)), true
+}
+
+func (m *model) clientChange() (change, bool) {
+	const maxClients = 30
+	ssids := []string{"corp", "guest", "iot"}
+
+	if len(m.clients) > 0 && (len(m.clients) >= maxClients || m.rng.Float64() < 0.4) {
+		i := m.rng.Intn(len(m.clients))
+		cl := m.clients[i]
+		m.clients = append(m.clients[:i], m.clients[i+1:]...)
+		reasons := []int{1, 3, 4, 8}
+		return clientChange(cl.mac, cl.ssid, false, reasons[m.rng.Intn(len(reasons))]), true
+	}
+
+	cl := wifiClient{
+		mac: fmt.Sprintf("02:%02x:%02x:%02x:%02x:%02x",
+			m.rng.Intn(256), m.rng.Intn(256), m.rng.Intn(256), m.rng.Intn(256), m.rng.Intn(256)),
+		ssid: ssids[m.rng.Intn(len(ssids))],
+	}
+	m.clients = append(m.clients, cl)
+	return clientChange(cl.mac, cl.ssid, true, 0), true
+}
+
+// ---------------- Transition rendering ----------------
+//
+// These constructors are shared by the state model and the scenario engine,
+// so scripted and random events look the same on the wire.
+
+func linkChange(name string, index int, up bool) change {
+	c := change{app: "link", msgid: "UPDOWN", severity: syslogsim.SeverityError, trap: snmptrap.LinkDown}
+	if up {
+		c.severity, c.trap = syslogsim.SeverityInfo, snmptrap.LinkUp
+	}
+	state := operStatus(up)
+	c.message = fmt.Sprintf("Interface %s changed state to %s", name, state)
+	c.vars = map[string]string{
+		"ifIndex":       strconv.Itoa(index),
+		"ifDescr":       name,
+		"ifAdminStatus": "up",
+		"ifOperStatus":  state,
+	}
+	return c
+}
+
+func bgpChange(addr string, as int, up bool) change {
+	c := change{app: "bgpd", msgid: "ADJCHANGE", severity: syslogsim.SeverityError, trap: snmptrap.BGPBackwardTransition}
+	bgpState := "idle"
+	if up {
+		c.severity, c.trap, bgpState = syslogsim.SeverityInfo, snmptrap.BGPEstablished, "established"
+	}
+	c.message = fmt.Sprintf("BGP session to peer %s (AS %d) went %s", addr, as, operStatus(up))
+	c.vars = map[string]string{
+		"bgpPeerRemoteAddr": addr,
+		"bgpPeerRemoteAs":   strconv.Itoa(as),
+		"bgpPeerState":      bgpState,
+	}
+	return c
+}
+
+func cpuChange(percent int, high bool) change {
+	c := change{app: "sysmon", msgid: "CPUFALLINGTHRESHOLD", severity: syslogsim.SeverityInfo, trap: snmptrap.CPUFalling}
+	c.message = fmt.Sprintf("CPU utilization returned to %d%%", percent)
+	if high {
+		c.msgid, c.severity, c.trap = "CPURISINGTHRESHOLD", syslogsim.SeverityWarning, snmptrap.CPURising
+		c.message = fmt.Sprintf("CPU utilization exceeded %d%%", percent)
+	}
+	c.vars = map[string]string{"cpmCPUTotal5minRev": strconv.Itoa(percent)}
+	return c
+}
+
+func memChange(percent int, high bool) change {
+	c := change{app: "sysmon", msgid: "MEMOK", severity: syslogsim.SeverityInfo, trap: snmptrap.MemoryRecovered}
+	c.message = fmt.Sprintf("Memory usage returned to %d%%", percent)
+	if high {
+		c.msgid, c.severity, c.trap = "MEMLOW", syslogsim.SeverityWarning, snmptrap.MemoryLow
+		c.message = fmt.Sprintf("High memory usage detected: %d%% used", percent)
+	}
+	c.vars = map[string]string{"ciscoMemoryPoolUsedPercent": strconv.Itoa(percent)}
+	return c
+}
+
+func serviceChange(name string, up bool) change {
+	c := change{app: "systemd", msgid: "UNITFAIL", severity: syslogsim.SeverityError, trap: snmptrap.ServiceDown}
+	c.message = fmt.Sprintf("Service %s exited unexpectedly", name)
+	if up {
+		c.msgid, c.severity, c.trap = "UNITSTART", syslogsim.SeverityInfo, snmptrap.ServiceUp
+		c.message = fmt.Sprintf("Service %s restarted successfully", name)
+	}
+	c.vars = map[string]string{"prNames": name, "prCount": strconv.Itoa(boolToInt(up)), "prErrorFlag": strconv.Itoa(boolToInt(!up))}
+	return c
+}
+
+func sessionChange(sessions int, from string, flood bool) change {
+	c := change{app: "conntrack", msgid: "CONNOK", severity: syslogsim.SeverityInfo, trap: snmptrap.SessionFloodCleared}
+	c.message = fmt.Sprintf("Embryonic session count back to normal: %d", sessions)
+	if flood {
+		c.msgid, c.severity, c.trap = "CONNLIMIT", syslogsim.SeverityCritical, snmptrap.SessionFlood
+		c.message = fmt.Sprintf("Connection limit exceeded: %d embryonic sessions from %s", sessions, from)
+	}
+	c.vars = map[string]string{"cfwConnectionCount": strconv.Itoa(sessions)}
+	return c
+}
+
+func authFailureChange(user, from string) change {
+	return change{
+		app:      "authd",
+		msgid:    "AUTHFAIL",
+		severity: syslogsim.SeverityWarning,
+		message:  fmt.Sprintf("Authentication failure for user %s from IP %s", user, from),
+		trap:     snmptrap.FirewallAuthFailure,
+		vars:     map[string]string{"user": user, "sourceAddr": from},
+	}
+}
+
+func clientChange(mac, ssid string, associated bool, reason int) change {
+	c := change{
+		app:      "hostapd",
+		msgid:    "DISASSOC",
+		severity: syslogsim.SeverityInfo,
+		trap:     snmptrap.ClientDisassociated,
+		message:  fmt.Sprintf("Client %s disassociated from SSID %s (reason %d)", mac, ssid, reason),
+		vars:     map[string]string{"bsnStationMacAddress": mac, "ssid": ssid},
+	}
+	if associated {
+		c.msgid, c.trap = "ASSOC", snmptrap.ClientAssociated
+		c.message = fmt.Sprintf("Client %s associated to SSID %s", mac, ssid)
+	}
+	return c
+}
+
+func stpChange(vlan string) change {
+	return change{
+		app:      "stp",
+		msgid:    "TOPOCHANGE",
+		severity: syslogsim.SeverityWarning,
+		message:  fmt.Sprintf("Spanning tree topology change detected on VLAN %s", vlan),
+		trap:     snmptrap.TopologyChange,
+		vars:     map[string]string{"vlan": vlan},
+	}
+}
+
+func reachabilityChange(target string, up bool) change {
+	c := change{app: "ipsla", msgid: "TRACKDOWN", severity: syslogsim.SeverityCritical, trap: snmptrap.ReachabilityLost}
+	c.message = fmt.Sprintf("Lost connectivity to %s: probe timed out", target)
+	sense := "timeout"
+	if up {
+		c.msgid, c.severity, c.trap, sense = "TRACKUP", syslogsim.SeverityInfo, snmptrap.ReachabilityRestored, "ok"
+		c.message = fmt.Sprintf("Connectivity to %s restored", target)
+	}
+	c.vars = map[string]string{"rttMonCtrlAdminTag": target, "rttMonLatestRttOperSense": sense}
+	return c
+}
+
+func coldStartChange() change {
+	return change{
+		app:      "kernel",
+		msgid:    "RESTART",
+		severity: syslogsim.SeverityWarning,
+		message:  "System restarted (cold start)",
+		trap:     snmptrap.ColdStart,
+	}
+}
+
+func operStatus(up bool) string {
+	if up {
+		return "up"
+	}
+	return "down"
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func randomAddr(rng *rand.Rand) string {
+	return fmt.Sprintf("203.0.113.%d", rng.Intn(254)+1)
+}
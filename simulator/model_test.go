@@ -0,0 +1,157 @@
+package simulator
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/snmptrap"
+	"github.com/ibm-live-project-interns/datasource/pkg/syslogsim"
+)
+
+// TestModel_TransitionsAlternate checks that, per subject, problem and
+// recovery notifications strictly alternate: an interface is never reported
+// down twice without an up in between, and so on.
+func TestModel_TransitionsAlternate(t *testing.T) {
+	pairs := map[string]string{
+		snmptrap.LinkDown.OID:              "link",
+		snmptrap.LinkUp.OID:                "link",
+		snmptrap.BGPBackwardTransition.OID: "bgp",
+		snmptrap.BGPEstablished.OID:        "bgp",
+		snmptrap.CPURising.OID:             "cpu",
+		snmptrap.CPUFalling.OID:            "cpu",
+		snmptrap.MemoryLow.OID:             "mem",
+		snmptrap.MemoryRecovered.OID:       "mem",
+		snmptrap.SessionFlood.OID:          "flood",
+		snmptrap.SessionFloodCleared.OID:   "flood",
+		snmptrap.ServiceDown.OID:           "service",
+		snmptrap.ServiceUp.OID:             "service",
+	}
+	problems := map[string]bool{
+		snmptrap.LinkDown.OID:              true,
+		snmptrap.BGPBackwardTransition.OID: true,
+		snmptrap.CPURising.OID:             true,
+		snmptrap.MemoryLow.OID:             true,
+		snmptrap.SessionFlood.OID:          true,
+		snmptrap.ServiceDown.OID:           true,
+	}
+
+	for _, kind := range []string{"router", "switch", "firewall", "server", "access-point"} {
+		t.Run(kind, func(t *testing.T) {
+			m := newModel(kind, rand.New(rand.NewSource(1)))
+			faulty := make(map[string]bool) // subject → currently in a problem state
+
+			for n := 0; n < 2000; n++ {
+				c := m.step()
+				trap := c.Trap("dev-1", time.Now())
+
+				if c.msgid == "" {
+					t.Fatalf("change %q has no MSGID", c.message)
+				}
+				if msg := c.Syslog("dev-1", time.Now(), "", n); !strings.Contains(msg, " dev-1 "+c.app+" - "+c.msgid+" - "+c.message) {
+					t.Fatalf("syslog does not match change: %q", msg)
+				}
+
+				group, ok := pairs[trap.OID]
+				if !ok {
+					continue
+				}
+				subject := group + ":" + trap.Variables["ifDescr"] + trap.Variables["bgpPeerRemoteAddr"] + trap.Variables["prNames"]
+				if problems[trap.OID] == faulty[subject] {
+					t.Fatalf("step %d: %s %q repeated for %s", n, trap.OID, trap.Message, subject)
+				}
+				faulty[subject] = problems[trap.OID]
+			}
+		})
+	}
+}
+
+func TestModel_ServiceRestartFollowsFailure(t *testing.T) {
+	m := newModel("server", rand.New(rand.NewSource(7)))
+	down := make(map[string]bool)
+
+	for n := 0; n < 2000; n++ {
+		c := m.step()
+		if c.trap != snmptrap.ServiceDown && c.trap != snmptrap.ServiceUp {
+			continue
+		}
+		name := c.vars["prNames"]
+		if isDown := c.trap == snmptrap.ServiceDown; isDown == down[name] {
+			t.Fatalf("step %d: service %s reported %q twice", n, name, c.message)
+		}
+		down[name] = c.trap == snmptrap.ServiceDown
+	}
+}
+
+func TestLoadModel_KeptAcrossRuns(t *testing.T) {
+	ctx := withState(context.Background(), &deviceState{})
+	m := loadModel(ctx, "router", nil)
+	for m.ifaces[0].operUp {
+		m.step()
+	}
+
+	// A restarted run must see the interface still down, so it can report
+	// the recovery.
+	if again := loadModel(ctx, "router", nil); again != m {
+		t.Fatal("expected the kept model on the next run")
+	}
+	if fresh := loadModel(context.Background(), "router", nil); fresh == m || !fresh.ifaces[0].operUp {
+		t.Error("expected a healthy model without a device state")
+	}
+}
+
+func TestModel_AccessPointClients(t *testing.T) {
+	m := newModel("access-point", rand.New(rand.NewSource(3)))
+	associated := make(map[string]bool)
+
+	for n := 0; n < 2000; n++ {
+		c := m.step()
+		mac := c.vars["bsnStationMacAddress"]
+		switch c.trap {
+		case snmptrap.ClientAssociated:
+			associated[mac] = true
+		case snmptrap.ClientDisassociated:
+			if !associated[mac] {
+				t.Fatalf("step %d: %s disassociated without being associated", n, mac)
+			}
+			delete(associated, mac)
+		}
+	}
+	if len(associated) != len(m.clients) {
+		t.Errorf("expected %d associated clients, model has %d", len(associated), len(m.clients))
+	}
+}
+
+func TestModel_SeverityWeights(t *testing.T) {
+	count := func(severities map[string]float64) map[syslogsim.Severity]int {
+		m := newModel("server", rand.New(rand.NewSource(5)))
+		m.severities = severities
+		down := make(map[string]bool)
+
+		counts := make(map[syslogsim.Severity]int)
+		for n := 0; n < 2000; n++ {
+			c := m.step()
+			counts[c.severity]++
+
+			// Weighting must not break the state model.
+			if name := c.vars["prNames"]; name != "" {
+				if isDown := c.trap == snmptrap.ServiceDown; isDown == down[name] {
+					t.Fatalf("step %d: service %s reported %q twice", n, name, c.message)
+				}
+				down[name] = c.trap == snmptrap.ServiceDown
+			}
+		}
+		return counts
+	}
+
+	plain := count(nil)
+	weighted := count(map[string]float64{"error": 3, "info": 1})
+	if weighted[syslogsim.SeverityError] <= plain[syslogsim.SeverityError] {
+		t.Errorf("expected errors weighted up: %v without weights, %v with", plain, weighted)
+	}
+	if weighted[syslogsim.SeverityWarning] > plain[syslogsim.SeverityWarning]/2 {
+		t.Errorf("expected warnings weighted down: %v without weights, %v with", plain, weighted)
+	}
+}
@@ -2,36 +2,35 @@ package simulator
 
 import (
 	"context"
-	"log"
-	"time"
+	"fmt"
+
+	"github.com/ibm-live-project-interns/datasource/config"
 )
 
 // Router simulates a router device that generates SNMP trap telemetry.
-//
-// NOTE: Currently a placeholder — Run() logs a message every 5 seconds but
-// does not produce actual SNMP traps. To complete the implementation, integrate
-// with the pkg/snmptrap package to call snmptrap.RandomTrap("router", r.Name)
-// and send the trap via snmptrap.SendTrap.
+// Every trap_interval it advances its state model (interfaces, BGP peers, CPU
+// and memory) by one transition and sends the matching trap, with its name
+// as the trap source. A positive syslog_interval adds syslog messages from
+// the same state model.
 type Router struct {
-	Name string
+	emitter
 }
 
-// Run starts the router simulation loop, logging every 5 seconds until the
-// context is cancelled.
-//
-// TODO: Replace the log-only stub with actual SNMP trap generation using
-// pkg/snmptrap.RandomTrap and pkg/snmptrap.SendTrap.
-func (r *Router) Run(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Router stopped:", r.Name)
-			return
-		case <-ticker.C:
-			log.Println("Router sending SNMP trap:", r.Name)
-		}
+// newRouterFromConfig builds a Router from its YAML configuration, which must
+// set a positive trap_interval; syslog_interval is optional.
+func newRouterFromConfig(cfg config.DeviceConfig) (Device, error) {
+	if cfg.TrapInterval <= 0 {
+		return nil, fmt.Errorf("router requires a positive trap_interval, got %d", cfg.TrapInterval)
+	}
+	e, err := newEmitter("router", cfg)
+	if err != nil {
+		return nil, err
 	}
+	return &Router{emitter: e}, nil
+}
+
+// Run sends the router's telemetry until the context is cancelled or the
+// context's clock (see simclock.FromContext) reaches its end.
+func (r *Router) Run(ctx context.Context) error {
+	return r.emitter.run(ctx)
 }
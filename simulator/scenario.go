@@ -0,0 +1,623 @@
+package simulator
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/ibm-live-project-interns/datasource/config"
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+	"github.com/ibm-live-project-interns/datasource/pkg/snmptrap"
+	"github.com/ibm-live-project-interns/datasource/pkg/syslogsim"
+)
+
+// Scenario is a scripted incident loaded from YAML: a timeline of events,
+// each emitted as an SNMP trap and/or syslog message from a named device.
+// Unlike the random device telemetry, a scenario with a fixed seed produces
+// the same events at the same offsets on every run.
+//
+//	name: core-link-flap
+//	seed: 42
+//	steps:
+//	  - at: T+30s
+//	    device: router-1
+//	    event: interface_down
+//	    interface: Gi0/1
+//	  - at: T+45s
+//	    device: switch-1
+//	    event: stp_topology_change
+//	    emit: [syslog]
+//	  - at: T+2m
+//	    device: router-1
+//	    event: interface_up
+//	    interface: Gi0/1
+//	  - at: T+3m
+//	    loop:
+//	      count: 3
+//	      every: 20s
+//	      steps:
+//	        - device: fw-1
+//	          event: auth_failure
+//	          jitter: 5s
+//
+// Step offsets are relative to the scenario start ("T+" is optional); steps
+// inside a loop are relative to the start of each iteration. Jitter delays a
+// step by a random amount in [0, jitter).
+//
+// With a Topology (inline, or a topology_file relative to the scenario),
+// device_down/device_up steps and interface events on linked interfaces also
+// generate the consequences on dependent devices; see topoState.cascade.
+type Scenario struct {
+	Name         string    `yaml:"name"`
+	Seed         int64     `yaml:"seed"`          // Jitter seed; 0 picks one from the clock
+	TrapTarget   string    `yaml:"trap_target"`   // Defaults to config.DefaultTrapTarget
+	SyslogTarget string    `yaml:"syslog_target"` // Defaults to config.DefaultSyslogTarget
+	Dialect      string    `yaml:"dialect"`       // Syslog format (see syslogsim.Dialects); defaults to RFC 5424
+	Jitter       Duration  `yaml:"jitter"`        // Default jitter for every step
+	Topology     *Topology `yaml:"topology"`
+	TopologyFile string    `yaml:"topology_file"`
+	Steps        []Step    `yaml:"steps"`
+}
+
+// Step is one scenario event, or a loop of nested steps.
+type Step struct {
+	At     Duration          `yaml:"at"`
+	Label  string            `yaml:"label"`  // Optional name reported in the output
+	Device string            `yaml:"device"` // Source hostname
+	Event  string            `yaml:"event"`  // One of scenarioEvents
+	Emit   []string          `yaml:"emit"`   // "trap" and/or "syslog"; defaults to both
+	Jitter *Duration         `yaml:"jitter"` // Overrides the scenario default
+	Loop   *Loop             `yaml:"loop"`
+	Params map[string]string `yaml:",inline"` // Event parameters, e.g. "interface: Gi0/1"
+}
+
+// Loop repeats its steps Count times, one iteration every Every.
+type Loop struct {
+	Count int      `yaml:"count"`
+	Every Duration `yaml:"every"`
+	Steps []Step   `yaml:"steps"`
+}
+
+// Duration is a time.Duration read from YAML as "30s", "2m" or "T+30s".
+type Duration time.Duration
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(strings.TrimPrefix(strings.TrimSpace(s), "T+"))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Emission records one trap or syslog message sent by a scenario. Run writes
+// one JSON object per emission, so tests can assert on exactly what was sent.
+type Emission struct {
+	Seq       int               `json:"seq"`
+	Scenario  string            `json:"scenario"`
+	Step      string            `json:"step"`                 // Step label or its path, e.g. "steps[3].loop.steps[0]"
+	RootCause string            `json:"root_cause,omitempty"` // Set on cascaded events: the device the step failed or restored
+	Iteration int               `json:"iteration,omitempty"`  // 1-based loop iteration
+	OffsetMS  int64             `json:"offset_ms"`            // Planned offset from the start, including jitter
+	Time      time.Time         `json:"time"`                 // When it was sent
+	Device    string            `json:"device"`
+	Event     string            `json:"event"`
+	Kind      string            `json:"kind"` // "trap" or "syslog"
+	Target    string            `json:"target"`
+	OID       string            `json:"oid,omitempty"`
+	Severity  string            `json:"severity"`
+	Message   string            `json:"message"`
+	Raw       string            `json:"raw,omitempty"` // Full syslog line
+	Variables map[string]string `json:"variables,omitempty"`
+	Error     string            `json:"error,omitempty"` // Send failure, if any
+}
+
+// ScenarioSummary reports what a scenario run did.
+type ScenarioSummary struct {
+	Seed    int64 // Seed used for jitter; pass it back to reproduce the run
+	Steps   int   // Steps executed (loop iterations count separately; cascaded events do not count)
+	Emitted int   // Messages sent successfully
+	Failed  int   // Messages that could not be sent
+}
+
+// planned is a step scheduled at a concrete offset.
+type planned struct {
+	offset    time.Duration
+	path      string
+	iteration int
+	step      *Step
+	change    change
+	rootCause string // Set on events derived from the topology
+}
+
+// LoadScenario reads and validates a scenario file. A relative
+// topology_file is resolved against the scenario's directory.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario %s: %w", path, err)
+	}
+	return parseScenario(data, filepath.Dir(path))
+}
+
+// ParseScenario parses and validates a YAML scenario. A relative
+// topology_file is resolved against the working directory.
+func ParseScenario(data []byte) (*Scenario, error) {
+	return parseScenario(data, "")
+}
+
+func parseScenario(data []byte, dir string) (*Scenario, error) {
+	var s Scenario
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid scenario: %w", err)
+	}
+	if _, err := syslogsim.ParseDialect(s.Dialect); err != nil {
+		return nil, fmt.Errorf("invalid scenario: %w", err)
+	}
+	if s.TopologyFile != "" {
+		if s.Topology != nil {
+			return nil, errors.New("invalid scenario: set topology or topology_file, not both")
+		}
+		path := s.TopologyFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		t, err := LoadTopology(path)
+		if err != nil {
+			return nil, err
+		}
+		s.Topology = t
+	}
+	if _, err := s.plan(rand.New(rand.NewSource(1))); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// plan flattens the scenario into steps ordered by offset, applying jitter
+// from rng, and adds the events they cascade into. All validation errors are
+// reported together.
+func (s *Scenario) plan(rng *rand.Rand) ([]planned, error) {
+	if s.Topology != nil {
+		if err := s.Topology.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	var out []planned
+	var errs []error
+
+	var walk func(steps []Step, prefix string, base time.Duration, iteration int)
+	walk = func(steps []Step, prefix string, base time.Duration, iteration int) {
+		for i := range steps {
+			st := &steps[i]
+			path := fmt.Sprintf("%s[%d]", prefix, i)
+			at := base + time.Duration(st.At)
+
+			if st.At < 0 {
+				errs = append(errs, fmt.Errorf("%s: at must not be negative", path))
+				continue
+			}
+
+			if st.Loop != nil {
+				if st.Event != "" || st.Device != "" {
+					errs = append(errs, fmt.Errorf("%s: a step has either a loop or an event, not both", path))
+					continue
+				}
+				if st.Loop.Count <= 0 || st.Loop.Every <= 0 {
+					errs = append(errs, fmt.Errorf("%s: loop requires a positive count and every", path))
+					continue
+				}
+				if len(st.Loop.Steps) == 0 {
+					errs = append(errs, fmt.Errorf("%s: loop has no steps", path))
+					continue
+				}
+				for n := 0; n < st.Loop.Count; n++ {
+					walk(st.Loop.Steps, path+".loop.steps", at+time.Duration(n)*time.Duration(st.Loop.Every), n+1)
+				}
+				continue
+			}
+
+			c, err := st.validate()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s (%s): %w", path, st.Event, err))
+				continue
+			}
+
+			jitter := time.Duration(s.Jitter)
+			if st.Jitter != nil {
+				jitter = time.Duration(*st.Jitter)
+			}
+			if jitter > 0 {
+				at += time.Duration(rng.Int63n(int64(jitter)))
+			}
+
+			label := path
+			if st.Label != "" {
+				label = st.Label
+			}
+			out = append(out, planned{offset: at, path: label, iteration: iteration, step: st, change: c})
+		}
+	}
+	walk(s.Steps, "steps", 0, 0)
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid scenario: %w", errors.Join(errs...))
+	}
+	if len(out) == 0 {
+		return nil, errors.New("invalid scenario: no steps")
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].offset < out[j].offset })
+	return s.cascade(out, rng)
+}
+
+// cascade walks the sorted plan through the topology and merges in the
+// events each step causes on other devices.
+func (s *Scenario) cascade(plan []planned, rng *rand.Rand) ([]planned, error) {
+	var ts *topoState
+	if s.Topology != nil {
+		ts = newTopoState(s.Topology, rng)
+	}
+
+	var derived []planned
+	var errs []error
+	for _, p := range plan {
+		if ts == nil {
+			if strings.HasPrefix(p.step.Event, "device_") {
+				errs = append(errs, fmt.Errorf("%s (%s): requires a topology", p.path, p.step.Event))
+			}
+			continue
+		}
+		more, err := ts.cascade(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", p.path, p.step.Event, err))
+			continue
+		}
+		derived = append(derived, more...)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid scenario: %w", errors.Join(errs...))
+	}
+	if len(derived) == 0 {
+		return plan, nil
+	}
+
+	plan = append(plan, derived...)
+	sort.SliceStable(plan, func(i, j int) bool { return plan[i].offset < plan[j].offset })
+	return plan, nil
+}
+
+// validate checks an event step and builds its change.
+func (st *Step) validate() (change, error) {
+	if st.Device == "" {
+		return change{}, errors.New("device is required")
+	}
+	spec, ok := scenarioEvents[st.Event]
+	if !ok {
+		return change{}, fmt.Errorf("unknown event (supported: %s)", strings.Join(scenarioEventNames(), ", "))
+	}
+	for _, kind := range st.Emit {
+		if kind != "trap" && kind != "syslog" {
+			return change{}, fmt.Errorf("emit: unknown kind %q (want trap or syslog)", kind)
+		}
+	}
+	for key := range st.Params {
+		if !slices.Contains(spec.params, key) {
+			return change{}, fmt.Errorf("unknown field %q (event accepts: %s)", key, strings.Join(spec.params, ", "))
+		}
+	}
+
+	c, err := spec.build(eventParams(st.Params))
+	if err != nil {
+		return change{}, err
+	}
+	if c.message != "" && c.trap.OID == "" && st.emits("trap") {
+		return change{}, errors.New(`event has no trap; set oid or use emit: [syslog]`)
+	}
+	return c, nil
+}
+
+func (st *Step) emits(kind string) bool {
+	return len(st.Emit) == 0 || slices.Contains(st.Emit, kind)
+}
+
+// scenarioIO abstracts sending and waiting so tests can run scenarios
+// instantly.
+type scenarioIO struct {
+	sendTrap   func(addr string, trap snmptrap.Trap) error
+	sendSyslog func(addr, msg string) error
+	wait       func(ctx context.Context, offset time.Duration) error // Blocks until offset after the start
+	close      func()
+}
+
+// Run executes the scenario on the context's clock (real time unless one is
+// set with simclock.WithClock), writing one Emission per message to out (if
+// non-nil) as JSON Lines. Send failures are recorded in the output and
+// counted, not fatal. Run returns early with ctx.Err() if the context is
+// cancelled.
+func (s *Scenario) Run(ctx context.Context, out io.Writer) (ScenarioSummary, error) {
+	sio := udpScenarioIO(simclock.FromContext(ctx))
+	defer sio.close()
+	return s.run(ctx, out, sio)
+}
+
+func (s *Scenario) run(ctx context.Context, out io.Writer, sio scenarioIO) (ScenarioSummary, error) {
+	seed := simclock.Seed(s.Seed)
+	log.Printf("Running scenario %s (seed %d)", s.Name, seed)
+	summary := ScenarioSummary{Seed: seed}
+
+	plan, err := s.plan(rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return summary, err
+	}
+	dialect, err := syslogsim.ParseDialect(s.Dialect)
+	if err != nil {
+		return summary, err
+	}
+	sequences := make(map[string]int) // Syslog sequence numbers by device
+
+	trapTarget := cmp.Or(s.TrapTarget, config.DefaultTrapTarget)
+	syslogTarget := cmp.Or(s.SyslogTarget, config.DefaultSyslogTarget)
+
+	var enc *json.Encoder
+	if out != nil {
+		enc = json.NewEncoder(out)
+	}
+
+	clock := simclock.FromContext(ctx)
+
+	for _, p := range plan {
+		if err := sio.wait(ctx, p.offset); err != nil {
+			return summary, err
+		}
+		if p.rootCause == "" {
+			summary.Steps++
+		}
+
+		for _, kind := range []string{"trap", "syslog"} {
+			if !p.step.emits(kind) || p.change.message == "" {
+				continue
+			}
+
+			e := Emission{
+				Seq:       summary.Emitted + summary.Failed + 1,
+				Scenario:  s.Name,
+				Step:      p.path,
+				RootCause: p.rootCause,
+				Iteration: p.iteration,
+				OffsetMS:  p.offset.Milliseconds(),
+				Device:    p.step.Device,
+				Event:     p.step.Event,
+				Kind:      kind,
+				Message:   p.change.message,
+			}
+
+			var err error
+			if kind == "trap" {
+				trap := p.change.Trap(p.step.Device, clock.Now())
+				e.Target, e.OID, e.Severity, e.Variables = trapTarget, trap.OID, trap.Severity, trap.Variables
+				e.Time = trap.Timestamp
+				err = sio.sendTrap(trapTarget, trap)
+			} else {
+				e.Time = clock.Now()
+				sequences[p.step.Device]++
+				e.Raw = p.change.Syslog(p.step.Device, e.Time, dialect, sequences[p.step.Device])
+				e.Target, e.Severity = syslogTarget, p.change.severity.String()
+				err = sio.sendSyslog(syslogTarget, e.Raw)
+			}
+
+			if err != nil {
+				e.Error = err.Error()
+				summary.Failed++
+			} else {
+				summary.Emitted++
+			}
+			if enc != nil {
+				if err := enc.Encode(e); err != nil {
+					return summary, fmt.Errorf("failed to write scenario output: %w", err)
+				}
+			}
+		}
+	}
+	return summary, nil
+}
+
+// udpScenarioIO sends traps with snmptrap.SendTrap and syslog over one UDP
+// connection per target. Offsets are measured on clock from the time it is
+// created.
+func udpScenarioIO(clock simclock.Clock) scenarioIO {
+	conns := make(map[string]net.Conn)
+	start := clock.Now()
+	return scenarioIO{
+		sendTrap: snmptrap.SendTrap,
+		sendSyslog: func(addr, msg string) error {
+			conn, ok := conns[addr]
+			if !ok {
+				var err error
+				if conn, err = net.Dial("udp", addr); err != nil {
+					return err
+				}
+				conns[addr] = conn
+			}
+			_, err := conn.Write([]byte(msg + "\n"))
+			return err
+		},
+		wait: func(ctx context.Context, offset time.Duration) error {
+			return clock.Sleep(ctx, start.Add(offset).Sub(clock.Now()))
+		},
+		close: func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		},
+	}
+}
+
+// ---------------- Events ----------------
+
+// eventSpec describes a scenario event type and the step fields it accepts.
+type eventSpec struct {
+	params []string
+	build  func(p eventParams) (change, error)
+}
+
+// scenarioEvents lists the supported scenario events by name.
+var scenarioEvents = map[string]eventSpec{
+	"device_down":    {nil, func(eventParams) (change, error) { return change{}, nil }}, // A dead device sends nothing
+	"device_up":      {nil, func(eventParams) (change, error) { return coldStartChange(), nil }},
+	"interface_down": {[]string{"interface", "index"}, func(p eventParams) (change, error) { return p.link(false) }},
+	"interface_up":   {[]string{"interface", "index"}, func(p eventParams) (change, error) { return p.link(true) }},
+	"bgp_down":       {[]string{"peer", "as"}, func(p eventParams) (change, error) { return p.bgp(false) }},
+	"bgp_up":         {[]string{"peer", "as"}, func(p eventParams) (change, error) { return p.bgp(true) }},
+	"cpu_high": {[]string{"value"}, func(p eventParams) (change, error) {
+		v, err := p.int("value", 95)
+		return cpuChange(v, true), err
+	}},
+	"cpu_normal": {[]string{"value"}, func(p eventParams) (change, error) {
+		v, err := p.int("value", 30)
+		return cpuChange(v, false), err
+	}},
+	"memory_high": {[]string{"value"}, func(p eventParams) (change, error) {
+		v, err := p.int("value", 92)
+		return memChange(v, true), err
+	}},
+	"memory_normal": {[]string{"value"}, func(p eventParams) (change, error) {
+		v, err := p.int("value", 40)
+		return memChange(v, false), err
+	}},
+	"service_down": {[]string{"service"}, func(p eventParams) (change, error) {
+		name, err := p.required("service")
+		return serviceChange(name, false), err
+	}},
+	"service_up": {[]string{"service"}, func(p eventParams) (change, error) {
+		name, err := p.required("service")
+		return serviceChange(name, true), err
+	}},
+	"session_flood": {[]string{"sessions", "source"}, func(p eventParams) (change, error) {
+		n, err := p.int("sessions", 8000)
+		return sessionChange(n, p.str("source", "203.0.113.10"), true), err
+	}},
+	"session_flood_cleared": {[]string{"sessions"}, func(p eventParams) (change, error) {
+		n, err := p.int("sessions", 200)
+		return sessionChange(n, "", false), err
+	}},
+	"auth_failure": {[]string{"user", "source"}, func(p eventParams) (change, error) {
+		return authFailureChange(p.str("user", "admin"), p.str("source", "203.0.113.10")), nil
+	}},
+	"stp_topology_change": {[]string{"vlan"}, func(p eventParams) (change, error) {
+		return stpChange(p.str("vlan", "1")), nil
+	}},
+	"client_associate": {[]string{"mac", "ssid"}, func(p eventParams) (change, error) {
+		mac, err := p.required("mac")
+		return clientChange(mac, p.str("ssid", "corp"), true, 0), err
+	}},
+	"client_disassociate": {[]string{"mac", "ssid", "reason"}, func(p eventParams) (change, error) {
+		mac, err := p.required("mac")
+		if err != nil {
+			return change{}, err
+		}
+		reason, err := p.int("reason", 8)
+		return clientChange(mac, p.str("ssid", "corp"), false, reason), err
+	}},
+	"custom": {[]string{"message", "oid", "severity", "app", "msgid"}, func(p eventParams) (change, error) {
+		msg, err := p.required("message")
+		if err != nil {
+			return change{}, err
+		}
+		sev := p.str("severity", "warning")
+		s, ok := syslogSeverities[sev]
+		if !ok {
+			return change{}, fmt.Errorf("invalid severity %q (want info, warning, error or critical)", sev)
+		}
+		c := change{app: p.str("app", "scenario"), msgid: p.str("msgid", ""), severity: s, message: msg}
+		if oid := p.str("oid", ""); oid != "" {
+			c.trap = snmptrap.TrapTemplate{OID: oid, Message: msg, Severity: sev}
+		}
+		return c, nil
+	}},
+}
+
+func scenarioEventNames() []string {
+	names := make([]string, 0, len(scenarioEvents))
+	for name := range scenarioEvents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// syslogSeverities maps trap severity names to syslog severities.
+var syslogSeverities = map[string]syslogsim.Severity{
+	"info":     syslogsim.SeverityInfo,
+	"warning":  syslogsim.SeverityWarning,
+	"error":    syslogsim.SeverityError,
+	"critical": syslogsim.SeverityCritical,
+}
+
+// eventParams are the event-specific fields of a step.
+type eventParams map[string]string
+
+func (p eventParams) str(key, def string) string {
+	if v, ok := p[key]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func (p eventParams) required(key string) (string, error) {
+	if v := p[key]; v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("%s is required", key)
+}
+
+func (p eventParams) int(key string, def int) (int, error) {
+	v, ok := p[key]
+	if !ok || v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer, got %q", key, v)
+	}
+	return n, nil
+}
+
+func (p eventParams) link(up bool) (change, error) {
+	name, err := p.required("interface")
+	if err != nil {
+		return change{}, err
+	}
+	index, err := p.int("index", 1)
+	return linkChange(name, index, up), err
+}
+
+func (p eventParams) bgp(up bool) (change, error) {
+	peer, err := p.required("peer")
+	if err != nil {
+		return change{}, err
+	}
+	as, err := p.int("as", 65001)
+	return bgpChange(peer, as, up), err
+}
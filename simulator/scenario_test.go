@@ -0,0 +1,291 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/snmptrap"
+)
+
+// fakeScenarioIO records sends and skips waiting, keeping track of the
+// virtual elapsed time instead.
+type fakeScenarioIO struct {
+	traps   []snmptrap.Trap
+	syslogs []string
+	elapsed time.Duration
+	failOn  string // Syslog messages containing this fail to send
+}
+
+func (f *fakeScenarioIO) io() scenarioIO {
+	return scenarioIO{
+		sendTrap: func(_ string, trap snmptrap.Trap) error {
+			f.traps = append(f.traps, trap)
+			return nil
+		},
+		sendSyslog: func(_, msg string) error {
+			if f.failOn != "" && strings.Contains(msg, f.failOn) {
+				return errors.New("connection refused")
+			}
+			f.syslogs = append(f.syslogs, msg)
+			return nil
+		},
+		wait: func(ctx context.Context, offset time.Duration) error {
+			f.elapsed = offset
+			return ctx.Err()
+		},
+		close: func() {},
+	}
+}
+
+func runScenario(t *testing.T, s *Scenario, f *fakeScenarioIO) ([]Emission, ScenarioSummary) {
+	t.Helper()
+	var out bytes.Buffer
+	summary, err := s.run(context.Background(), &out, f.io())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var emissions []Emission
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var e Emission
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("decode output: %v", err)
+		}
+		emissions = append(emissions, e)
+	}
+	return emissions, summary
+}
+
+func TestLoadScenario_Sample(t *testing.T) {
+	s, err := LoadScenario("../config/scenarios/core-link-flap.yml")
+	if err != nil {
+		t.Fatalf("expected sample scenario to be valid, got %v", err)
+	}
+
+	emissions, summary := runScenario(t, s, &fakeScenarioIO{})
+
+	// 5 steps + 5 loop iterations; the STP step only emits syslog
+	if summary.Steps != 10 || summary.Emitted != 19 || summary.Failed != 0 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	first := emissions[0]
+	if first.Step != "uplink-down" || first.OffsetMS != 30000 || first.Kind != "trap" ||
+		first.OID != snmptrap.LinkDown.OID || first.Variables["ifDescr"] != "GigabitEthernet0/1" {
+		t.Errorf("unexpected first emission: %+v", first)
+	}
+
+	last := emissions[len(emissions)-1]
+	if last.Iteration != 5 || last.Event != "auth_failure" || last.Device != "fw-1" {
+		t.Errorf("unexpected last emission: %+v", last)
+	}
+}
+
+func TestScenario_ReproducibleWithSeed(t *testing.T) {
+	s, err := ParseScenario([]byte(`
+name: jittery
+seed: 7
+jitter: 10s
+steps:
+  - at: 5s
+    loop:
+      count: 4
+      every: 1m
+      steps:
+        - device: router-1
+          event: cpu_high
+        - at: 30s
+          device: router-1
+          event: cpu_normal
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	a, _ := runScenario(t, s, &fakeScenarioIO{})
+	b, _ := runScenario(t, s, &fakeScenarioIO{})
+	if len(a) != 16 || len(a) != len(b) {
+		t.Fatalf("expected 16 emissions per run, got %d and %d", len(a), len(b))
+	}
+
+	var prev int64
+	for i := range a {
+		if a[i].OffsetMS != b[i].OffsetMS || a[i].Event != b[i].Event {
+			t.Fatalf("emission %d differs between runs: %+v vs %+v", i, a[i], b[i])
+		}
+		if a[i].OffsetMS < prev {
+			t.Errorf("emissions out of order at %d", i)
+		}
+		prev = a[i].OffsetMS
+
+		iterStart := 5000 + int64(a[i].Iteration-1)*60000
+		if a[i].Event == "cpu_normal" {
+			iterStart += 30000
+		}
+		if a[i].OffsetMS < iterStart || a[i].OffsetMS >= iterStart+10000 {
+			t.Errorf("emission %d offset %dms outside jitter window from %dms", i, a[i].OffsetMS, iterStart)
+		}
+	}
+}
+
+func TestScenario_ReportsChosenSeed(t *testing.T) {
+	s, err := ParseScenario([]byte(`
+name: unseeded
+jitter: 10s
+steps:
+  - at: 5s
+    loop:
+      count: 4
+      every: 1m
+      steps:
+        - device: router-1
+          event: cpu_high
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	a, summary := runScenario(t, s, &fakeScenarioIO{})
+	if summary.Seed == 0 {
+		t.Fatal("expected the chosen seed in the summary")
+	}
+
+	s.Seed = summary.Seed
+	b, _ := runScenario(t, s, &fakeScenarioIO{})
+	for i := range a {
+		if a[i].OffsetMS != b[i].OffsetMS {
+			t.Fatalf("emission %d differs when rerun with the reported seed: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+}
+
+func TestScenario_EmitAndFailures(t *testing.T) {
+	s, err := ParseScenario([]byte(`
+steps:
+  - device: srv-1
+    event: service_down
+    service: postgres
+    emit: [syslog]
+  - at: 1s
+    device: srv-1
+    event: custom
+    message: backup job failed
+    severity: error
+    emit: [syslog]
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	f := &fakeScenarioIO{failOn: "backup"}
+	emissions, summary := runScenario(t, s, f)
+
+	if len(f.traps) != 0 {
+		t.Errorf("expected no traps, got %d", len(f.traps))
+	}
+	if summary.Emitted != 1 || summary.Failed != 1 || len(emissions) != 2 {
+		t.Fatalf("unexpected summary %+v with %d emissions", summary, len(emissions))
+	}
+	if !strings.Contains(emissions[0].Raw, " srv-1 systemd - UNITFAIL - Service postgres exited unexpectedly") {
+		t.Errorf("unexpected syslog line: %q", emissions[0].Raw)
+	}
+	if emissions[1].Error != "connection refused" || emissions[1].Severity != "error" {
+		t.Errorf("expected recorded send failure, got %+v", emissions[1])
+	}
+	if f.elapsed != time.Second {
+		t.Errorf("expected 1s of virtual time, got %s", f.elapsed)
+	}
+}
+
+func TestParseScenario_Errors(t *testing.T) {
+	_, err := ParseScenario([]byte(`
+steps:
+  - at: 10s
+    device: router-1
+    event: interface_down
+  - device: router-1
+    event: reboot
+  - device: switch-1
+    event: stp_topology_change
+    vlna: 10
+  - at: 1m
+    loop:
+      count: 0
+      every: 10s
+      steps:
+        - device: fw-1
+          event: auth_failure
+  - device: srv-1
+    event: custom
+    message: no trap here
+  - at: soon
+    device: srv-1
+    event: cpu_high
+`))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	// yaml errors abort decoding before validation
+	if !strings.Contains(err.Error(), `invalid duration "soon"`) {
+		t.Fatalf("expected duration error, got %v", err)
+	}
+
+	_, err = ParseScenario([]byte(`
+steps:
+  - at: 10s
+    device: router-1
+    event: interface_down
+  - device: router-1
+    event: reboot
+  - device: switch-1
+    event: stp_topology_change
+    vlna: 10
+  - at: 1m
+    loop:
+      count: 0
+      every: 10s
+      steps:
+        - device: fw-1
+          event: auth_failure
+  - device: srv-1
+    event: custom
+    message: no trap here
+`))
+	for _, want := range []string{
+		"steps[0] (interface_down): interface is required",
+		"steps[1] (reboot): unknown event",
+		`steps[2] (stp_topology_change): unknown field "vlna"`,
+		"steps[3]: loop requires a positive count and every",
+		"steps[4] (custom): event has no trap",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestScenario_Cancel(t *testing.T) {
+	s, err := ParseScenario([]byte(`
+steps:
+  - at: 1h
+    device: router-1
+    event: cpu_high
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	summary, err := s.Run(ctx, nil)
+	if !errors.Is(err, context.DeadlineExceeded) || summary.Steps != 0 {
+		t.Errorf("expected cancellation before the first step, got %+v, %v", summary, err)
+	}
+}
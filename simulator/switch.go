@@ -2,36 +2,35 @@ package simulator
 
 import (
 	"context"
-	"log"
-	"time"
+	"fmt"
+
+	"github.com/ibm-live-project-interns/datasource/config"
 )
 
 // Switch simulates a network switch device that generates syslog telemetry.
-//
-// NOTE: Currently a placeholder — Run() logs a message every 7 seconds but
-// does not produce actual syslog events. To complete the implementation,
-// integrate with the pkg/syslogsim package to generate and transmit RFC 5424
-// syslog messages.
+// Every syslog_interval it advances its state model (access ports, CPU and
+// memory) by one transition and sends the matching syslog message, with its
+// name as the HOSTNAME field, in the configured dialect. A positive
+// trap_interval adds SNMP traps from the same state model.
 type Switch struct {
-	Name string
+	emitter
 }
 
-// Run starts the switch simulation loop, logging every 7 seconds until the
-// context is cancelled.
-//
-// TODO: Replace the log-only stub with actual syslog event generation using
-// pkg/syslogsim.RunSimulation or a dedicated syslog sender.
-func (s *Switch) Run(ctx context.Context) {
-	ticker := time.NewTicker(7 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Switch stopped:", s.Name)
-			return
-		case <-ticker.C:
-			log.Println("Switch sending syslog:", s.Name)
-		}
+// newSwitchFromConfig builds a Switch from its YAML configuration, which must
+// set a positive syslog_interval; trap_interval is optional.
+func newSwitchFromConfig(cfg config.DeviceConfig) (Device, error) {
+	if cfg.SyslogInterval <= 0 {
+		return nil, fmt.Errorf("switch requires a positive syslog_interval, got %d", cfg.SyslogInterval)
+	}
+	e, err := newEmitter("switch", cfg)
+	if err != nil {
+		return nil, err
 	}
+	return &Switch{emitter: e}, nil
+}
+
+// Run sends the switch's telemetry until the context is cancelled or the
+// context's clock reaches its end.
+func (s *Switch) Run(ctx context.Context) error {
+	return s.emitter.run(ctx)
 }
@@ -0,0 +1,164 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/config"
+	"github.com/ibm-live-project-interns/datasource/pkg/simclock"
+	"github.com/ibm-live-project-interns/datasource/pkg/snmptrap"
+	"github.com/ibm-live-project-interns/datasource/pkg/syslogsim"
+	"github.com/ibm-live-project-interns/datasource/pkg/traffic"
+)
+
+// emitter sends a mix of SNMP traps and syslog messages for one device;
+// Router, Switch and Appliance are built on it. A zero interval disables that
+// kind of telemetry; a profile shapes each enabled kind independently. Both
+// kinds are derived from one state model, so traps and syslog lines never
+// contradict each other.
+type emitter struct {
+	name           string
+	kind           string // Device type passed to the trap and syslog generators
+	trapInterval   time.Duration
+	syslogInterval time.Duration
+	trapTarget     string
+	syslogTarget   string
+	profile        *traffic.Profile
+	dialect        syslogsim.Dialect
+}
+
+// newEmitter builds an emitter from a device configuration. At least one of
+// trap_interval and syslog_interval must be positive.
+func newEmitter(kind string, cfg config.DeviceConfig) (emitter, error) {
+	if cfg.TrapInterval <= 0 && cfg.SyslogInterval <= 0 {
+		return emitter{}, fmt.Errorf("%s requires a positive trap_interval or syslog_interval", kind)
+	}
+	dialect, err := syslogsim.ParseDialect(cfg.Dialect)
+	if err != nil {
+		return emitter{}, err
+	}
+	return emitter{
+		name:           cfg.Name,
+		kind:           kind,
+		trapInterval:   time.Duration(cfg.TrapInterval) * time.Second,
+		syslogInterval: time.Duration(cfg.SyslogInterval) * time.Second,
+		trapTarget:     cfg.TrapTarget,
+		syslogTarget:   cfg.SyslogTarget,
+		profile:        cfg.Profile,
+		dialect:        dialect,
+	}, nil
+}
+
+// Appliance simulates a device that sends both SNMP traps and syslog: a
+// firewall, server or wireless access point (see deviceTypes). It sends traps
+// every trap_interval and syslog every syslog_interval; either interval may
+// be omitted.
+type Appliance struct {
+	emitter
+}
+
+// newApplianceFromConfig returns a factory for appliances of the given kind,
+// whose configuration must set a positive trap_interval or syslog_interval.
+func newApplianceFromConfig(kind string) deviceFactory {
+	return func(cfg config.DeviceConfig) (Device, error) {
+		e, err := newEmitter(kind, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &Appliance{emitter: e}, nil
+	}
+}
+
+// Run sends the appliance's telemetry until the context is cancelled.
+func (a *Appliance) Run(ctx context.Context) error {
+	return a.emitter.run(ctx)
+}
+
+// maxSendFailures is the number of consecutive send failures after which a
+// device gives up and reports itself failed to the Manager.
+const maxSendFailures = 5
+
+// Name implements Device.
+func (e emitter) Name() string { return e.name }
+
+// Type implements Device.
+func (e emitter) Type() string { return e.kind }
+
+// run sends telemetry until the context is cancelled or the context's clock
+// reaches its end. Individual send failures are logged; after
+// maxSendFailures consecutive failures of either kind the device fails.
+func (e emitter) run(ctx context.Context) error {
+	var conn net.Conn
+	if e.syslogInterval > 0 {
+		target := e.syslogTarget
+		if target == "" {
+			target = config.DefaultSyslogTarget
+		}
+
+		var err error
+		if conn, err = net.Dial("udp", target); err != nil {
+			return fmt.Errorf("failed to open syslog connection to %s: %w", target, err)
+		}
+		defer conn.Close()
+	}
+
+	trapTarget := e.trapTarget
+	if trapTarget == "" {
+		trapTarget = config.DefaultTrapTarget
+	}
+
+	// Both schedules run on the clock: sleep until whichever is due first.
+	clock := simclock.FromContext(ctx)
+	now := clock.Now()
+	trapSchedule := traffic.NewSchedule(e.profile, e.trapInterval, now, deviceRand(ctx, 1))
+	syslogSchedule := traffic.NewSchedule(e.profile, e.syslogInterval, now, deviceRand(ctx, 2))
+	nextTrap, nextSyslog := firstTick(now, e.trapInterval, trapSchedule), firstTick(now, e.syslogInterval, syslogSchedule)
+
+	state := loadModel(ctx, e.kind, e.profile)
+	failures, seq := 0, 0
+	for {
+		sendTrap := !nextTrap.IsZero() && (nextSyslog.IsZero() || !nextSyslog.Before(nextTrap))
+		due := nextSyslog
+		if sendTrap {
+			due = nextTrap
+		}
+		if err := clock.Sleep(ctx, due.Sub(clock.Now())); err != nil {
+			log.Printf("%s stopped: %s", e.kind, e.name)
+			return nil
+		}
+
+		var err error
+		if sendTrap {
+			nextTrap = nextTrap.Add(trapSchedule.Next(due))
+			trap := state.step().Trap(e.name, due)
+			if err = snmptrap.SendTrap(trapTarget, trap); err != nil {
+				log.Printf("%s %s: failed to send trap: %v", e.kind, e.name, err)
+			}
+		} else {
+			nextSyslog = nextSyslog.Add(syslogSchedule.Next(due))
+			seq++
+			msg := state.step().Syslog(e.name, due, e.dialect, seq)
+			if _, err = conn.Write([]byte(msg + "\n")); err != nil {
+				log.Printf("%s %s: failed to send syslog: %v", e.kind, e.name, err)
+			}
+		}
+
+		if err == nil {
+			failures = 0
+		} else if failures++; failures >= maxSendFailures {
+			return fmt.Errorf("%d consecutive send failures: %w", failures, err)
+		}
+	}
+}
+
+// firstTick returns when a schedule with the given interval first fires, or
+// the zero time if it is disabled.
+func firstTick(now time.Time, interval time.Duration, schedule *traffic.Schedule) time.Time {
+	if interval <= 0 {
+		return time.Time{}
+	}
+	return now.Add(schedule.Next(now))
+}
@@ -0,0 +1,391 @@
+package simulator
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Topology is the device dependency graph a scenario uses to derive
+// cascading failures. Links point downstream: From is the device closer to
+// the core, To the one that depends on it. Devices with no upstream link are
+// roots; a device that loses every path from a root becomes unreachable.
+//
+//	devices:
+//	  - {name: core-1, type: router, address: 10.255.0.1, as: 65001}
+//	  - {name: dist-1, type: switch}
+//	  - {name: app-1, type: server}
+//	links:
+//	  - {from: core-1, from_interface: Gi0/1, to: dist-1, to_interface: Gi1/0/48}
+//	  - {from: dist-1, from_interface: Gi1/0/1, to: app-1, to_interface: eth0}
+type Topology struct {
+	Devices []TopologyDevice `yaml:"devices"`
+	Links   []TopologyLink   `yaml:"links"`
+}
+
+// TopologyDevice is a node in the topology.
+type TopologyDevice struct {
+	Name    string `yaml:"name"`
+	Type    string `yaml:"type"`    // One of the simulator device types
+	Address string `yaml:"address"` // Address BGP peers report; defaults to Name
+	AS      int    `yaml:"as"`      // BGP AS number; defaults to 65000
+}
+
+// TopologyLink connects an interface on an upstream device to an interface
+// on a downstream device.
+type TopologyLink struct {
+	From          string `yaml:"from"`
+	FromInterface string `yaml:"from_interface"`
+	To            string `yaml:"to"`
+	ToInterface   string `yaml:"to_interface"`
+	BGP           bool   `yaml:"bgp"` // The endpoints run a BGP session over the link
+}
+
+// Cascade delays, measured from the triggering step. Each derived event is
+// delayed by up to another 50% at random.
+const (
+	linkDetectDelay   = 1 * time.Second  // Far end notices loss of carrier
+	bgpDownDelay      = 2 * time.Second  // Session torn down after the link drops
+	bgpUpDelay        = 10 * time.Second // OPEN/KEEPALIVE exchange once the link is back
+	stpConvergeDelay  = 3 * time.Second  // Rapid spanning tree reconverges
+	reachabilityDelay = 5 * time.Second  // Per hop: IP SLA probes time out
+)
+
+// LoadTopology reads and validates a topology file.
+func LoadTopology(path string) (*Topology, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topology %s: %w", path, err)
+	}
+	var t Topology
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid topology %s: %w", path, err)
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// validate checks names, types and link endpoints. All errors are reported
+// together.
+func (t *Topology) validate() error {
+	var errs []error
+	names := make(map[string]bool, len(t.Devices))
+	for i, d := range t.Devices {
+		switch {
+		case d.Name == "":
+			errs = append(errs, fmt.Errorf("devices[%d]: name is required", i))
+		case names[d.Name]:
+			errs = append(errs, fmt.Errorf("devices[%d]: duplicate device %s", i, d.Name))
+		}
+		if _, ok := deviceTypes[strings.ToLower(d.Type)]; !ok {
+			errs = append(errs, fmt.Errorf("devices[%d]: unknown type %q (supported: %s)",
+				i, d.Type, strings.Join(SupportedTypes(), ", ")))
+		}
+		names[d.Name] = true
+	}
+
+	ifaces := make(map[string]bool)
+	for i, l := range t.Links {
+		for _, end := range [][2]string{{l.From, l.FromInterface}, {l.To, l.ToInterface}} {
+			switch {
+			case !names[end[0]]:
+				errs = append(errs, fmt.Errorf("links[%d]: unknown device %q", i, end[0]))
+			case end[1] == "":
+				errs = append(errs, fmt.Errorf("links[%d]: interface on %s is required", i, end[0]))
+			case ifaces[end[0]+" "+end[1]]:
+				errs = append(errs, fmt.Errorf("links[%d]: %s %s is already linked", i, end[0], end[1]))
+			}
+			ifaces[end[0]+" "+end[1]] = true
+		}
+		if l.From == l.To {
+			errs = append(errs, fmt.Errorf("links[%d]: %s is linked to itself", i, l.From))
+		}
+	}
+
+	if len(errs) == 0 && len(t.Devices) > 0 && len(t.roots()) == 0 {
+		errs = append(errs, errors.New("no root device: every device has an upstream link"))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid topology: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// roots returns the devices with no upstream link.
+func (t *Topology) roots() []string {
+	downstream := make(map[string]bool)
+	for _, l := range t.Links {
+		downstream[l.To] = true
+	}
+	var roots []string
+	for _, d := range t.Devices {
+		if !downstream[d.Name] {
+			roots = append(roots, d.Name)
+		}
+	}
+	return roots
+}
+
+// ---------------- Cascades ----------------
+
+// topoState tracks which devices and links are down while a scenario plan is
+// walked in time order.
+type topoState struct {
+	t        *Topology
+	devices  map[string]*TopologyDevice
+	down     map[string]bool // Devices
+	linkDown map[int]bool    // Links, by index; a link is also unusable when either end is down
+	rng      *rand.Rand
+}
+
+func newTopoState(t *Topology, rng *rand.Rand) *topoState {
+	ts := &topoState{
+		t:        t,
+		devices:  make(map[string]*TopologyDevice, len(t.Devices)),
+		down:     make(map[string]bool),
+		linkDown: make(map[int]bool),
+		rng:      rng,
+	}
+	for i := range t.Devices {
+		ts.devices[t.Devices[i].Name] = &t.Devices[i]
+	}
+	return ts
+}
+
+// cascade applies a step to the topology and returns the events it causes
+// on other devices: the far end of a failed link, BGP sessions and spanning
+// tree over it, and devices downstream that lose (or regain) reachability.
+// Steps that do not change the topology cause nothing.
+func (ts *topoState) cascade(p planned) ([]planned, error) {
+	var out []planned
+	emit := func(delay time.Duration, device, event string, c change) {
+		delay += time.Duration(ts.rng.Int63n(int64(delay)/2 + 1))
+		out = append(out, planned{
+			offset:    p.offset + delay,
+			path:      p.path,
+			iteration: p.iteration,
+			step:      &Step{Device: device, Event: event},
+			change:    c,
+			rootCause: p.step.Device,
+		})
+	}
+
+	dev := p.step.Device
+	before, wasUsable := ts.reachable(), ts.usableLinks()
+	var start string // Where the change happened, for counting hops
+
+	switch p.step.Event {
+	case "device_down", "device_up":
+		if _, ok := ts.devices[dev]; !ok {
+			return nil, fmt.Errorf("device %s is not in the topology", dev)
+		}
+		up := p.step.Event == "device_up"
+		if ts.down[dev] != up {
+			return nil, nil
+		}
+		start = dev
+
+		if !up {
+			// Neighbours see the links drop; dev itself is silent.
+			for i, l := range ts.t.Links {
+				if peer, ok := l.peer(dev); ok && ts.usable(i) {
+					ts.linkEffects(emit, i, false, []string{peer}, "")
+				}
+			}
+			ts.down[dev] = true
+		} else {
+			ts.down[dev] = false
+			for i, l := range ts.t.Links {
+				if peer, ok := l.peer(dev); ok && ts.usable(i) {
+					ts.linkEffects(emit, i, true, []string{dev, peer}, "")
+				}
+			}
+		}
+
+	case "interface_down", "interface_up":
+		i, ok := ts.linkAt(dev, p.step.Params["interface"])
+		if !ok {
+			return nil, nil
+		}
+		up := p.step.Event == "interface_up"
+		if ts.linkDown[i] != up {
+			return nil, nil
+		}
+		start = ts.t.Links[i].From
+
+		ts.linkDown[i] = !up
+		if wasUsable[i] || ts.usable(i) {
+			peer, _ := ts.t.Links[i].peer(dev)
+			ts.linkEffects(emit, i, up, []string{dev, peer}, dev)
+		}
+
+	default:
+		return nil, nil
+	}
+
+	after := ts.reachable()
+	hops := ts.hops(start)
+	for _, d := range ts.t.Devices {
+		if ts.down[d.Name] || before[d.Name] == after[d.Name] {
+			continue
+		}
+		if d.Name == dev && p.step.Event == "device_up" {
+			continue // Its cold start is the step itself
+		}
+		// Probes target the upstream neighbour that was (or is now) in use.
+		event, links := "reachability_lost", wasUsable
+		if after[d.Name] {
+			event, links = "reachability_restored", ts.usableLinks()
+		}
+		emit(reachabilityDelay*time.Duration(max(hops[d.Name], 1)), d.Name, event,
+			reachabilityChange(ts.upstream(d.Name, links), after[d.Name]))
+	}
+	return out, nil
+}
+
+// linkEffects emits what each reporter sees when link i changes state: its
+// interface event, the BGP session over the link and, on switches, spanning
+// tree reconverging. The interface event of skipIface is the triggering
+// step itself and is not repeated.
+func (ts *topoState) linkEffects(emit func(time.Duration, string, string, change), i int, up bool, reporters []string, skipIface string) {
+	l := ts.t.Links[i]
+	for _, r := range reporters {
+		iface := l.FromInterface
+		if r == l.To {
+			iface = l.ToInterface
+		}
+		peer, _ := l.peer(r)
+
+		if r != skipIface {
+			emit(linkDetectDelay, r, "interface_"+operStatus(up), linkChange(iface, ts.ifIndex(r, iface), up))
+		}
+		if l.BGP {
+			delay := bgpDownDelay
+			if up {
+				delay = bgpUpDelay
+			}
+			p := ts.devices[peer]
+			emit(delay, r, "bgp_"+operStatus(up), bgpChange(cmp.Or(p.Address, p.Name), cmp.Or(p.AS, 65000), up))
+		}
+		if strings.EqualFold(ts.devices[r].Type, "switch") {
+			emit(stpConvergeDelay, r, "stp_topology_change", stpChange("1"))
+		}
+	}
+}
+
+// usable reports whether traffic can cross link i.
+func (ts *topoState) usable(i int) bool {
+	l := ts.t.Links[i]
+	return !ts.linkDown[i] && !ts.down[l.From] && !ts.down[l.To]
+}
+
+// usableLinks returns the usable state of every link.
+func (ts *topoState) usableLinks() map[int]bool {
+	usable := make(map[int]bool, len(ts.t.Links))
+	for i := range ts.t.Links {
+		usable[i] = ts.usable(i)
+	}
+	return usable
+}
+
+// reachable returns the devices that have a working path from an up root.
+func (ts *topoState) reachable() map[string]bool {
+	seen := make(map[string]bool)
+	var queue []string
+	for _, r := range ts.t.roots() {
+		if !ts.down[r] {
+			seen[r] = true
+			queue = append(queue, r)
+		}
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for i, l := range ts.t.Links {
+			if l.From == cur && !seen[l.To] && ts.usable(i) {
+				seen[l.To] = true
+				queue = append(queue, l.To)
+			}
+		}
+	}
+	return seen
+}
+
+// hops returns the downstream distance of every device from start, ignoring
+// link state.
+func (ts *topoState) hops(start string) map[string]int {
+	dist := map[string]int{start: 0}
+	queue := []string{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, l := range ts.t.Links {
+			if _, ok := dist[l.To]; l.From == cur && !ok {
+				dist[l.To] = dist[cur] + 1
+				queue = append(queue, l.To)
+			}
+		}
+	}
+	return dist
+}
+
+// upstream returns the first upstream neighbour of name over a link marked
+// usable, falling back to the first upstream neighbour.
+func (ts *topoState) upstream(name string, usable map[int]bool) string {
+	first := ""
+	for i, l := range ts.t.Links {
+		if l.To != name {
+			continue
+		}
+		if usable[i] {
+			return l.From
+		}
+		first = cmp.Or(first, l.From)
+	}
+	return cmp.Or(first, name)
+}
+
+// linkAt returns the index of the link attached to device's interface.
+func (ts *topoState) linkAt(device, iface string) (int, bool) {
+	for i, l := range ts.t.Links {
+		if (l.From == device && l.FromInterface == iface) || (l.To == device && l.ToInterface == iface) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ifIndex numbers a device's linked interfaces from 1 in link order.
+func (ts *topoState) ifIndex(device, iface string) int {
+	n := 0
+	for _, l := range ts.t.Links {
+		for _, end := range [][2]string{{l.From, l.FromInterface}, {l.To, l.ToInterface}} {
+			if end[0] == device {
+				n++
+				if end[1] == iface {
+					return n
+				}
+			}
+		}
+	}
+	return 1
+}
+
+// peer returns the other end of the link, if device is one end.
+func (l TopologyLink) peer(device string) (string, bool) {
+	switch device {
+	case l.From:
+		return l.To, true
+	case l.To:
+		return l.From, true
+	}
+	return "", false
+}
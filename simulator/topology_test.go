@@ -0,0 +1,177 @@
+package simulator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/pkg/snmptrap"
+)
+
+func TestLoadScenario_TopologySample(t *testing.T) {
+	s, err := LoadScenario("../config/scenarios/core-router-outage.yml")
+	if err != nil {
+		t.Fatalf("expected sample scenario to be valid, got %v", err)
+	}
+
+	emissions, summary := runScenario(t, s, &fakeScenarioIO{})
+	if summary.Steps != 4 || summary.Failed != 0 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	// Derived traps by step, as "device event"
+	derived := make(map[string][]string)
+	for _, e := range emissions {
+		if e.Kind != "trap" || e.RootCause == "" {
+			continue
+		}
+		derived[e.Step] = append(derived[e.Step], e.Device+" "+e.Event)
+
+		if e.Step == "core-1-crash" && e.Device == "core-1" {
+			t.Errorf("failed device should be silent, got %s", e.Event)
+		}
+	}
+
+	crash := strings.Join(derived["core-1-crash"], ",")
+	for _, want := range []string{
+		"fw-1 interface_down", "fw-1 bgp_down", "dist-sw-1 stp_topology_change",
+		"app-1 reachability_lost", "ap-1 reachability_lost",
+	} {
+		if !strings.Contains(crash, want) {
+			t.Errorf("expected %q after core-1 crash, got %s", want, crash)
+		}
+	}
+	// dist-sw-2 is dual-homed and stays reachable through core-2
+	if strings.Contains(crash, "db-1") || strings.Contains(crash, "dist-sw-2 reachability") {
+		t.Errorf("expected dual-homed branch to stay reachable, got %s", crash)
+	}
+
+	cut := strings.Join(derived["dist-sw-2-uplink-cut"], ",")
+	for _, want := range []string{"core-2 interface_down", "dist-sw-2 reachability_lost", "db-1 reachability_lost"} {
+		if !strings.Contains(cut, want) {
+			t.Errorf("expected %q after second uplink cut, got %s", want, cut)
+		}
+	}
+	if strings.Contains(cut, "dist-sw-2 interface_down") {
+		t.Errorf("triggering interface event should not be repeated, got %s", cut)
+	}
+
+	reboot := strings.Join(derived["core-1-reboot"], ",")
+	for _, want := range []string{"core-1 interface_up", "fw-1 bgp_up", "core-1 bgp_up", "app-1 reachability_restored"} {
+		if !strings.Contains(reboot, want) {
+			t.Errorf("expected %q after core-1 reboot, got %s", want, reboot)
+		}
+	}
+}
+
+func TestScenario_CascadeDelays(t *testing.T) {
+	s, err := ParseScenario([]byte(`
+name: delays
+seed: 3
+topology:
+  devices:
+    - {name: r1, type: router}
+    - {name: r2, type: router, address: 10.0.0.2, as: 65002}
+    - {name: sw, type: switch}
+    - {name: srv, type: server}
+  links:
+    - {from: r1, from_interface: Gi0/0, to: r2, to_interface: Gi0/0, bgp: true}
+    - {from: r2, from_interface: Gi0/1, to: sw, to_interface: Gi1/0/1}
+    - {from: sw, from_interface: Gi1/0/2, to: srv, to_interface: eth0}
+steps:
+  - at: 10s
+    device: r1
+    event: interface_down
+    interface: Gi0/0
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	emissions, _ := runScenario(t, s, &fakeScenarioIO{})
+	base := map[string]time.Duration{
+		"r2 interface_down":     linkDetectDelay,
+		"r1 bgp_down":           bgpDownDelay,
+		"r2 bgp_down":           bgpDownDelay,
+		"r2 reachability_lost":  reachabilityDelay,
+		"sw reachability_lost":  2 * reachabilityDelay,
+		"srv reachability_lost": 3 * reachabilityDelay,
+	}
+	seen := make(map[string]bool)
+	for _, e := range emissions {
+		if e.Kind != "trap" || e.RootCause == "" {
+			continue
+		}
+		key := e.Device + " " + e.Event
+		seen[key] = true
+		d, ok := base[key]
+		if !ok {
+			t.Errorf("unexpected derived event %s", key)
+			continue
+		}
+		if e.RootCause != "r1" {
+			t.Errorf("%s: expected root cause r1, got %q", key, e.RootCause)
+		}
+		offset := time.Duration(e.OffsetMS)*time.Millisecond - 10*time.Second
+		if offset < d || offset > d+d/2 {
+			t.Errorf("%s: expected delay in [%v, %v], got %v", key, d, d+d/2, offset)
+		}
+		if key == "r1 bgp_down" && e.Variables["bgpPeerRemoteAddr"] != "10.0.0.2" {
+			t.Errorf("expected BGP peer address from topology, got %v", e.Variables)
+		}
+		if key == "srv reachability_lost" && (e.OID != snmptrap.ReachabilityLost.OID || e.Variables["rttMonCtrlAdminTag"] != "sw") {
+			t.Errorf("unexpected reachability trap: %+v", e)
+		}
+	}
+	for key := range base {
+		if !seen[key] {
+			t.Errorf("missing derived event %s", key)
+		}
+	}
+}
+
+func TestScenario_TopologyErrors(t *testing.T) {
+	tests := map[string]string{
+		"device event without topology": `
+steps:
+  - {device: r1, event: device_down}`,
+		"device not in topology": `
+topology:
+  devices: [{name: r1, type: router}]
+steps:
+  - {device: r2, event: device_down}`,
+		"unknown link device": `
+topology:
+  devices: [{name: r1, type: router}]
+  links: [{from: r1, from_interface: a, to: r9, to_interface: b}]
+steps:
+  - {device: r1, event: device_down}`,
+		"unknown type": `
+topology:
+  devices: [{name: r1, type: toaster}]
+steps:
+  - {device: r1, event: device_down}`,
+		"no root": `
+topology:
+  devices: [{name: r1, type: router}, {name: r2, type: router}]
+  links:
+    - {from: r1, from_interface: a, to: r2, to_interface: a}
+    - {from: r2, from_interface: b, to: r1, to_interface: b}
+steps:
+  - {device: r1, event: device_down}`,
+		"both topology and file": `
+topology_file: campus-topology.yml
+topology:
+  devices: [{name: r1, type: router}]
+steps:
+  - {device: r1, event: device_down}`,
+	}
+
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseScenario([]byte(doc)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Package source provides long-running inputs that feed the datasource
+// pipeline from files produced by the standalone simulators.
+//
+// MetadataSource watches the devices-metadata.json file written by
+// cmd/metadata-pub, keeps the shared inventory up to date and forwards one
+// metadata event per changed device to a Sink. On Linux the file is watched
+// with inotify; elsewhere (or if inotify is unavailable) it is polled.
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/inventory"
+	"github.com/ibm-live-project-interns/ingestor/shared/models"
+)
+
+// Sink receives events produced by a source.
+type Sink func(models.Event)
+
+// MetadataSource watches a device metadata file and turns changes into
+// metadata events.
+type MetadataSource struct {
+	inv  *inventory.Inventory
+	sink Sink
+
+	// PollInterval is used when inotify is unavailable.
+	PollInterval time.Duration
+	// Settle is how long to wait after a change notification before reading,
+	// so that bursts of writes are coalesced into a single read.
+	Settle time.Duration
+	// Retries is how many extra reads are attempted when the file cannot be
+	// parsed, which typically means the writer is still part way through.
+	Retries int
+
+	last []byte
+}
+
+// NewMetadataSource creates a source that refreshes inv from its backing file
+// and sends a metadata event to sink for every device change.
+func NewMetadataSource(inv *inventory.Inventory, sink Sink) *MetadataSource {
+	s := &MetadataSource{
+		inv:          inv,
+		sink:         sink,
+		PollInterval: 2 * time.Second,
+		Settle:       200 * time.Millisecond,
+		Retries:      3,
+	}
+
+	inv.OnChange(s.publish)
+	return s
+}
+
+// Run watches the metadata file until the context is cancelled.
+func (s *MetadataSource) Run(ctx context.Context) error {
+	path := s.inv.Path()
+	notify := make(chan struct{}, 1)
+
+	if err := watchFile(ctx, path, notify); err != nil {
+		log.Printf("metadata source: file notifications unavailable (%v), polling every %s", err, s.PollInterval)
+		go pollFile(ctx, path, s.PollInterval, notify)
+	}
+
+	if err := s.load(ctx); err != nil {
+		log.Printf("metadata source: initial load of %s failed: %v", path, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-notify:
+			if !sleep(ctx, s.Settle) {
+				return nil
+			}
+			drain(notify)
+
+			if err := s.load(ctx); err != nil {
+				log.Printf("metadata source: keeping previous inventory: %v", err)
+			}
+		}
+	}
+}
+
+// load reads and validates the metadata file and, if its contents changed,
+// replaces the inventory. Parse failures are retried to ride out partial
+// writes; the previous inventory is kept if every attempt fails.
+func (s *MetadataSource) load(ctx context.Context) error {
+	path := s.inv.Path()
+
+	var lastErr error
+	for attempt := 0; attempt <= s.Retries; attempt++ {
+		if attempt > 0 && !sleep(ctx, s.Settle) {
+			return ctx.Err()
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to read metadata file: %w", err)
+			continue
+		}
+
+		if bytes.Equal(data, s.last) {
+			return nil
+		}
+
+		devices, err := inventory.Parse(data)
+		if err != nil {
+			lastErr = fmt.Errorf("invalid metadata file %s: %w", path, err)
+			continue
+		}
+
+		s.last = data
+		s.inv.Replace(devices)
+		return nil
+	}
+
+	return lastErr
+}
+
+// publish maps inventory changes to metadata events and hands them to the sink.
+func (s *MetadataSource) publish(changes []inventory.Change) {
+	for _, c := range changes {
+		event, err := c.Event()
+		if err != nil {
+			log.Printf("metadata source: failed to map change for %s: %v", c.Hostname, err)
+			continue
+		}
+		s.sink(event)
+	}
+}
+
+// pollFile signals notify whenever the file's size or modification time
+// changes. It is the fallback when inotify cannot be used.
+func pollFile(ctx context.Context, path string, interval time.Duration, notify chan<- struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastMod time.Time
+	var lastSize int64 = -1
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if info.ModTime().Equal(lastMod) && info.Size() == lastSize {
+				continue
+			}
+			lastMod, lastSize = info.ModTime(), info.Size()
+			trigger(notify)
+		}
+	}
+}
+
+// trigger performs a non-blocking send so that pending notifications coalesce.
+func trigger(notify chan<- struct{}) {
+	select {
+	case notify <- struct{}{}:
+	default:
+	}
+}
+
+func drain(notify <-chan struct{}) {
+	for {
+		select {
+		case <-notify:
+		default:
+			return
+		}
+	}
+}
+
+// sleep waits for d or until the context is cancelled, reporting whether the
+// full duration elapsed.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,147 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ibm-live-project-interns/datasource/inventory"
+	"github.com/ibm-live-project-interns/datasource/pkg/metadatasim"
+	"github.com/ibm-live-project-interns/ingestor/shared/constants"
+	"github.com/ibm-live-project-interns/ingestor/shared/models"
+)
+
+func writeDevices(t *testing.T, path string, devices []metadatasim.Device) {
+	t.Helper()
+	data, err := json.MarshalIndent(devices, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func startSource(t *testing.T, path string, initial int) (<-chan models.Event, *inventory.Inventory) {
+	t.Helper()
+
+	events := make(chan models.Event, 10)
+	inv := inventory.New(path)
+	src := NewMetadataSource(inv, func(e models.Event) { events <- e })
+	src.PollInterval = 20 * time.Millisecond
+	src.Settle = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go src.Run(ctx)
+
+	// The inventory starts empty, so the initial load announces every device
+	// as added. Consuming those also guarantees the watch is in place.
+	for i := 0; i < initial; i++ {
+		expectEvent(t, events)
+	}
+
+	return events, inv
+}
+
+func expectEvent(t *testing.T, events <-chan models.Event) models.Event {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for metadata event")
+		return models.Event{}
+	}
+}
+
+func TestMetadataSource_EmitsChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices-metadata.json")
+	devices := []metadatasim.Device{
+		{ID: "dev-001", Hostname: "device-001", IP: "10.0.0.1", OS: "IOS-XE 17.3", Location: "DC1-Rack1"},
+	}
+	writeDevices(t, path, devices)
+
+	events, _ := startSource(t, path, 1)
+
+	devices[0].OS = "IOS-XE 17.3 (patched)"
+	writeDevices(t, path, devices)
+
+	e := expectEvent(t, events)
+	if e.EventType != constants.EventTypeMetadata || e.SourceHost != "device-001" {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+}
+
+func TestMetadataSource_ToleratesPartialWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices-metadata.json")
+	devices := []metadatasim.Device{
+		{ID: "dev-001", Hostname: "device-001", IP: "10.0.0.1", Location: "DC1-Rack1"},
+	}
+	writeDevices(t, path, devices)
+
+	events, inv := startSource(t, path, 1)
+
+	if err := os.WriteFile(path, []byte(`[{"id": "dev-001", "hostn`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	devices[0].Location = "DC1-Rack1-Alt"
+	writeDevices(t, path, devices)
+
+	e := expectEvent(t, events)
+	if e.SourceHost != "device-001" {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	if d, _ := inv.Lookup("device-001"); d.Location != "DC1-Rack1-Alt" {
+		t.Errorf("expected inventory to hold the completed write, got %+v", d)
+	}
+}
+
+func TestMetadataSource_RejectsInvalidSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices-metadata.json")
+	writeDevices(t, path, []metadatasim.Device{
+		{ID: "dev-001", Hostname: "device-001", IP: "10.0.0.1"},
+	})
+
+	_, inv := startSource(t, path, 1)
+
+	writeDevices(t, path, []metadatasim.Device{
+		{ID: "dev-001", Hostname: "device-001", IP: "not-an-ip"},
+	})
+	time.Sleep(200 * time.Millisecond)
+
+	if d, _ := inv.Lookup("device-001"); d.IP != "10.0.0.1" {
+		t.Errorf("expected previous inventory to be kept, got %+v", d)
+	}
+}
+
+func TestPollFile_SignalsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices-metadata.json")
+	writeDevices(t, path, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notify := make(chan struct{}, 1)
+	go pollFile(ctx, path, 10*time.Millisecond, notify)
+
+	select {
+	case <-notify:
+	case <-time.After(time.Second):
+		t.Fatalf("expected initial poll notification")
+	}
+
+	writeDevices(t, path, []metadatasim.Device{{ID: "dev-001", Hostname: "device-001", IP: "10.0.0.1"}})
+
+	select {
+	case <-notify:
+	case <-time.After(time.Second):
+		t.Fatalf("expected notification after change")
+	}
+}
@@ -0,0 +1,84 @@
+//go:build linux
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+// watchFile uses inotify to signal notify whenever the file at path is
+// written, created, replaced or removed. The parent directory is watched
+// rather than the file itself so that atomic rename-over writes and the file
+// being recreated are both observed.
+func watchFile(ctx context.Context, path string, notify chan<- struct{}) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return fmt.Errorf("inotify init: %w", err)
+	}
+
+	dir, name := filepath.Dir(path), filepath.Base(path)
+	mask := uint32(syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_CREATE |
+		syscall.IN_MOVED_TO | syscall.IN_DELETE)
+
+	if _, err := syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+		syscall.Close(fd)
+		return fmt.Errorf("inotify watch %s: %w", dir, err)
+	}
+
+	// A non-blocking fd wrapped in os.File is registered with the runtime
+	// poller, so closing it unblocks the pending Read on cancellation.
+	f := os.NewFile(uintptr(fd), "inotify")
+
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			if eventsMatch(buf[:n], name) {
+				trigger(notify)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// eventsMatch reports whether any inotify event in buf refers to name.
+func eventsMatch(buf []byte, name string) bool {
+	for off := 0; off+syscall.SizeofInotifyEvent <= len(buf); {
+		ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+		start := off + syscall.SizeofInotifyEvent
+		end := start + int(ev.Len)
+		if end > len(buf) {
+			return false
+		}
+
+		evName := string(bytesBeforeNUL(buf[start:end]))
+		if evName == name {
+			return true
+		}
+		off = end
+	}
+	return false
+}
+
+func bytesBeforeNUL(b []byte) []byte {
+	for i, c := range b {
+		if c == 0 {
+			return b[:i]
+		}
+	}
+	return b
+}
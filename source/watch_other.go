@@ -0,0 +1,13 @@
+//go:build !linux
+
+package source
+
+import (
+	"context"
+	"errors"
+)
+
+// watchFile is not implemented outside Linux; callers fall back to polling.
+func watchFile(ctx context.Context, path string, notify chan<- struct{}) error {
+	return errors.New("inotify not supported on this platform")
+}
@@ -12,6 +12,8 @@ package sysloglistener
 import (
 	"fmt"
 	"net"
+
+	"github.com/ibm-live-project-interns/datasource/mapper"
 )
 
 // StartUDPListener starts a UDP server on the given address and prints
@@ -44,3 +46,39 @@ func StartUDPListener(address string) error {
 		fmt.Printf("From %s: %s\n", remoteAddr, string(buf[:n]))
 	}
 }
+
+// StartMappedListener starts a UDP server on the given address and maps each
+// JSON syslog payload with m.MapSyslogFrom, using the packet's source address
+// as the peer. handle receives every mapped event together with its source
+// check; payloads that cannot be mapped are printed as they are. It blocks
+// forever once started, like StartUDPListener.
+func StartMappedListener(address string, m *mapper.Mapper, handle func(mapper.CheckedEvent)) error {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 2048)
+	fmt.Printf("Listening on UDP %s ...\n", address)
+
+	for {
+		n, remoteAddr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			fmt.Println("read error:", err)
+			continue
+		}
+
+		event, check, err := m.MapSyslogFrom(buf[:n], remoteAddr)
+		if err != nil {
+			fmt.Printf("From %s (not mappable): %s\n", remoteAddr, string(buf[:n]))
+			continue
+		}
+		handle(mapper.CheckedEvent{Event: event, Source: check})
+	}
+}